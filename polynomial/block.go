@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/hneemann/parser2/funcGen"
 	"github.com/hneemann/parser2/value"
+	"sort"
 	"strings"
 	"unicode"
 )
@@ -14,17 +15,40 @@ type BlockFactory struct {
 	name    string
 }
 
-type BlockFactoryFunc func([]*float64) (BlockNextFunc, error)
+type BlockFactoryFunc func([]*float64) (*Block, error)
+
+// Block is a simulation block created by a BlockFactory.
+// A block can have a continuous state which is integrated by the solver.
+type Block struct {
+	// states is the number of continuous states of the block
+	states int
+	// output calculates the output from the state x and the input signals
+	output func(t float64, x Vector) (float64, error)
+	// derivative calculates the derivative of the state, only required if states>0
+	derivative func(t float64, x, dx Vector) error
+	// feedThrough is true if the output depends directly on the input signals
+	feedThrough bool
+	// accept is called after every accepted step, can be nil
+	accept func(t float64) error
+}
 
-type BlockNextFunc func(t, dt float64) (float64, error)
+// algebraic creates a block without a state whose output directly depends on its inputs
+func algebraic(f func(t float64) (float64, error)) *Block {
+	return &Block{
+		output: func(t float64, _ Vector) (float64, error) {
+			return f(t)
+		},
+		feedThrough: true,
+	}
+}
 
 func Gain(g float64) BlockFactory {
 	return BlockFactory{
-		creator: func(args []*float64) (BlockNextFunc, error) {
+		creator: func(args []*float64) (*Block, error) {
 			a := args[0]
-			return func(_, _ float64) (float64, error) {
+			return algebraic(func(_ float64) (float64, error) {
 				return *a * g, nil
-			}, nil
+			}), nil
 		},
 		inputs: 1,
 		name:   fmt.Sprintf("Gain %f", g),
@@ -33,10 +57,10 @@ func Gain(g float64) BlockFactory {
 
 func Const(c float64) BlockFactory {
 	return BlockFactory{
-		creator: func(args []*float64) (BlockNextFunc, error) {
-			return func(_, _ float64) (float64, error) {
+		creator: func(args []*float64) (*Block, error) {
+			return algebraic(func(_ float64) (float64, error) {
 				return c, nil
-			}, nil
+			}), nil
 		},
 		inputs: 0,
 		name:   fmt.Sprintf("Const %f", c),
@@ -45,10 +69,10 @@ func Const(c float64) BlockFactory {
 
 func Closure(c value.Closure) BlockFactory {
 	return BlockFactory{
-		creator: func(args []*float64) (BlockNextFunc, error) {
+		creator: func(args []*float64) (*Block, error) {
 			st := funcGen.NewEmptyStack[value.Value]()
 			vals := make([]value.Value, len(args))
-			return func(_, _ float64) (float64, error) {
+			return algebraic(func(_ float64) (float64, error) {
 				for i, a := range args {
 					vals[i] = value.Float(*a)
 				}
@@ -60,7 +84,7 @@ func Closure(c value.Closure) BlockFactory {
 					return f, nil
 				}
 				return 0, fmt.Errorf("invalid return value %v", res)
-			}, nil
+			}), nil
 		},
 		inputs: c.Args,
 		name:   "function of input signals",
@@ -69,9 +93,9 @@ func Closure(c value.Closure) BlockFactory {
 
 func ClosureTime(c value.Closure) BlockFactory {
 	return BlockFactory{
-		creator: func(args []*float64) (BlockNextFunc, error) {
+		creator: func(args []*float64) (*Block, error) {
 			st := funcGen.NewEmptyStack[value.Value]()
-			return func(t, _ float64) (float64, error) {
+			return algebraic(func(t float64) (float64, error) {
 				res, err := c.Eval(st, value.Float(t))
 				if err != nil {
 					return 0, err
@@ -80,7 +104,7 @@ func ClosureTime(c value.Closure) BlockFactory {
 					return f, nil
 				}
 				return 0, fmt.Errorf("invalid return value %v", res)
-			}, nil
+			}), nil
 		},
 		inputs: 0,
 		name:   "function of time",
@@ -88,12 +112,12 @@ func ClosureTime(c value.Closure) BlockFactory {
 }
 func Mul() BlockFactory {
 	return BlockFactory{
-		creator: func(args []*float64) (BlockNextFunc, error) {
+		creator: func(args []*float64) (*Block, error) {
 			a := args[0]
 			b := args[1]
-			return func(_, _ float64) (float64, error) {
+			return algebraic(func(_ float64) (float64, error) {
 				return *a * *b, nil
-			}, nil
+			}), nil
 		},
 		inputs: 2,
 		name:   "Mul",
@@ -102,12 +126,12 @@ func Mul() BlockFactory {
 
 func Add() BlockFactory {
 	return BlockFactory{
-		creator: func(args []*float64) (BlockNextFunc, error) {
+		creator: func(args []*float64) (*Block, error) {
 			a := args[0]
 			b := args[1]
-			return func(_, _ float64) (float64, error) {
+			return algebraic(func(_ float64) (float64, error) {
 				return *a + *b, nil
-			}, nil
+			}), nil
 		},
 		inputs: 2,
 		name:   "Add",
@@ -116,14 +140,14 @@ func Add() BlockFactory {
 
 func AddMultiple(n int) BlockFactory {
 	return BlockFactory{
-		creator: func(args []*float64) (BlockNextFunc, error) {
-			return func(_, _ float64) (float64, error) {
+		creator: func(args []*float64) (*Block, error) {
+			return algebraic(func(_ float64) (float64, error) {
 				var sum float64
 				for i := 0; i < n; i++ {
 					sum += *args[i]
 				}
 				return sum, nil
-			}, nil
+			}), nil
 		},
 		inputs: n,
 		name:   "Add",
@@ -132,16 +156,16 @@ func AddMultiple(n int) BlockFactory {
 
 func Limit(min, max float64) BlockFactory {
 	return BlockFactory{
-		creator: func(args []*float64) (BlockNextFunc, error) {
+		creator: func(args []*float64) (*Block, error) {
 			in := args[0]
-			return func(_, _ float64) (float64, error) {
+			return algebraic(func(_ float64) (float64, error) {
 				if *in < min {
 					return min, nil
 				} else if *in > max {
 					return max, nil
 				}
 				return *in, nil
-			}, nil
+			}), nil
 		},
 		inputs: 1,
 		name:   fmt.Sprintf("Limit %f-%f", min, max)}
@@ -149,12 +173,12 @@ func Limit(min, max float64) BlockFactory {
 
 func Sub() BlockFactory {
 	return BlockFactory{
-		creator: func(args []*float64) (BlockNextFunc, error) {
+		creator: func(args []*float64) (*Block, error) {
 			a := args[0]
 			b := args[1]
-			return func(_, _ float64) (float64, error) {
+			return algebraic(func(_ float64) (float64, error) {
 				return *a - *b, nil
-			}, nil
+			}), nil
 		},
 		inputs: 2,
 		name:   "Sub",
@@ -163,13 +187,17 @@ func Sub() BlockFactory {
 
 func Integrate() BlockFactory {
 	return BlockFactory{
-		creator: func(args []*float64) (BlockNextFunc, error) {
+		creator: func(args []*float64) (*Block, error) {
 			a := args[0]
-			var sum float64
-			return func(_, dt float64) (float64, error) {
-				tf := sum
-				sum += *a * dt
-				return tf, nil
+			return &Block{
+				states: 1,
+				output: func(_ float64, x Vector) (float64, error) {
+					return x[0], nil
+				},
+				derivative: func(_ float64, _, dx Vector) error {
+					dx[0] = *a
+					return nil
+				},
 			}, nil
 		},
 		inputs: 1,
@@ -177,21 +205,50 @@ func Integrate() BlockFactory {
 	}
 }
 
+// differentiator calculates the derivative of a signal by the
+// difference quotient to the last accepted step
+type differentiator struct {
+	in    *float64
+	last  float64
+	lastT float64
+	dif   float64
+}
+
+func (d *differentiator) get(t float64) float64 {
+	if t <= d.lastT {
+		return d.dif
+	}
+	return (*d.in - d.last) / (t - d.lastT)
+}
+
+func (d *differentiator) accept(t float64) error {
+	if t > d.lastT {
+		d.dif = (*d.in - d.last) / (t - d.lastT)
+	}
+	d.last = *d.in
+	d.lastT = t
+	return nil
+}
+
 func BlockPID(kp, Ti, Td float64) (BlockFactory, error) {
 	if Ti == 0 {
 		return BlockFactory{}, fmt.Errorf("Ti must not be zero")
 	}
 	return BlockFactory{
-		creator: func(args []*float64) (BlockNextFunc, error) {
+		creator: func(args []*float64) (*Block, error) {
 			a := args[0]
-			var sum float64
-			var last float64
-			return func(_, dt float64) (float64, error) {
-				dif := (*a - last) / dt
-				u := kp * (*a + sum/Ti + dif*Td)
-				last = *a
-				sum += *a * dt
-				return u, nil
+			dif := &differentiator{in: a}
+			return &Block{
+				states: 1,
+				output: func(t float64, x Vector) (float64, error) {
+					return kp * (*a + x[0]/Ti + dif.get(t)*Td), nil
+				},
+				derivative: func(_ float64, _, dx Vector) error {
+					dx[0] = *a
+					return nil
+				},
+				feedThrough: true,
+				accept:      dif.accept,
 			}, nil
 		},
 		inputs: 1,
@@ -201,13 +258,14 @@ func BlockPID(kp, Ti, Td float64) (BlockFactory, error) {
 
 func Differentiate() BlockFactory {
 	return BlockFactory{
-		creator: func(args []*float64) (BlockNextFunc, error) {
-			a := args[0]
-			var last float64
-			return func(_, dt float64) (float64, error) {
-				dif := (*a - last) / dt
-				last = *a
-				return dif, nil
+		creator: func(args []*float64) (*Block, error) {
+			dif := &differentiator{in: args[0]}
+			return &Block{
+				output: func(t float64, _ Vector) (float64, error) {
+					return dif.get(t), nil
+				},
+				feedThrough: true,
+				accept:      dif.accept,
 			}, nil
 		},
 		inputs: 1,
@@ -217,27 +275,40 @@ func Differentiate() BlockFactory {
 
 func Delay(delayTime float64) BlockFactory {
 	return BlockFactory{
-		creator: func(args []*float64) (BlockNextFunc, error) {
+		creator: func(args []*float64) (*Block, error) {
 			if delayTime <= 0 {
 				return nil, fmt.Errorf("delay time must be greater than zero")
 			}
 			a := args[0]
-			var buffer []float64
-			i := 0
-			n := 0
-			return func(_, dt float64) (float64, error) {
-				if n == 0 {
-					n = int(delayTime / dt)
-					buffer = make([]float64, n)
-				}
-
-				d := buffer[i]
-				buffer[i] = *a
-				i++
-				if i >= n {
-					i = 0
-				}
-				return d, nil
+			// history of the accepted input values
+			var times, values []float64
+			return &Block{
+				output: func(t float64, _ Vector) (float64, error) {
+					td := t - delayTime
+					if len(times) == 0 || td < times[0] {
+						return 0, nil
+					}
+					i := sort.SearchFloat64s(times, td)
+					if i >= len(times) {
+						return values[len(values)-1], nil
+					}
+					if times[i] == td || i == 0 {
+						return values[i], nil
+					}
+					f := (td - times[i-1]) / (times[i] - times[i-1])
+					return values[i-1] + f*(values[i]-values[i-1]), nil
+				},
+				accept: func(t float64) error {
+					times = append(times, t)
+					values = append(values, *a)
+					// remove the values which are not required anymore
+					i := sort.SearchFloat64s(times, t-delayTime)
+					if i > 1 && i > len(times)/2 {
+						times = append(times[:0], times[i-1:]...)
+						values = append(values[:0], values[i-1:]...)
+					}
+					return nil
+				},
 			}, nil
 		},
 		inputs: 1,
@@ -247,30 +318,50 @@ func Delay(delayTime float64) BlockFactory {
 
 func BlockLinear(lin *Linear) BlockFactory {
 	return BlockFactory{
-		creator: func(args []*float64) (BlockNextFunc, error) {
+		creator: func(args []*float64) (*Block, error) {
 			in := args[0]
-			a, c, d, err := lin.GetStateSpaceRepresentation()
+			rational := *lin
+			rational.Delay = 0
+			ss, err := rational.StateSpace()
 			if err != nil {
 				return nil, err
 			}
-			n := len(lin.Denominator) - 1
-			x := make(Vector, n)
-			xDot := make(Vector, n)
-			if n > 0 {
-				return func(_, dt float64) (float64, error) {
-					y := c.Mul(x) + d**in
-
-					a.Mul(xDot, x)
-					xDot[n-1] += *in
-					x.Add(dt, xDot)
-
-					return y, nil
-				}, nil
-			} else {
-				return func(_, dt float64) (float64, error) {
-					return d * *in, nil
-				}, nil
+			u := func(float64) (float64, error) { return *in, nil }
+			var accept func(t float64) error
+			if lin.Delay != 0 {
+				// the dead time is realized by a delay block at the input
+				delay, err := Delay(lin.Delay).creator(args)
+				if err != nil {
+					return nil, err
+				}
+				u = func(t float64) (float64, error) { return delay.output(t, nil) }
+				accept = delay.accept
 			}
+			c := ss.C[0]
+			d := ss.D[0][0]
+			return &Block{
+				states: ss.Order(),
+				output: func(t float64, x Vector) (float64, error) {
+					v, err := u(t)
+					if err != nil {
+						return 0, err
+					}
+					return c.Mul(x) + d*v, nil
+				},
+				derivative: func(t float64, x, dx Vector) error {
+					v, err := u(t)
+					if err != nil {
+						return err
+					}
+					ss.A.Mul(dx, x)
+					for i := range dx {
+						dx[i] += ss.B[i][0] * v
+					}
+					return nil
+				},
+				feedThrough: d != 0 && lin.Delay == 0,
+				accept:      accept,
+			}, nil
 		},
 		inputs: 1,
 		name:   fmt.Sprintf("Linear %v", lin),
@@ -288,9 +379,14 @@ func (b SystemBlock) String() string {
 }
 
 type System struct {
-	blocks  []SystemBlock
-	outputs []string
-	next    []BlockNextFunc
+	blocks    []SystemBlock
+	outputs   []string
+	instances []*Block
+	// order is the order in which the block outputs are evaluated
+	order []int
+	// offsets are the positions of the block states in the system state
+	offsets []int
+	states  int
 	values  []float64
 }
 
@@ -333,34 +429,175 @@ func (s *System) Initialize() error {
 		}
 	}
 
-	var next []BlockNextFunc
+	var instances []*Block
+	var offsets []int
+	states := 0
 	var values = make([]float64, len(outputs))
 	for _, block := range s.blocks {
 		args := make([]*float64, block.factory.inputs)
 		for i, input := range block.inputs {
 			args[i] = &values[outputMap[input]]
 		}
-		nextFunc, err := block.factory.creator(args)
+		instance, err := block.factory.creator(args)
 		if err != nil {
 			return fmt.Errorf("error creating block '%v': %w", block, err)
 		}
-		next = append(next, nextFunc)
+		instances = append(instances, instance)
+		offsets = append(offsets, states)
+		states += instance.states
+	}
+
+	order, err := s.evaluationOrder(instances, outputMap)
+	if err != nil {
+		return err
 	}
 
 	s.outputs = outputs
-	s.next = next
+	s.instances = instances
+	s.order = order
+	s.offsets = offsets
+	s.states = states
 	s.values = values
 
 	return nil
 }
 
+// evaluationOrder sorts the blocks in a way that every block with a direct
+// feed through is evaluated after the blocks creating its input signals.
+// The blocks without a feed through are evaluated first.
+// A loop which consists only of blocks with a direct feed through can not be
+// evaluated and is rejected. Such a loop needs a delay block to be broken up.
+func (s *System) evaluationOrder(instances []*Block, outputMap map[string]int) ([]int, error) {
+	var order []int
+	done := make([]bool, len(instances))
+	for i, inst := range instances {
+		if !inst.feedThrough {
+			order = append(order, i)
+			done[i] = true
+		}
+	}
+	for len(order) < len(instances) {
+		found := false
+		for i, block := range s.blocks {
+			if done[i] {
+				continue
+			}
+			ready := true
+			for _, input := range block.inputs {
+				if !done[outputMap[input]] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				order = append(order, i)
+				done[i] = true
+				found = true
+			}
+		}
+		if !found {
+			var loop []string
+			for i, block := range s.blocks {
+				if !done[i] {
+					loop = append(loop, block.output)
+				}
+			}
+			return nil, fmt.Errorf("algebraic loop detected, involved signals: %v; a delay block is required to break up the loop", loop)
+		}
+	}
+	return order, nil
+}
+
+// evaluate calculates all signals at the time t for the system state x
+func (s *System) evaluate(t float64, x Vector) error {
+	for _, i := range s.order {
+		inst := s.instances[i]
+		o := s.offsets[i]
+		var err error
+		s.values[i], err = inst.output(t, x[o:o+inst.states])
+		if err != nil {
+			return fmt.Errorf("error in block '%v': %w", s.blocks[i], err)
+		}
+	}
+	return nil
+}
+
+func (s *System) derivative(t float64, x, dx Vector) error {
+	err := s.evaluate(t, x)
+	if err != nil {
+		return err
+	}
+	for i, inst := range s.instances {
+		if inst.states > 0 {
+			o := s.offsets[i]
+			err = inst.derivative(t, x[o:o+inst.states], dx[o:o+inst.states])
+			if err != nil {
+				return fmt.Errorf("error in block '%v': %w", s.blocks[i], err)
+			}
+		}
+	}
+	return nil
+}
+
+// accept evaluates all signals of an accepted step and informs the blocks about it
+func (s *System) accept(t float64, x Vector) error {
+	err := s.evaluate(t, x)
+	if err != nil {
+		return err
+	}
+	for i, inst := range s.instances {
+		if inst.accept != nil {
+			err = inst.accept(t)
+			if err != nil {
+				return fmt.Errorf("error in block '%v': %w", s.blocks[i], err)
+			}
+		}
+	}
+	return nil
+}
+
+// Run simulates the system using the euler method
 func (s *System) Run(tMax, dt float64, pointsExported int) (*dataSet, error) {
+	return s.RunSolver(Euler, tMax, dt, pointsExported)
+}
+
+// RunSolver simulates the system using the given solver. For the fixed step
+// solvers dt is the step width, for the adaptive solver it is the maximum step width.
+func (s *System) RunSolver(solver Solver, tMax, dt float64, pointsExported int) (*dataSet, error) {
 	if pointsExported < 10 {
 		pointsExported = 1000
 	}
 
+	x := make(Vector, s.states)
+	cols := len(s.outputs) + 1
+
+	if solver == RK45 {
+		if dt == 0 {
+			dt = tMax / float64(pointsExported)
+		}
+		resultData := &dataSet{cols: cols}
+		_, err := rk45(s.derivative, x, tMax, dt, func(t float64, x Vector) error {
+			err := s.accept(t, x)
+			if err != nil {
+				return err
+			}
+			resultData.elements = append(resultData.elements, t)
+			resultData.elements = append(resultData.elements, s.values...)
+			resultData.rows++
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return resultData, nil
+	}
+
 	if dt == 0 {
-		dt = 1e-4
+		if solver == Euler {
+			dt = 1e-4
+		} else {
+			dt = tMax / float64(pointsExported)
+		}
 	}
 
 	pointsCalculated := int(tMax / dt)
@@ -369,44 +606,38 @@ func (s *System) Run(tMax, dt float64, pointsExported int) (*dataSet, error) {
 		skip = 1
 	}
 
-	t := 0.0
-
-	nextValues := make([]float64, len(s.values))
 	dataSetRows := pointsExported + 10
+	steps := 10 + pointsExported*skip
 
-	resultData := newDataSet(dataSetRows, len(s.outputs)+1)
+	resultData := newDataSet(dataSetRows, cols)
 
 	counter := 0
 	row := 0
-	for {
-		if counter == 0 || row < 10 {
+	err := fixedStep(solver, s.derivative, x, steps, dt, func(t float64, x Vector) error {
+		err := s.accept(t, x)
+		if err != nil {
+			return err
+		}
+		if row < dataSetRows && (counter == 0 || row < 10) {
 			counter = skip
 			resultData.set(row, 0, t)
 			for i, y := range s.values {
 				resultData.set(row, i+1, y)
 			}
 			row++
-			if row >= dataSetRows {
-				break
-			}
 		}
 		counter--
-
-		for i, next := range s.next {
-			var err error
-			nextValues[i], err = next(t, dt)
-			if err != nil {
-				return nil, fmt.Errorf("error in block %d: %w", i, err)
-			}
-		}
-		copy(s.values, nextValues)
-		t += dt
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	resultData.rows = row
 
 	return resultData, nil
 }
 
-func SimulateBlock(st funcGen.Stack[value.Value], def *value.List, tMax, dt float64, pointsExported int) (value.Value, error) {
+func SimulateBlock(st funcGen.Stack[value.Value], def *value.List, solver Solver, tMax, dt float64, pointsExported int) (value.Value, error) {
 	sys := NewSystem()
 	for v, err := range def.Iterate(st) {
 		if err != nil {
@@ -444,7 +675,7 @@ func SimulateBlock(st funcGen.Stack[value.Value], def *value.List, tMax, dt floa
 		return nil, err
 	}
 
-	resultData, err := sys.Run(tMax, dt, pointsExported)
+	resultData, err := sys.RunSolver(solver, tMax, dt, pointsExported)
 	if err != nil {
 		return nil, err
 	}
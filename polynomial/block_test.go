@@ -4,6 +4,7 @@ import (
 	"github.com/hneemann/control/graph"
 	"github.com/hneemann/parser2/value/export/xmlWriter"
 	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
 )
 
@@ -43,3 +44,94 @@ func TestSimple(t *testing.T) {
 	assert.NoError(t, svg.Close())
 
 }
+
+func TestSolver(t *testing.T) {
+	// first order low pass, step response is 1-exp(-t)
+	lin := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}
+	for _, solver := range []Solver{Euler, RK4, RK45} {
+		t.Run(solver.String(), func(tt *testing.T) {
+			s := NewSystem().
+				AddBlock([]string{}, "u", Const(1)).
+				AddBlock([]string{"u"}, "y", BlockLinear(lin))
+			assert.NoError(tt, s.Initialize())
+
+			data, err := s.RunSolver(solver, 5, 0, 0)
+			assert.NoError(tt, err)
+			assert.True(tt, data.rows > 10)
+
+			last := data.rows - 1
+			tl := data.get(last, 0)
+			assert.InDelta(tt, 1-math.Exp(-tl), data.get(last, 2), 1e-3)
+		})
+	}
+}
+
+func TestAlgebraicLoop(t *testing.T) {
+	s := NewSystem().
+		AddBlock([]string{"b"}, "a", Gain(2)).
+		AddBlock([]string{"a"}, "b", Gain(0.5))
+	err := s.Initialize()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "algebraic loop")
+}
+
+func TestDeadTimeLoop(t *testing.T) {
+	// closed loop of 0.5*e^{-s}/(s+1), the steady state is 1/3
+	lin := &Linear{Numerator: Polynomial{0.5}, Denominator: Polynomial{1, 1}, Delay: 1}
+	s := NewSystem().
+		AddBlock([]string{}, "w", Const(1)).
+		AddBlock([]string{"w", "y"}, "e", Sub()).
+		AddBlock([]string{"e"}, "y", BlockLinear(lin))
+	assert.NoError(t, s.Initialize())
+
+	data, err := s.RunSolver(RK4, 30, 0.01, 0)
+	assert.NoError(t, err)
+
+	yCol := 0
+	for i, name := range s.outputs {
+		if name == "y" {
+			yCol = i + 1
+		}
+	}
+	for row := range data.rows {
+		tr := data.get(row, 0)
+		if tr < 1 {
+			assert.InDelta(t, 0, data.get(row, yCol), 1e-9)
+		}
+	}
+	assert.InDelta(t, 1.0/3, data.get(data.rows-1, yCol), 1e-3)
+}
+
+func TestAlgebraicLoopDelay(t *testing.T) {
+	// the plant (0.5s+1)/(s+1) has a direct feed through, so the
+	// loop needs a delay to be simulated
+	lin := &Linear{Numerator: Polynomial{1, 0.5}, Denominator: Polynomial{1, 1}}
+	s := NewSystem().
+		AddBlock([]string{}, "w", Const(1)).
+		AddBlock([]string{"w", "y"}, "e", Sub()).
+		AddBlock([]string{"e"}, "y", BlockLinear(lin))
+	err := s.Initialize()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "algebraic loop")
+
+	// the delay by one step restores the behavior of earlier versions
+	const dt = 1e-3
+	s = NewSystem().
+		AddBlock([]string{}, "w", Const(1)).
+		AddBlock([]string{"w", "yd"}, "e", Sub()).
+		AddBlock([]string{"e"}, "y", BlockLinear(lin)).
+		AddBlock([]string{"y"}, "yd", Delay(dt))
+	assert.NoError(t, s.Initialize())
+
+	data, err := s.Run(10, dt, 0)
+	assert.NoError(t, err)
+
+	yCol := 0
+	for i, name := range s.outputs {
+		if name == "y" {
+			yCol = i + 1
+		}
+	}
+	// the closed loop is (0.5s+1)/(1.5s+2), the steady state is 1/2
+	assert.InDelta(t, 0.5, data.get(data.rows-1, yCol), 1e-3)
+}
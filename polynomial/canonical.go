@@ -0,0 +1,310 @@
+package polynomial
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Controllability returns the controllability matrix [B, AB, A²B, ..., Aⁿ⁻¹B]
+func (s *StateSpace) Controllability() Matrix {
+	n := s.Order()
+	m := s.Inputs()
+	q := NewMatrix(n, n*m)
+	ak := s.B
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			copy(q[i][k*m:], ak[i])
+		}
+		ak = mul(s.A, ak, m)
+	}
+	return q
+}
+
+// Observability returns the observability matrix [C; CA; CA²; ...; CAⁿ⁻¹]
+func (s *StateSpace) Observability() Matrix {
+	n := s.Order()
+	q := make(Matrix, 0, n*s.Outputs())
+	ck := s.C
+	for k := 0; k < n; k++ {
+		q = append(q, ck.Copy()...)
+		ck = mul(ck, s.A, n)
+	}
+	return q
+}
+
+func (s *StateSpace) IsControllable() bool {
+	return s.Controllability().Rank() == s.Order()
+}
+
+func (s *StateSpace) IsObservable() bool {
+	return s.Observability().Rank() == s.Order()
+}
+
+// Transform returns the system with the new state x̃=T⁻¹x, which is
+// the system (T⁻¹AT, T⁻¹B, CT, D).
+func (s *StateSpace) Transform(t Matrix) (*StateSpace, error) {
+	n := s.Order()
+	if t.Rows() != n || t.Cols() != n {
+		return nil, fmt.Errorf("the transformation matrix needs to be a %dx%d matrix", n, n)
+	}
+	a, err := t.Solve(mul(s.A, t, n))
+	if err != nil {
+		return nil, fmt.Errorf("the transformation matrix is singular: %w", err)
+	}
+	b, err := t.Solve(s.B)
+	if err != nil {
+		return nil, fmt.Errorf("the transformation matrix is singular: %w", err)
+	}
+	return NewStateSpace(a, b, mul(s.C, t, n), s.D.Copy())
+}
+
+// basisTolerance is the relative tolerance used to detect linear dependent vectors
+const basisTolerance = 1e-9
+
+// extendBasis orthogonalizes the candidates against the orthonormal basis using
+// the modified Gram-Schmidt method. The returned orthonormal vectors are the
+// candidates which are linear independent of the basis and of each other.
+// The returned vectors are elements of the span of the basis and the candidates.
+func extendBasis(basis []Vector, candidates []Vector) []Vector {
+	scale := 0.0
+	for _, c := range candidates {
+		scale = math.Max(scale, math.Sqrt(c.Mul(c)))
+	}
+	all := append([]Vector{}, basis...)
+	var added []Vector
+	for _, c := range candidates {
+		v := append(Vector{}, c...)
+		for range 2 {
+			// orthogonalize twice to keep the orthogonality
+			for _, b := range all {
+				v.Add(-b.Mul(v), b)
+			}
+		}
+		l := math.Sqrt(v.Mul(v))
+		if l > basisTolerance*math.Max(1, scale) {
+			for i := range v {
+				v[i] /= l
+			}
+			all = append(all, v)
+			added = append(added, v)
+		}
+	}
+	return added
+}
+
+// complement returns an orthonormal basis of the orthogonal complement
+// of the span of the given orthonormal basis in the n-dimensional space.
+func complement(basis []Vector, n int) []Vector {
+	return extendBasis(basis, Identity(n))
+}
+
+// columns returns the columns of the matrix as vectors
+func (m Matrix) columns() []Vector {
+	return m.Transpose()
+}
+
+// columnMatrix creates a matrix with the given vectors as columns
+func columnMatrix(n int, vectors ...[]Vector) Matrix {
+	m := NewMatrix(n, 0)
+	for _, vl := range vectors {
+		for _, v := range vl {
+			for i := range m {
+				m[i] = append(m[i], v[i])
+			}
+		}
+	}
+	return m
+}
+
+// KalmanDecomposition is the system transformed to the Kalman canonical
+// decomposition. The states are ordered as controllable and observable,
+// controllable and not observable, not controllable and observable and
+// neither controllable nor observable.
+type KalmanDecomposition struct {
+	System *StateSpace
+	// T is the transformation matrix with x=Tx̃
+	T Matrix
+	// Dims are the number of states of the four parts
+	Dims [4]int
+}
+
+// KalmanDecomposition calculates the Kalman decomposition of the system.
+func (s *StateSpace) KalmanDecomposition() (*KalmanDecomposition, error) {
+	n := s.Order()
+	if n == 0 {
+		return nil, errors.New("the system has no states")
+	}
+	controllable := extendBasis(nil, s.Controllability().columns())
+	observable := extendBasis(nil, s.Observability())
+	unobservable := complement(observable, n)
+
+	// the intersection of two spaces is the complement of the sum of their complements
+	uncontrollable := complement(controllable, n)
+	sum := append(uncontrollable, extendBasis(uncontrollable, observable)...)
+	cNo := complement(sum, n)
+
+	// cNo is a subspace of both spaces, so the extensions stay in the respective space
+	cO := extendBasis(cNo, controllable)
+	nCNo := extendBasis(cNo, unobservable)
+	nCO := complement(extendBasis(nil, columnMatrix(n, cO, cNo, nCNo).columns()), n)
+
+	t := columnMatrix(n, cO, cNo, nCO, nCNo)
+	sys, err := s.Transform(t)
+	if err != nil {
+		return nil, err
+	}
+	return &KalmanDecomposition{
+		System: sys,
+		T:      t,
+		Dims:   [4]int{len(cO), len(cNo), len(nCO), len(nCNo)},
+	}, nil
+}
+
+// minimal returns a minimal realization of the system, which is the
+// controllable and observable part of the Kalman decomposition.
+func (s *StateSpace) minimal() (*StateSpace, error) {
+	if s.Order() == 0 {
+		return s, nil
+	}
+	kd, err := s.KalmanDecomposition()
+	if err != nil {
+		return nil, err
+	}
+	k := kd.Dims[0]
+	a := NewMatrix(k, k)
+	b := NewMatrix(k, s.Inputs())
+	c := NewMatrix(s.Outputs(), k)
+	for i := 0; i < k; i++ {
+		copy(a[i], kd.System.A[i][:k])
+		copy(b[i], kd.System.B[i])
+	}
+	for i := range c {
+		copy(c[i], kd.System.C[i][:k])
+	}
+	return &StateSpace{A: a, B: b, C: c, D: kd.System.D}, nil
+}
+
+// ControllableForm returns the controllable canonical form of the system.
+func (s *StateSpace) ControllableForm() (*StateSpace, error) {
+	if !s.isSISO() {
+		return nil, errors.New("the controllable canonical form requires a single input single output system")
+	}
+	if !s.IsControllable() {
+		return nil, errors.New("the system is not controllable")
+	}
+	lin, err := s.Linear()
+	if err != nil {
+		return nil, err
+	}
+	return lin.StateSpace()
+}
+
+// ObservableForm returns the observable canonical form of the system.
+func (s *StateSpace) ObservableForm() (*StateSpace, error) {
+	if !s.isSISO() {
+		return nil, errors.New("the observable canonical form requires a single input single output system")
+	}
+	if !s.IsObservable() {
+		return nil, errors.New("the system is not observable")
+	}
+	lin, err := s.Linear()
+	if err != nil {
+		return nil, err
+	}
+	return lin.ObservableForm()
+}
+
+// ModalForm returns the modal canonical form of the system.
+func (s *StateSpace) ModalForm() (*StateSpace, error) {
+	if !s.isSISO() {
+		return nil, errors.New("the modal canonical form requires a single input single output system")
+	}
+	lin, err := s.Linear()
+	if err != nil {
+		return nil, err
+	}
+	return lin.ModalForm()
+}
+
+// errDeadTime is returned if a system with dead time is converted to the state space representation
+var errDeadTime = errors.New("a system with dead time has no state space representation, use pade to approximate the dead time")
+
+// ControllableForm returns the controllable canonical form of the transfer function.
+func (l *Linear) ControllableForm() (*StateSpace, error) {
+	return l.StateSpace()
+}
+
+// ObservableForm returns the observable canonical form of the transfer function.
+// It is the dual of the controllable canonical form.
+func (l *Linear) ObservableForm() (*StateSpace, error) {
+	c, err := l.ControllableForm()
+	if err != nil {
+		return nil, err
+	}
+	return &StateSpace{A: c.A.Transpose(), B: c.C.Transpose(), C: c.B.Transpose(), D: c.D}, nil
+}
+
+// ModalForm returns the modal canonical form of the transfer function.
+// Every real pole becomes a diagonal element of the matrix A, a complex
+// pair of poles σ±jω becomes the block [[σ,ω],[-ω,σ]]. Repeated real poles
+// lead to jordan blocks.
+func (l *Linear) ModalForm() (*StateSpace, error) {
+	if l.Delay != 0 {
+		return nil, errDeadTime
+	}
+	if !l.IsCausal() {
+		return nil, errors.New("the system is not causal")
+	}
+	pf, err := l.Residues()
+	if err != nil {
+		return nil, err
+	}
+	d := 0.0
+	if len(pf.Direct) > 0 {
+		d = pf.Direct[0]
+	}
+
+	n := l.Denominator.Canonical().Degree()
+	a := NewMatrix(n, n)
+	b := NewMatrix(n, 1)
+	c := NewMatrix(1, n)
+	o := 0
+	terms := pf.Terms
+	for i := 0; i < len(terms); {
+		p := terms[i].Pole
+		// the terms of a pole are ordered by ascending power
+		m := 1
+		for i+m < len(terms) && terms[i+m].Pole == p {
+			m++
+		}
+		switch {
+		case imag(p) < 0:
+		case imag(p) == 0:
+			for k := 0; k < m; k++ {
+				a[o+k][o+k] = real(p)
+				if k < m-1 {
+					a[o+k][o+k+1] = 1
+				}
+				c[0][o+k] = real(terms[i+m-1-k].Residue)
+			}
+			b[o+m-1][0] = 1
+			o += m
+		default:
+			if m > 1 {
+				return nil, errors.New("repeated complex poles are not supported by the modal form")
+			}
+			r := terms[i].Residue
+			a[o][o] = real(p)
+			a[o][o+1] = imag(p)
+			a[o+1][o] = -imag(p)
+			a[o+1][o+1] = real(p)
+			b[o+1][0] = 1
+			c[0][o] = -2 * imag(r)
+			c[0][o+1] = 2 * real(r)
+			o += 2
+		}
+		i += m
+	}
+	return NewStateSpace(a, b, c, Matrix{{d}})
+}
@@ -0,0 +1,132 @@
+package polynomial
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestStateSpace_Controllability(t *testing.T) {
+	// the first state is controllable but not observable, the second one is observable but not controllable
+	sys, err := NewStateSpace(Matrix{{-1, 0}, {0, -2}}, Matrix{{1}, {0}}, Matrix{{0, 1}}, Matrix{{0}})
+	assert.NoError(t, err)
+
+	assert.EqualValues(t, Matrix{{1, -1}, {0, 0}}, sys.Controllability())
+	assert.EqualValues(t, Matrix{{0, 1}, {0, -2}}, sys.Observability())
+	assert.False(t, sys.IsControllable())
+	assert.False(t, sys.IsObservable())
+
+	lin := &Linear{Numerator: Polynomial{1, 2}, Denominator: Polynomial{2, 3, 1}}
+	ss, err := lin.StateSpace()
+	assert.NoError(t, err)
+	assert.True(t, ss.IsControllable())
+	assert.True(t, ss.IsObservable())
+
+	// pole-zero cancellation
+	lin = &Linear{Numerator: Polynomial{1, 1}, Denominator: Polynomial{2, 3, 1}}
+	ss, err = lin.StateSpace()
+	assert.NoError(t, err)
+	assert.True(t, ss.IsControllable())
+	assert.False(t, ss.IsObservable())
+}
+
+func assertMatrixZero(t *testing.T, m Matrix, r0, r1, c0, c1 int) {
+	for r := r0; r < r1; r++ {
+		for c := c0; c < c1; c++ {
+			assert.InDelta(t, 0, m[r][c], 1e-9, "element %d,%d", r, c)
+		}
+	}
+}
+
+func TestStateSpace_KalmanDecomposition(t *testing.T) {
+	a := Matrix{
+		{-1, 0, 0, 0},
+		{0, -2, 0, 0},
+		{0, 0, -3, 0},
+		{0, 0, 0, -4},
+	}
+	// mix the states to hide the structure
+	m := Matrix{
+		{1, 1, 0, 0},
+		{0, 1, 1, 0},
+		{0, 0, 1, 1},
+		{1, 0, 0, 1.5},
+	}
+	orig, err := NewStateSpace(a, Matrix{{1}, {1}, {0}, {0}}, Matrix{{1, 0, 1, 0}}, Matrix{{0}})
+	assert.NoError(t, err)
+	mInv, err := m.Inverse()
+	assert.NoError(t, err)
+	sys, err := orig.Transform(mInv)
+	assert.NoError(t, err)
+
+	kd, err := sys.KalmanDecomposition()
+	assert.NoError(t, err)
+	assert.Equal(t, [4]int{1, 1, 1, 1}, kd.Dims)
+
+	k := kd.System
+	// uncontrollable states are not influenced by the controllable ones and by the input
+	assertMatrixZero(t, k.A, 2, 4, 0, 2)
+	assertMatrixZero(t, k.B, 2, 4, 0, 1)
+	// unobservable states do not influence the observable ones and the output
+	assertMatrixZero(t, k.A, 0, 1, 1, 2)
+	assertMatrixZero(t, k.A, 0, 1, 3, 4)
+	assertMatrixZero(t, k.A, 2, 3, 3, 4)
+	assertMatrixZero(t, k.C, 0, 1, 1, 2)
+	assertMatrixZero(t, k.C, 0, 1, 3, 4)
+
+	// the controllable and observable part is 1/(s+1)
+	assert.InDelta(t, -1, k.A[0][0], 1e-9)
+	assert.InDelta(t, 1, k.B[0][0]*k.C[0][0], 1e-9)
+}
+
+func TestLinear_CanonicalForms(t *testing.T) {
+	tests := []struct {
+		name string
+		lin  *Linear
+	}{
+		{"PT1", &Linear{Numerator: Polynomial{2}, Denominator: Polynomial{1, 3}}},
+		{"complex", &Linear{Numerator: Polynomial{1, 2}, Denominator: Polynomial{1, 1}.Mul(Polynomial{5, 2, 1})}},
+		{"repeated", &Linear{Numerator: Polynomial{3, 1}, Denominator: Polynomial{1, 1}.Mul(Polynomial{1, 1}).Mul(Polynomial{2, 1})}},
+		{"direct", &Linear{Numerator: Polynomial{1, 2, 3}, Denominator: Polynomial{4, 3, 1}}},
+		{"integrator", &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{0, 2, 1}}},
+	}
+	forms := []struct {
+		name string
+		f    func(l *Linear) (*StateSpace, error)
+	}{
+		{"controllable", (*Linear).ControllableForm},
+		{"observable", (*Linear).ObservableForm},
+		{"modal", (*Linear).ModalForm},
+	}
+	for _, tt := range tests {
+		for _, form := range forms {
+			t.Run(tt.name+"-"+form.name, func(t *testing.T) {
+				ss, err := form.f(tt.lin)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.lin.Denominator.Degree(), ss.Order())
+				lin, err := ss.Linear()
+				assert.NoError(t, err)
+				expected := normalized(t, tt.lin)
+				for _, w := range []float64{0.1, 1, 10} {
+					s := complex(0, w)
+					d := expected.EvalCplx(s) - lin.EvalCplx(s)
+					assert.InDelta(t, 0, math.Hypot(real(d), imag(d)), 1e-6, lin.String())
+				}
+			})
+		}
+	}
+}
+
+func TestLinear_ModalForm(t *testing.T) {
+	// the poles are sorted by descending real part
+	lin := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{3, 1}.Mul(Polynomial{5, 2, 1})}
+	ss, err := lin.ModalForm()
+	assert.NoError(t, err)
+	assertMatrixZero(t, ss.A, 0, 2, 2, 3)
+	assertMatrixZero(t, ss.A, 2, 3, 0, 2)
+	assert.InDelta(t, -1, ss.A[0][0], 1e-6)
+	assert.InDelta(t, -1, ss.A[1][1], 1e-6)
+	assert.InDelta(t, 2, ss.A[0][1], 1e-6)
+	assert.InDelta(t, -2, ss.A[1][0], 1e-6)
+	assert.InDelta(t, -3, ss.A[2][2], 1e-6)
+}
@@ -0,0 +1,129 @@
+package polynomial
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hneemann/parser2/value"
+	"math"
+	"math/cmplx"
+)
+
+// DCGain returns the static gain G(0). Common factors s in the numerator and
+// the denominator are cancelled. If the system has more poles than zeros at
+// s=0, the gain is infinite.
+func (l *Linear) DCGain() float64 {
+	lowest := func(p Polynomial) int {
+		for i, c := range p {
+			if c != 0 {
+				return i
+			}
+		}
+		return len(p)
+	}
+	n := lowest(l.Numerator)
+	d := lowest(l.Denominator)
+	switch {
+	case n >= len(l.Numerator):
+		return 0
+	case n > d:
+		return 0
+	case n < d:
+		if (l.Numerator[n] < 0) != (l.Denominator[d] < 0) {
+			return math.Inf(-1)
+		}
+		return math.Inf(1)
+	default:
+		return l.Numerator[n] / l.Denominator[d]
+	}
+}
+
+// Bandwidth returns the frequency at which the amplitude drops by the given
+// value in dB below the dc gain for the first time.
+func (l *Linear) Bandwidth(dB float64) (float64, error) {
+	dc := math.Abs(l.DCGain())
+	if dc == 0 || math.IsInf(dc, 0) {
+		return 0, errors.New("the bandwidth requires a finite dc gain which is not zero")
+	}
+	limit := dc * math.Pow(10, -math.Abs(dB)/20)
+
+	abs := func(w float64) float64 {
+		return cmplx.Abs(l.EvalCplx(complex(0, w))) - limit
+	}
+	wb := 0.0
+	err := l.scanFrequencies(abs, func(w0, w1 float64) error {
+		if wb > 0 {
+			return nil
+		}
+		w, err := value.Bisection(func(w float64) (float64, error) {
+			return abs(w), nil
+		}, w0, w1, 1e-10*dc)
+		if err != nil {
+			return err
+		}
+		wb = w
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if wb == 0 {
+		return 0, errors.New("the amplitude does not drop below the limit")
+	}
+	return wb, nil
+}
+
+// Resonance returns the frequency and the value of the maximum amplitude.
+// If the amplitude has its maximum at ω=0, a frequency of zero is returned.
+// Poles on the imaginary axis are rejected, since the amplitude is infinite there.
+func (l *Linear) Resonance() (float64, float64, error) {
+	dc := math.Abs(l.DCGain())
+	if math.IsInf(dc, 0) {
+		return 0, 0, errors.New("the amplitude is infinite at ω=0")
+	}
+	poles, err := l.Poles()
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, p := range poles.roots {
+		if math.Abs(real(p)) < eps && imag(p) > eps && cmplx.Abs(l.Numerator.EvalCplx(p)) > eps {
+			return 0, 0, fmt.Errorf("the amplitude is infinite at ω=%g", imag(p))
+		}
+	}
+	w, m, err := l.findMinimum(func(w float64) float64 {
+		a := cmplx.Abs(l.EvalCplx(complex(0, w)))
+		if math.IsNaN(a) {
+			return math.Inf(-1)
+		}
+		return -a
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	if -m <= dc {
+		return 0, dc, nil
+	}
+	return w, -m, nil
+}
+
+// NaturalFrequency describes a complex pole pair by the natural frequency Wn and the damping ratio Zeta
+type NaturalFrequency struct {
+	Pole complex128
+	Wn   float64
+	Zeta float64
+}
+
+// NaturalFrequencies returns the natural frequency and the damping ratio of all complex pole pairs
+func (l *Linear) NaturalFrequencies() ([]NaturalFrequency, error) {
+	poles, err := l.Poles()
+	if err != nil {
+		return nil, err
+	}
+	var nf []NaturalFrequency
+	for _, p := range poles.roots {
+		if imag(p) > eps {
+			wn := cmplx.Abs(p)
+			nf = append(nf, NaturalFrequency{Pole: p, Wn: wn, Zeta: -real(p) / wn})
+		}
+	}
+	return nf, nil
+}
@@ -0,0 +1,84 @@
+package polynomial
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestLinear_DCGain(t *testing.T) {
+	tests := []struct {
+		name string
+		lin  *Linear
+		want float64
+	}{
+		{"simple", &Linear{Numerator: Polynomial{3}, Denominator: Polynomial{2, 3, 1}}, 1.5},
+		{"integrator", &Linear{Numerator: Polynomial{3}, Denominator: Polynomial{0, 2, 1}}, math.Inf(1)},
+		{"negIntegrator", &Linear{Numerator: Polynomial{-3}, Denominator: Polynomial{0, 2, 1}}, math.Inf(-1)},
+		{"differentiator", &Linear{Numerator: Polynomial{0, 3}, Denominator: Polynomial{2, 1}}, 0},
+		{"cancel", &Linear{Numerator: Polynomial{0, 3}, Denominator: Polynomial{0, 2, 1}}, 1.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.lin.DCGain())
+		})
+	}
+}
+
+func TestLinear_Bandwidth(t *testing.T) {
+	l := &Linear{Numerator: Polynomial{2}, Denominator: Polynomial{1, 1}}
+	wb, err := l.Bandwidth(3)
+	assert.NoError(t, err)
+	assert.InDelta(t, math.Sqrt(math.Pow(10, 0.3)-1), wb, 1e-8)
+
+	_, err = (&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{0, 1}}).Bandwidth(3)
+	assert.Error(t, err)
+
+	// the amplitude rises above the dc gain before it drops
+	l = &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 0.2, 1}}
+	wb, err = l.Bandwidth(3)
+	assert.NoError(t, err)
+	assert.True(t, wb > 1)
+	assert.InDelta(t, math.Pow(10, -0.15), cmplx.Abs(l.EvalCplx(complex(0, wb))), 1e-8)
+
+	// the narrow notch is the first drop below the limit
+	l = &Linear{Numerator: Polynomial{1, 0.01, 1}, Denominator: Polynomial{1, 0.1, 1}}
+	wb, err = l.Bandwidth(3)
+	assert.NoError(t, err)
+	assert.True(t, wb > 0.9 && wb < 1)
+	assert.InDelta(t, math.Pow(10, -0.15), cmplx.Abs(l.EvalCplx(complex(0, wb))), 1e-8)
+}
+
+func TestLinear_Resonance(t *testing.T) {
+	zeta := 0.1
+	l := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 2 * zeta, 1}}
+	wr, mr, err := l.Resonance()
+	assert.NoError(t, err)
+	assert.InDelta(t, math.Sqrt(1-2*zeta*zeta), wr, 1e-6)
+	assert.InDelta(t, 1/(2*zeta*math.Sqrt(1-zeta*zeta)), mr, 1e-9)
+
+	l = &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1.6, 1}}
+	wr, mr, err = l.Resonance()
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, wr)
+	assert.Equal(t, 1.0, mr)
+
+	l = &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 0, 1}}
+	_, _, err = l.Resonance()
+	assert.Error(t, err)
+}
+
+func TestLinear_NaturalFrequencies(t *testing.T) {
+	l := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{4, 1, 1}.Mul(Polynomial{9, 3, 1}).Mul(Polynomial{1, 1})}
+	nf, err := l.NaturalFrequencies()
+	assert.NoError(t, err)
+	assert.Len(t, nf, 2)
+	if nf[0].Wn > nf[1].Wn {
+		nf[0], nf[1] = nf[1], nf[0]
+	}
+	assert.InDelta(t, 2, nf[0].Wn, 1e-9)
+	assert.InDelta(t, 0.25, nf[0].Zeta, 1e-9)
+	assert.InDelta(t, 3, nf[1].Wn, 1e-9)
+	assert.InDelta(t, 0.5, nf[1].Zeta, 1e-9)
+}
@@ -0,0 +1,191 @@
+package polynomial
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hneemann/control/graph"
+	"github.com/hneemann/control/graph/grParser"
+	"math"
+	"math/cmplx"
+)
+
+// arcPath is a circular arc from the angle a0 to the angle a1
+type arcPath struct {
+	center graph.Point
+	radius float64
+	a0, a1 float64
+}
+
+func (a arcPath) point(angle float64) graph.Point {
+	return graph.Point{X: a.center.X + a.radius*math.Cos(angle), Y: a.center.Y + a.radius*math.Sin(angle)}
+}
+
+func (a arcPath) Iter(yield func(graph.PathElement, error) bool) {
+	const steps = 360
+	for i := 0; i <= steps; i++ {
+		mode := 'L'
+		if i == 0 {
+			mode = 'M'
+		}
+		p := a.point(a.a0 + (a.a1-a.a0)*float64(i)/steps)
+		if !yield(graph.PathElement{Mode: mode, Point: p}, nil) {
+			return
+		}
+	}
+}
+
+func (a arcPath) IsClosed() bool {
+	return false
+}
+
+func gridStyle(env *graph.ChartContentEnvironment) *graph.Style {
+	style := env.Chart.X.Grid
+	if style == nil {
+		style = grParser.GridStyle
+	}
+	return style
+}
+
+// drawLabel draws the label at the first of the given points which is inside the chart
+func drawLabel(env *graph.ChartContentEnvironment, label string, style *graph.Style, points ...graph.Point) {
+	r := env.Canvas.Rect()
+	for _, p := range points {
+		if r.Contains(p) {
+			env.Canvas.DrawText(p, label, graph.Bottom|graph.Left, style.Text(), env.Canvas.Context().TextSize*0.8)
+			return
+		}
+	}
+}
+
+// MCircles are the circles of constant magnitude of the closed loop
+// in the nyquist plot of the open loop. The magnitudes are given in dB.
+type MCircles struct {
+	Values []float64
+}
+
+func (m MCircles) String() string {
+	return "M-Circles"
+}
+
+func (m MCircles) Bounds() (x, y graph.Bounds, e error) {
+	return graph.Bounds{}, graph.Bounds{}, nil
+}
+
+func (m MCircles) DependantBounds(_, _ graph.Bounds) (x, y graph.Bounds, e error) {
+	return graph.Bounds{}, graph.Bounds{}, nil
+}
+
+// mCircleArc returns the circle of the open loop G on which the closed loop T=G/(1+G) has the magnitude db
+func mCircleArc(db float64) arcPath {
+	mag := math.Pow(10, db/20)
+	m2 := mag * mag
+	return arcPath{
+		center: graph.Point{X: -m2 / (m2 - 1)},
+		radius: math.Abs(mag / (m2 - 1)),
+		a0:     0,
+		a1:     2 * math.Pi,
+	}
+}
+
+func (m MCircles) DrawTo(env *graph.ChartContentEnvironment) error {
+	style := gridStyle(env)
+	r := env.Canvas.Rect()
+	for _, db := range m.Values {
+		label := fmt.Sprintf("%gdB", db)
+		if db == 0 {
+			// the circle degenerates to the line Re=-1/2
+			err := env.Canvas.DrawPath(r.IntersectPath(graph.PointsFromSlice(graph.Point{X: -0.5, Y: r.Min.Y}, graph.Point{X: -0.5, Y: r.Max.Y})), style)
+			if err != nil {
+				return err
+			}
+			drawLabel(env, label, style, graph.Point{X: -0.5, Y: (r.Min.Y + r.Max.Y) / 2})
+			continue
+		}
+		arc := mCircleArc(db)
+		err := env.Canvas.DrawPath(r.IntersectPath(arc), style)
+		if err != nil {
+			return err
+		}
+		drawLabel(env, label, style, arc.point(math.Pi/2), arc.point(-math.Pi/2), arc.point(math.Pi/4), arc.point(3*math.Pi/4))
+	}
+	return nil
+}
+
+func (m MCircles) Legend() []graph.Legend {
+	return nil
+}
+
+// NCircles are the curves of constant phase of the closed loop in the nyquist
+// plot of the open loop. The phases are given in degrees. Only the arc on which
+// the closed loop has the given phase is drawn, it runs from 0 to -1.
+type NCircles struct {
+	Values []float64
+}
+
+func NewNCircles(values []float64) (NCircles, error) {
+	for _, v := range values {
+		if math.Abs(math.Remainder(v, 180)) < 1e-9 {
+			return NCircles{}, errors.New("the phase of an N-circle must not be a multiple of 180°")
+		}
+	}
+	return NCircles{Values: values}, nil
+}
+
+func (n NCircles) String() string {
+	return "N-Circles"
+}
+
+func (n NCircles) Bounds() (x, y graph.Bounds, e error) {
+	return graph.Bounds{}, graph.Bounds{}, nil
+}
+
+func (n NCircles) DependantBounds(_, _ graph.Bounds) (x, y graph.Bounds, e error) {
+	return graph.Bounds{}, graph.Bounds{}, nil
+}
+
+// nCircleArc returns the arc of the open loop G on which the closed loop T=G/(1+G) has the phase alpha
+func nCircleArc(alpha float64) (arcPath, graph.Point) {
+	a := alpha / 180 * math.Pi
+	center := complex(-0.5, 0.5/math.Tan(a))
+	// the open loop at which the closed loop has the magnitude one
+	t := cmplx.Rect(1, a)
+	g1 := t / (1 - t)
+
+	a0 := cmplx.Phase(-center)
+	a1 := cmplx.Phase(-1 - center)
+	am := cmplx.Phase(g1 - center)
+	// sweep in the direction which contains the point g1
+	for a1 < a0 {
+		a1 += 2 * math.Pi
+	}
+	for am < a0 {
+		am += 2 * math.Pi
+	}
+	if am > a1 {
+		a1 -= 2 * math.Pi
+	}
+	return arcPath{
+		center: graph.Point{X: real(center), Y: imag(center)},
+		radius: 0.5 / math.Abs(math.Sin(a)),
+		a0:     a0,
+		a1:     a1,
+	}, graph.Point{X: real(g1), Y: imag(g1)}
+}
+
+func (n NCircles) DrawTo(env *graph.ChartContentEnvironment) error {
+	style := gridStyle(env)
+	r := env.Canvas.Rect()
+	for _, alpha := range n.Values {
+		arc, mid := nCircleArc(alpha)
+		err := env.Canvas.DrawPath(r.IntersectPath(arc), style)
+		if err != nil {
+			return err
+		}
+		drawLabel(env, fmt.Sprintf("%g°", alpha), style, mid, arc.point((arc.a0*3+arc.a1)/4), arc.point((arc.a0+arc.a1*3)/4))
+	}
+	return nil
+}
+
+func (n NCircles) Legend() []graph.Legend {
+	return nil
+}
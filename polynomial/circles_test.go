@@ -0,0 +1,41 @@
+package polynomial
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func closedLoop(t *testing.T, arc arcPath) []complex128 {
+	var cl []complex128
+	for pe, err := range arc.Iter {
+		assert.NoError(t, err)
+		g := complex(pe.Point.X, pe.Point.Y)
+		cl = append(cl, g/(1+g))
+	}
+	return cl
+}
+
+func TestMCircles(t *testing.T) {
+	for _, db := range []float64{-6, -1, 1, 6} {
+		m := math.Pow(10, db/20)
+		for _, c := range closedLoop(t, mCircleArc(db)) {
+			assert.InDelta(t, m, cmplx.Abs(c), 1e-9)
+		}
+	}
+}
+
+func TestNCircles(t *testing.T) {
+	for _, alpha := range []float64{-150, -90, -30, -5, 10, 60} {
+		arc, _ := nCircleArc(alpha)
+		cl := closedLoop(t, arc)
+		// the end points are G=0 and G=-1
+		for _, c := range cl[1 : len(cl)-1] {
+			assert.InDelta(t, alpha, cmplx.Phase(c)/math.Pi*180, 1e-6)
+		}
+	}
+
+	_, err := NewNCircles([]float64{-30, -180})
+	assert.Error(t, err)
+}
@@ -0,0 +1,465 @@
+package polynomial
+
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// RootInfo describes a root of a polynomial
+type RootInfo struct {
+	Root         complex128
+	Multiplicity int
+	// Error is an estimate of the absolute error of the root
+	Error float64
+}
+
+// multiplicityTolerance is the relative residual up to which a cluster of
+// roots is accepted as a multiple root
+const multiplicityTolerance = 1e-12
+
+// RootInfo calculates the roots of the polynomial, their multiplicities and
+// an estimate of their errors. Only the roots with a non-negative imaginary
+// part are returned. The roots are the eigenvalues of the companion matrix,
+// which are calculated by the QR algorithm. Clusters of roots which belong
+// to a multiple root are replaced by their refined mean value.
+func (p Polynomial) RootInfo() ([]RootInfo, error) {
+	if len(p) == 0 {
+		return nil, errors.New("no coefficients given")
+	}
+	if p[len(p)-1] == 0 {
+		return nil, errors.New("not canonical")
+	}
+
+	zeros := 0
+	for zeros < len(p)-1 && math.Abs(p[zeros]) < eps {
+		zeros++
+	}
+	info, err := p[zeros:].rootInfoNonZero()
+	if err != nil {
+		return nil, err
+	}
+	if zeros > 0 {
+		info = append(info, RootInfo{Root: 0, Multiplicity: zeros})
+	}
+	return info, nil
+}
+
+func (p Polynomial) rootInfoNonZero() ([]RootInfo, error) {
+	n := p.Degree()
+	if n <= 2 {
+		r, err := p.rootUpToThree()
+		if err != nil {
+			return nil, err
+		}
+		if len(r.roots) == 2 && r.roots[0] == r.roots[1] {
+			return []RootInfo{{Root: r.roots[0], Multiplicity: 2, Error: p.rootError(r.roots[0], 2)}}, nil
+		}
+		var info []RootInfo
+		for _, z := range r.roots {
+			info = append(info, RootInfo{Root: z, Multiplicity: 1, Error: p.rootError(z, 1)})
+		}
+		return info, nil
+	}
+
+	z, err := p.companionEigenvalues()
+	if err != nil {
+		return nil, err
+	}
+
+	all := p.resolveClusters(z, 1)
+	var info []RootInfo
+	for _, ri := range all {
+		if math.Abs(imag(ri.Root)) < 1e-7 {
+			ri.Root = complex(real(ri.Root), 0)
+			info = append(info, ri)
+		} else if imag(ri.Root) > 0 {
+			info = append(info, ri)
+		}
+	}
+	return info, nil
+}
+
+// rootsEigen calculates the roots as the eigenvalues of the companion matrix
+func (p Polynomial) rootsEigen() (Roots, error) {
+	info, err := p.RootInfo()
+	if err != nil {
+		return Roots{}, err
+	}
+	var roots []complex128
+	for _, ri := range info {
+		for range ri.Multiplicity {
+			roots = append(roots, ri.Root)
+		}
+	}
+	return Roots{roots: roots, factor: p[len(p)-1]}, nil
+}
+
+// companionEigenvalues returns all roots of the polynomial as the eigenvalues of the companion matrix
+func (p Polynomial) companionEigenvalues() ([]complex128, error) {
+	n := p.Degree()
+	a := NewMatrix(n, n)
+	for j := 0; j < n; j++ {
+		a[0][j] = -p[n-1-j] / p[n]
+	}
+	for i := 1; i < n; i++ {
+		a[i][i-1] = 1
+	}
+	a.balance()
+	return a.hessenbergEigenvalues()
+}
+
+// resolveClusters groups the roots to clusters. Two roots belong to the same cluster
+// if their distance is smaller than the scaled sum of their error estimates.
+// If a cluster is not a multiple root, it is split by a smaller scale.
+func (p Polynomial) resolveClusters(z []complex128, scale float64) []RootInfo {
+	n := len(z)
+	e := make([]float64, n)
+	for i, r := range z {
+		e[i] = p.rootError(r, 1)
+	}
+	cluster := make([]int, n)
+	for i := range cluster {
+		cluster[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if cluster[i] != i {
+			cluster[i] = find(cluster[i])
+		}
+		return cluster[i]
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if cmplx.Abs(z[i]-z[j]) <= 2*scale*(e[i]+e[j]) {
+				cluster[find(j)] = find(i)
+			}
+		}
+	}
+	members := map[int][]complex128{}
+	var order []int
+	for i := 0; i < n; i++ {
+		c := find(i)
+		if _, ok := members[c]; !ok {
+			order = append(order, c)
+		}
+		members[c] = append(members[c], z[i])
+	}
+
+	var info []RootInfo
+	for _, c := range order {
+		m := members[c]
+		if len(m) > 1 {
+			if ri, ok := p.multipleRoot(m); ok {
+				info = append(info, ri)
+				continue
+			}
+			if scale > 1e-6 {
+				info = append(info, p.resolveClusters(m, scale/4)...)
+				continue
+			}
+		}
+		for _, r := range m {
+			r = p.polish(r)
+			info = append(info, RootInfo{Root: r, Multiplicity: 1, Error: p.rootError(r, 1)})
+		}
+	}
+	return info
+}
+
+// multipleRoot checks if the cluster of roots belongs to a root with a multiplicity
+// equal to the size of the cluster. If so, the mean value of the cluster is refined
+// by the newton method applied to the derivative of order m-1, which has a simple
+// root at this position.
+func (p Polynomial) multipleRoot(cluster []complex128) (RootInfo, bool) {
+	m := len(cluster)
+	mean := complex(0, 0)
+	for _, c := range cluster {
+		mean += c
+	}
+	mean /= complex(float64(m), 0)
+
+	derivs := []Polynomial{p}
+	for k := 1; k <= m; k++ {
+		derivs = append(derivs, derivs[k-1].Derivative())
+	}
+	mean = derivs[m-1].polish(mean)
+
+	x := cmplx.Abs(mean)
+	for k := 0; k < m; k++ {
+		if cmplx.Abs(derivs[k].EvalCplx(mean)) > multiplicityTolerance*derivs[k].absEval(x) {
+			return RootInfo{}, false
+		}
+	}
+	return RootInfo{Root: mean, Multiplicity: m, Error: p.rootError(mean, m)}, true
+}
+
+// polish improves the root by the newton method as long as the residual decreases
+func (p Polynomial) polish(z complex128) complex128 {
+	d := p.Derivative()
+	f := cmplx.Abs(p.EvalCplx(z))
+	for range 10 {
+		dz := d.EvalCplx(z)
+		if dz == 0 {
+			break
+		}
+		zn := z - p.EvalCplx(z)/dz
+		fn := cmplx.Abs(p.EvalCplx(zn))
+		if !(fn < f) {
+			break
+		}
+		z = zn
+		f = fn
+	}
+	return z
+}
+
+// rootError estimates the error of a root with multiplicity m. The coefficients of the
+// polynomial are assumed to be perturbed by the rounding errors. The residual is also
+// taken into account.
+func (p Polynomial) rootError(z complex128, m int) float64 {
+	d := p
+	fact := 1.0
+	for k := 1; k <= m; k++ {
+		d = d.Derivative()
+		fact *= float64(k)
+	}
+	dm := cmplx.Abs(d.EvalCplx(z))
+	if dm == 0 {
+		return math.Inf(1)
+	}
+	res := cmplx.Abs(p.EvalCplx(z)) + epsMachine*p.absEval(cmplx.Abs(z))
+	return math.Pow(fact*res/dm, 1/float64(m))
+}
+
+// epsMachine is the machine precision of float64
+const epsMachine = 2.220446049250313e-16
+
+// absEval evaluates the polynomial with the absolute values of the coefficients
+func (p Polynomial) absEval(x float64) float64 {
+	r := 0.0
+	for i := len(p) - 1; i >= 0; i-- {
+		r = r*x + math.Abs(p[i])
+	}
+	return r
+}
+
+// balance balances the matrix by a diagonal similarity transformation
+// to reduce the rounding errors of the eigenvalue calculation.
+// The hessenberg form of the matrix is preserved.
+func (m Matrix) balance() {
+	const radix = 2.0
+	n := m.Rows()
+	done := false
+	for !done {
+		done = true
+		for i := 0; i < n; i++ {
+			r, c := 0.0, 0.0
+			for j := 0; j < n; j++ {
+				if j != i {
+					c += math.Abs(m[j][i])
+					r += math.Abs(m[i][j])
+				}
+			}
+			if c == 0 || r == 0 {
+				continue
+			}
+			g := r / radix
+			f := 1.0
+			s := c + r
+			for c < g {
+				f *= radix
+				c *= radix * radix
+			}
+			g = r * radix
+			for c > g {
+				f /= radix
+				c /= radix * radix
+			}
+			if (c+r)/f < 0.95*s {
+				done = false
+				for j := 0; j < n; j++ {
+					m[i][j] /= f
+					m[j][i] *= f
+				}
+			}
+		}
+	}
+}
+
+// hessenbergEigenvalues calculates the eigenvalues of an upper hessenberg matrix
+// using the Francis double shift QR algorithm. The matrix is destroyed.
+func (m Matrix) hessenbergEigenvalues() ([]complex128, error) {
+	a := m
+	n := a.Rows()
+	ev := make([]complex128, n)
+	norm := 0.0
+	for i := 0; i < n; i++ {
+		for j := max(i-1, 0); j < n; j++ {
+			norm += math.Abs(a[i][j])
+		}
+	}
+	sign := func(a, b float64) float64 {
+		if b >= 0 {
+			return math.Abs(a)
+		}
+		return -math.Abs(a)
+	}
+
+	nn := n - 1
+	shift := 0.0
+	for nn >= 0 {
+		its := 0
+		for {
+			// look for a single small sub-diagonal element
+			l := nn
+			for ; l >= 1; l-- {
+				s := math.Abs(a[l-1][l-1]) + math.Abs(a[l][l])
+				if s == 0 {
+					s = norm
+				}
+				if math.Abs(a[l][l-1])+s == s {
+					a[l][l-1] = 0
+					break
+				}
+			}
+			x := a[nn][nn]
+			if l == nn {
+				// one root found
+				ev[nn] = complex(x+shift, 0)
+				nn--
+				break
+			}
+			y := a[nn-1][nn-1]
+			w := a[nn][nn-1] * a[nn-1][nn]
+			if l == nn-1 {
+				// two roots found
+				p := 0.5 * (y - x)
+				q := p*p + w
+				z := math.Sqrt(math.Abs(q))
+				x += shift
+				if q >= 0 {
+					z = p + sign(z, p)
+					r1 := x + z
+					r2 := r1
+					if z != 0 {
+						r2 = x - w/z
+					}
+					ev[nn-1] = complex(r1, 0)
+					ev[nn] = complex(r2, 0)
+				} else {
+					ev[nn-1] = complex(x+p, -z)
+					ev[nn] = complex(x+p, z)
+				}
+				nn -= 2
+				break
+			}
+
+			if its == 60 {
+				return nil, errors.New("the QR algorithm does not converge")
+			}
+			if its == 10 || its == 20 {
+				// exceptional shift
+				shift += x
+				for i := 0; i <= nn; i++ {
+					a[i][i] -= x
+				}
+				s := math.Abs(a[nn][nn-1]) + math.Abs(a[nn-1][nn-2])
+				x = 0.75 * s
+				y = x
+				w = -0.4375 * s * s
+			}
+			its++
+
+			// look for two consecutive small sub-diagonal elements
+			var p, q, r, z float64
+			mm := nn - 2
+			for ; mm >= l; mm-- {
+				z = a[mm][mm]
+				r = x - z
+				s := y - z
+				p = (r*s-w)/a[mm+1][mm] + a[mm][mm+1]
+				q = a[mm+1][mm+1] - z - r - s
+				r = a[mm+2][mm+1]
+				s = math.Abs(p) + math.Abs(q) + math.Abs(r)
+				p /= s
+				q /= s
+				r /= s
+				if mm == l {
+					break
+				}
+				u := math.Abs(a[mm][mm-1]) * (math.Abs(q) + math.Abs(r))
+				v := math.Abs(p) * (math.Abs(a[mm-1][mm-1]) + math.Abs(z) + math.Abs(a[mm+1][mm+1]))
+				if u+v == v {
+					break
+				}
+			}
+			for i := mm + 2; i <= nn; i++ {
+				a[i][i-2] = 0
+				if i != mm+2 {
+					a[i][i-3] = 0
+				}
+			}
+
+			// double QR step on rows l to nn and columns mm to nn
+			for k := mm; k <= nn-1; k++ {
+				if k != mm {
+					p = a[k][k-1]
+					q = a[k+1][k-1]
+					r = 0
+					if k != nn-1 {
+						r = a[k+2][k-1]
+					}
+					x = math.Abs(p) + math.Abs(q) + math.Abs(r)
+					if x != 0 {
+						p /= x
+						q /= x
+						r /= x
+					}
+				}
+				s := sign(math.Sqrt(p*p+q*q+r*r), p)
+				if s == 0 {
+					continue
+				}
+				if k == mm {
+					if l != mm {
+						a[k][k-1] = -a[k][k-1]
+					}
+				} else {
+					a[k][k-1] = -s * x
+				}
+				p += s
+				x = p / s
+				y = q / s
+				z = r / s
+				q /= p
+				r /= p
+				for j := k; j <= nn; j++ {
+					p = a[k][j] + q*a[k+1][j]
+					if k != nn-1 {
+						p += r * a[k+2][j]
+						a[k+2][j] -= p * z
+					}
+					a[k+1][j] -= p * y
+					a[k][j] -= p * x
+				}
+				mmin := min(nn, k+3)
+				for i := l; i <= mmin; i++ {
+					p = x*a[i][k] + y*a[i][k+1]
+					if k != nn-1 {
+						p += z * a[i][k+2]
+						a[i][k+2] -= p * r
+					}
+					a[i][k+1] -= p * q
+					a[i][k] -= p
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(ev, func(i, j int) bool {
+		return cmplx.Abs(ev[i]) < cmplx.Abs(ev[j])
+	})
+	return ev, nil
+}
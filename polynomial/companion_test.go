@@ -0,0 +1,105 @@
+package polynomial
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/cmplx"
+	"sort"
+	"testing"
+)
+
+func TestPolynomial_RootInfo(t *testing.T) {
+	type root struct {
+		z complex128
+		m int
+	}
+	tests := []struct {
+		name string
+		p    Polynomial
+		want []root
+	}{
+		{"simple", Polynomial{6, 11, 6, 1}, []root{{-3, 1}, {-2, 1}, {-1, 1}}},
+		{"zeros", Polynomial{0, 0, 2, 1}, []root{{-2, 1}, {0, 2}}},
+		{"double", Polynomial{1, 2, 1}, []root{{-1, 2}}},
+		{"triple", Polynomial{0.1, 1}.Pow(3), []root{{-0.1, 3}}},
+		{"tenfold", Polynomial{1, 1}.Pow(10), []root{{-1, 10}}},
+		{"mixed", Polynomial{1, 1}.Pow(5).Mul(Polynomial{2, 1}.Pow(3)).Mul(Polynomial{10, 1}), []root{{-10, 1}, {-2, 3}, {-1, 5}}},
+		{"complex double", Polynomial{2, 2, 1}.Pow(2).Mul(Polynomial{3, 1}), []root{{-3, 1}, {complex(-1, 1), 2}}},
+		{"close", Polynomial{1, 1}.Mul(Polynomial{1.0001, 1}).Mul(Polynomial{3, 1}), []root{{-3, 1}, {-1.0001, 1}, {-1, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := tt.p.RootInfo()
+			assert.NoError(t, err)
+			sort.Slice(info, func(i, j int) bool {
+				return real(info[i].Root) < real(info[j].Root)
+			})
+			assert.Len(t, info, len(tt.want))
+			for i, w := range tt.want {
+				assert.InDelta(t, 0, cmplx.Abs(w.z-info[i].Root), 1e-8, info[i].Root)
+				assert.Equal(t, w.m, info[i].Multiplicity)
+				assert.True(t, info[i].Error < 0.1, info[i].Error)
+			}
+		})
+	}
+}
+
+func TestPolynomial_RootsHighDegree(t *testing.T) {
+	// poles of a 30th order butterworth filter
+	p := Polynomial{1}
+	var want []complex128
+	for k := 0; k < 15; k++ {
+		z := cmplx.Rect(1, math.Pi/2+math.Pi*float64(2*k+1)/60)
+		want = append(want, z)
+		p = p.Mul(FromRoot(z))
+	}
+	r, err := p.Roots()
+	assert.NoError(t, err)
+	assert.Equal(t, 30, r.Count())
+	info, err := p.RootInfo()
+	assert.NoError(t, err)
+	assert.Len(t, info, 15)
+	for _, ri := range info {
+		dist := math.Inf(1)
+		for _, w := range want {
+			dist = math.Min(dist, cmplx.Abs(ri.Root-w))
+		}
+		assert.LessOrEqual(t, dist, ri.Error, ri.Root)
+		assert.Less(t, ri.Error, 0.01, ri.Root)
+	}
+
+	// a plant with 20 real poles
+	p = Polynomial{1}
+	for i := 1; i <= 20; i++ {
+		p = p.Mul(Polynomial{float64(i) / 4, 1})
+	}
+	info, err = p.RootInfo()
+	assert.NoError(t, err)
+	assert.Len(t, info, 20)
+	for _, ri := range info {
+		assert.Equal(t, 0.0, imag(ri.Root))
+		nearest := math.Round(-real(ri.Root)*4) / 4
+		assert.InDelta(t, nearest, -real(ri.Root), math.Max(ri.Error, 1e-10))
+	}
+}
+
+func TestMatrix_HessenbergEigenvalues(t *testing.T) {
+	m := Matrix{
+		{1, 2, 3, 4},
+		{-1, 0, 2, 1},
+		{0, 3, -2, 1},
+		{0, 0, 1, -1},
+	}
+	ev, err := m.Copy().hessenbergEigenvalues()
+	assert.NoError(t, err)
+	cp, err := m.CharPoly()
+	assert.NoError(t, err)
+	for _, e := range ev {
+		assert.InDelta(t, 0, cmplx.Abs(cp.EvalCplx(e)), 1e-9)
+	}
+	trace := complex(0, 0)
+	for _, e := range ev {
+		trace += e
+	}
+	assert.InDelta(t, m.Trace(), real(trace), 1e-12)
+}
@@ -0,0 +1,48 @@
+package polynomial
+
+import "errors"
+
+// ControlLoop contains the closed loop transfer functions of the standard
+// control loop with the plant G, the controller K and the sensor H.
+// The disturbance d_i acts on the plant input, the disturbance d_o on the
+// plant output and the noise n is added to the measured value.
+type ControlLoop struct {
+	// L is the loop gain K*G*H
+	L *Linear
+	// S is the sensitivity 1/(1+L), the transfer function from d_o to y
+	S *Linear
+	// T is the complementary sensitivity L/(1+L)
+	T *Linear
+	// GS is the transfer function G/(1+L) from d_i to y
+	GS *Linear
+	// KS is the transfer function K/(1+L) from r to u
+	KS *Linear
+	// RY is the transfer function K*G/(1+L) from r to y
+	RY *Linear
+	// NY is the transfer function -K*G/(1+L) from n to y
+	NY *Linear
+}
+
+// NewControlLoop creates the closed loop transfer functions. All of them
+// share the characteristic polynomial of the loop as denominator.
+func NewControlLoop(g, k, h *Linear) (*ControlLoop, error) {
+	if g.Delay != 0 || k.Delay != 0 || h.Delay != 0 {
+		return nil, errDeadTimeLoop
+	}
+	d := g.Denominator.Mul(k.Denominator).Mul(h.Denominator)
+	n := g.Numerator.Mul(k.Numerator).Mul(h.Numerator)
+	c := d.Add(n).Canonical()
+	if c.IsZero() {
+		return nil, errors.New("the feedback loop is not well-posed")
+	}
+	ry := g.Numerator.Mul(k.Numerator).Mul(h.Denominator)
+	return &ControlLoop{
+		L:  k.Mul(g).Mul(h),
+		S:  &Linear{Numerator: d, Denominator: c},
+		T:  &Linear{Numerator: n, Denominator: c},
+		GS: &Linear{Numerator: g.Numerator.Mul(k.Denominator).Mul(h.Denominator), Denominator: c},
+		KS: &Linear{Numerator: k.Numerator.Mul(g.Denominator).Mul(h.Denominator), Denominator: c},
+		RY: &Linear{Numerator: ry, Denominator: c},
+		NY: &Linear{Numerator: ry.MulFloat(-1), Denominator: c},
+	}, nil
+}
@@ -0,0 +1,69 @@
+package polynomial
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/cmplx"
+	"testing"
+)
+
+func TestLinear_Feedback(t *testing.T) {
+	g := &Linear{Numerator: Polynomial{2}, Denominator: Polynomial{1, 1}}
+	h := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 0.1}}
+
+	// 2(1+0.1s)/((1+s)(1+0.1s)+2)
+	fb, err := g.Feedback(h, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, Polynomial{2, 0.2}, fb.Numerator)
+	assert.Equal(t, Polynomial{3, 1.1, 0.1}, fb.Denominator)
+
+	fb, err = g.Feedback(h, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, Polynomial{-1, 1.1, 0.1}, fb.Denominator)
+
+	// unity feedback equals loop
+	loop, err := g.Loop()
+	assert.NoError(t, err)
+	fb, err = g.Feedback(NewConst(1), -1)
+	assert.NoError(t, err)
+	assert.True(t, loop.Equals(fb))
+
+	_, err = NewConst(1).Feedback(NewConst(1), 1)
+	assert.Error(t, err)
+	_, err = (&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}, Delay: 1}).Feedback(h, -1)
+	assert.Equal(t, errDeadTimeLoop, err)
+	_, err = g.Feedback(h, 0)
+	assert.Equal(t, errFeedbackSign, err)
+}
+
+func TestNewControlLoop(t *testing.T) {
+	g := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 3, 2}}
+	k, err := PID(2, 3, 0, 0)
+	assert.NoError(t, err)
+	h := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 0.05}}
+	cl, err := NewControlLoop(g, k, h)
+	assert.NoError(t, err)
+
+	fb, err := k.Mul(g).Feedback(h, -1)
+	assert.NoError(t, err)
+	for _, s := range []complex128{complex(0.1, 0.2), complex(0, 1), complex(-2, 3)} {
+		l := k.EvalCplx(s) * g.EvalCplx(s) * h.EvalCplx(s)
+		want := map[string]complex128{
+			"L":  l,
+			"S":  1 / (1 + l),
+			"T":  l / (1 + l),
+			"GS": g.EvalCplx(s) / (1 + l),
+			"KS": k.EvalCplx(s) / (1 + l),
+			"RY": fb.EvalCplx(s),
+			"NY": -fb.EvalCplx(s),
+		}
+		got := map[string]*Linear{"L": cl.L, "S": cl.S, "T": cl.T, "GS": cl.GS, "KS": cl.KS, "RY": cl.RY, "NY": cl.NY}
+		for n, w := range want {
+			assert.InDelta(t, 0, cmplx.Abs(w-got[n].EvalCplx(s)), 1e-9*(1+cmplx.Abs(w)), "%s at %v", n, s)
+		}
+	}
+
+	// the integral action rejects constant disturbances
+	assert.InDelta(t, 0, cl.S.DCGain(), 1e-12)
+	assert.InDelta(t, 0, cl.GS.DCGain(), 1e-12)
+	assert.InDelta(t, 1, cl.RY.DCGain(), 1e-12)
+}
@@ -0,0 +1,583 @@
+package polynomial
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/hneemann/control/graph"
+	"github.com/hneemann/parser2/funcGen"
+	"github.com/hneemann/parser2/value"
+	"github.com/hneemann/parser2/value/export"
+	"github.com/hneemann/parser2/value/export/xmlWriter"
+	"math"
+	"math/cmplx"
+	"strconv"
+)
+
+// Discrete is a discrete time linear system given by its transfer function in z.
+// T is the sample time.
+type Discrete struct {
+	Numerator   Polynomial
+	Denominator Polynomial
+	T           float64
+}
+
+var _ export.ToHtmlInterface = &Discrete{}
+
+// NewDiscrete creates a new discrete system with the sample time T
+func NewDiscrete(num, den Polynomial, T float64) (*Discrete, error) {
+	if T <= 0 {
+		return nil, errors.New("the sample time needs to be greater than zero")
+	}
+	if den.IsZero() {
+		return nil, errors.New("the denominator must not be zero")
+	}
+	return &Discrete{Numerator: num.Canonical(), Denominator: den.Canonical(), T: T}, nil
+}
+
+func discreteFromLinear(l *Linear, T float64) *Discrete {
+	num := l.Numerator.Canonical()
+	den, f := l.Denominator.Normalize()
+	return &Discrete{Numerator: num.MulFloat(1 / f), Denominator: den, T: T}
+}
+
+// linear returns the transfer function in z as a linear system
+func (d *Discrete) linear() *Linear {
+	return &Linear{Numerator: d.Numerator, Denominator: d.Denominator}
+}
+
+// DiscretizationMethod selects the method used to convert between
+// continuous and discrete time systems
+type DiscretizationMethod int
+
+const (
+	ZOH DiscretizationMethod = iota
+	Tustin
+	Matched
+)
+
+func (m DiscretizationMethod) String() string {
+	switch m {
+	case Tustin:
+		return "tustin"
+	case Matched:
+		return "matched"
+	default:
+		return "zoh"
+	}
+}
+
+// DiscretizationByName returns the discretization method with the given name
+func DiscretizationByName(name string) (DiscretizationMethod, error) {
+	switch name {
+	case "zoh":
+		return ZOH, nil
+	case "tustin":
+		return Tustin, nil
+	case "matched":
+		return Matched, nil
+	default:
+		return ZOH, fmt.Errorf("unknown method '%s', allowed are 'zoh', 'tustin' and 'matched'", name)
+	}
+}
+
+// C2D converts the continuous system to a discrete system with the sample time T
+func (l *Linear) C2D(T float64, method DiscretizationMethod) (*Discrete, error) {
+	if T <= 0 {
+		return nil, errors.New("the sample time needs to be greater than zero")
+	}
+	if !l.IsCausal() {
+		return nil, errors.New("the system is not causal")
+	}
+	if l.Delay != 0 {
+		// a dead time which is a multiple of the sample time becomes z^{-k}
+		k := math.Round(l.Delay / T)
+		if math.Abs(k*T-l.Delay) > eps*T {
+			return nil, fmt.Errorf("the dead time %g is not a multiple of the sample time %g", l.Delay, T)
+		}
+		rational := *l
+		rational.Delay = 0
+		d, err := rational.C2D(T, method)
+		if err != nil {
+			return nil, err
+		}
+		d.Denominator = d.Denominator.Mul(Polynomial{0, 1}.Pow(int(k)))
+		return d, nil
+	}
+	switch method {
+	case Tustin:
+		return l.c2dTustin(T), nil
+	case Matched:
+		return l.c2dMatched(T)
+	default:
+		return l.c2dZOH(T)
+	}
+}
+
+func (l *Linear) c2dZOH(T float64) (*Discrete, error) {
+	ss, err := l.reduce().StateSpace()
+	if err != nil {
+		return nil, err
+	}
+	n := ss.Order()
+	if n == 0 {
+		return NewDiscrete(Polynomial{ss.D[0][0]}, Polynomial{1}, T)
+	}
+
+	// exp([[A,B],[0,0]]T) = [[Ad,Bd],[0,1]]
+	m, err := Stack([][]Matrix{{ss.A, ss.B}, {NewMatrix(1, n+1)}})
+	if err != nil {
+		return nil, err
+	}
+	e, err := m.MulFloat(T).Exp()
+	if err != nil {
+		return nil, err
+	}
+	ad := NewMatrix(n, n)
+	bd := NewMatrix(n, 1)
+	for i := 0; i < n; i++ {
+		copy(ad[i], e[i][:n])
+		bd[i][0] = e[i][n]
+	}
+	lin, err := (&StateSpace{A: ad, B: bd, C: ss.C, D: ss.D}).Linear()
+	if err != nil {
+		return nil, err
+	}
+	return discreteFromLinear(lin, T), nil
+}
+
+// bilinear substitutes the variable of the polynomial p by (a1*x+a0)/(b1*x+b0)
+// and multiplies the result by (b1*x+b0)^n
+func bilinear(p Polynomial, n int, a, b Polynomial) Polynomial {
+	res := Polynomial{0}
+	for k, c := range p {
+		if c != 0 {
+			res = res.Add(a.Pow(k).Mul(b.Pow(n - k)).MulFloat(c))
+		}
+	}
+	return res
+}
+
+func (l *Linear) c2dTustin(T float64) *Discrete {
+	// s = 2/T (z-1)/(z+1)
+	n := l.Denominator.Canonical().Degree()
+	a := Polynomial{-2 / T, 2 / T}
+	b := Polynomial{1, 1}
+	return discreteFromLinear(&Linear{
+		Numerator:   bilinear(l.Numerator.Canonical(), n, a, b),
+		Denominator: bilinear(l.Denominator.Canonical(), n, a, b),
+	}, T)
+}
+
+// rootFactor returns the real polynomial which has the root r.
+// If complex is true, the conjugate root is also a root of the returned polynomial.
+func rootFactor(r complex128, complex bool) Polynomial {
+	if complex {
+		return Polynomial{real(r)*real(r) + imag(r)*imag(r), -2 * real(r), 1}
+	}
+	return Polynomial{-real(r), 1}
+}
+
+// matchGain returns the factor k which makes k*b have the same gain as a.
+// The gain is compared at the given points, the first point which
+// gives finite values is used.
+func matchGain(a, b func(float64) complex128, points ...float64) float64 {
+	for _, w := range points {
+		ca := a(w)
+		cb := b(w)
+		if cmplx.IsInf(ca) || cmplx.IsNaN(ca) || cmplx.Abs(ca) < eps || cmplx.Abs(cb) < eps {
+			continue
+		}
+		if w == 0 {
+			return real(ca) / real(cb)
+		}
+		return cmplx.Abs(ca) / cmplx.Abs(cb)
+	}
+	return 1
+}
+
+func (l *Linear) c2dMatched(T float64) (*Discrete, error) {
+	poles, err := l.Poles()
+	if err != nil {
+		return nil, err
+	}
+	zeros, err := l.Zeros()
+	if err != nil {
+		return nil, err
+	}
+
+	den := Polynomial{1}
+	for _, p := range poles.roots {
+		den = den.Mul(rootFactor(cmplx.Exp(p*complex(T, 0)), imag(p) != 0))
+	}
+	num := Polynomial{1}
+	for _, z := range zeros.roots {
+		num = num.Mul(rootFactor(cmplx.Exp(z*complex(T, 0)), imag(z) != 0))
+	}
+	// zeros at infinity are mapped to z=-1, one delay is kept
+	for i := num.Degree() + 1; i < den.Degree(); i++ {
+		num = num.Mul(Polynomial{1, 1})
+	}
+
+	d := &Discrete{Numerator: num, Denominator: den, T: T}
+	k := matchGain(l.FrequencyResponse, d.FrequencyResponse, 0, 0.01/T, 0.1/T)
+	d.Numerator = num.MulFloat(k)
+	return d, nil
+}
+
+// D2C converts the discrete system to a continuous system
+func (d *Discrete) D2C(method DiscretizationMethod) (*Linear, error) {
+	if !d.IsCausal() {
+		return nil, errors.New("the system is not causal")
+	}
+	switch method {
+	case Tustin:
+		// z = (1+sT/2)/(1-sT/2)
+		n := d.Denominator.Degree()
+		a := Polynomial{1, d.T / 2}
+		b := Polynomial{1, -d.T / 2}
+		return (&Linear{
+			Numerator:   bilinear(d.Numerator, n, a, b),
+			Denominator: bilinear(d.Denominator, n, a, b),
+		}).Normalize()
+	case Matched:
+		return d.d2cMatched()
+	default:
+		return d.d2cZOH()
+	}
+}
+
+func (d *Discrete) d2cZOH() (*Linear, error) {
+	ss, err := d.linear().StateSpace()
+	if err != nil {
+		return nil, err
+	}
+	n := ss.Order()
+	if n == 0 {
+		return NewConst(ss.D[0][0]), nil
+	}
+
+	// log([[Ad,Bd],[0,1]])/T = [[A,B],[0,0]]
+	bottom := NewMatrix(1, n+1)
+	bottom[0][n] = 1
+	m, err := Stack([][]Matrix{{ss.A, ss.B}, {bottom}})
+	if err != nil {
+		return nil, err
+	}
+	lg, err := m.Log()
+	if err != nil {
+		return nil, fmt.Errorf("no continuous system found: %w", err)
+	}
+	lg = lg.MulFloat(1 / d.T)
+	a := NewMatrix(n, n)
+	b := NewMatrix(n, 1)
+	for i := 0; i < n; i++ {
+		copy(a[i], lg[i][:n])
+		b[i][0] = lg[i][n]
+	}
+	lin, err := (&StateSpace{A: a, B: b, C: ss.C, D: ss.D}).Linear()
+	if err != nil {
+		return nil, err
+	}
+	return lin.Normalize()
+}
+
+func (d *Discrete) d2cMatched() (*Linear, error) {
+	poles, err := d.Poles()
+	if err != nil {
+		return nil, err
+	}
+	zeros, err := d.Zeros()
+	if err != nil {
+		return nil, err
+	}
+	toS := func(z complex128) (complex128, error) {
+		if cmplx.Abs(z) < eps || (imag(z) == 0 && real(z) < 0) {
+			return 0, fmt.Errorf("the root %v has no continuous counterpart", z)
+		}
+		return cmplx.Log(z) / complex(d.T, 0), nil
+	}
+
+	den := Polynomial{1}
+	for _, p := range poles.roots {
+		s, err := toS(p)
+		if err != nil {
+			return nil, err
+		}
+		den = den.Mul(rootFactor(s, imag(p) != 0))
+	}
+	num := Polynomial{1}
+	for _, z := range zeros.roots {
+		if cmplx.Abs(z+1) < 1e-9 {
+			// zeros at z=-1 are zeros at infinity
+			continue
+		}
+		s, err := toS(z)
+		if err != nil {
+			return nil, err
+		}
+		num = num.Mul(rootFactor(s, imag(z) != 0))
+	}
+	l := &Linear{Numerator: num, Denominator: den}
+	k := matchGain(d.FrequencyResponse, l.FrequencyResponse, 0, 0.01/d.T, 0.1/d.T)
+	l.Numerator = num.MulFloat(k)
+	return l, nil
+}
+
+func (d *Discrete) EvalCplx(z complex128) complex128 {
+	return d.Numerator.EvalCplx(z) / d.Denominator.EvalCplx(z)
+}
+
+// FrequencyResponse returns the response at the angular frequency ω which is G(e^{jωT})
+func (d *Discrete) FrequencyResponse(w float64) complex128 {
+	return d.EvalCplx(cmplx.Rect(1, w*d.T))
+}
+
+func (d *Discrete) IsCausal() bool {
+	return d.Numerator.Degree() <= d.Denominator.Degree()
+}
+
+func (d *Discrete) Poles() (Roots, error) {
+	return d.Denominator.Roots()
+}
+
+func (d *Discrete) Zeros() (Roots, error) {
+	return d.Numerator.Roots()
+}
+
+// IsStable returns true if all poles are inside the unit circle
+func (d *Discrete) IsStable() (bool, error) {
+	poles, err := d.Poles()
+	if err != nil {
+		return false, err
+	}
+	for _, p := range poles.roots {
+		if cmplx.Abs(p) >= 1 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (d *Discrete) checkT(o *Discrete) error {
+	if math.Abs(d.T-o.T) > 1e-12*d.T {
+		return fmt.Errorf("the sample times %v and %v do not match", d.T, o.T)
+	}
+	return nil
+}
+
+func (d *Discrete) Mul(o *Discrete) (*Discrete, error) {
+	if err := d.checkT(o); err != nil {
+		return nil, err
+	}
+	return &Discrete{Numerator: d.Numerator.Mul(o.Numerator), Denominator: d.Denominator.Mul(o.Denominator), T: d.T}, nil
+}
+
+func (d *Discrete) Add(o *Discrete) (*Discrete, error) {
+	if err := d.checkT(o); err != nil {
+		return nil, err
+	}
+	l, err := d.linear().Add(o.linear())
+	if err != nil {
+		return nil, err
+	}
+	return &Discrete{Numerator: l.Numerator, Denominator: l.Denominator, T: d.T}, nil
+}
+
+func (d *Discrete) MulFloat(f float64) *Discrete {
+	return &Discrete{Numerator: d.Numerator.MulFloat(f), Denominator: d.Denominator, T: d.T}
+}
+
+// Loop closes the loop with a negative unity feedback
+func (d *Discrete) Loop() *Discrete {
+	return &Discrete{Numerator: d.Numerator, Denominator: d.Numerator.Add(d.Denominator), T: d.T}
+}
+
+// Simulate calculates the response to the input signal u by evaluating the difference equation.
+// Returns one point per sample.
+func (d *Discrete) Simulate(tMax float64, u func(float64) (float64, error)) (*value.List, error) {
+	if !d.IsCausal() {
+		return nil, errors.New("the system is not causal")
+	}
+	if tMax <= 0 {
+		return nil, errors.New("tMax must be greater than 0")
+	}
+	samples := int(tMax/d.T+1e-9) + 1
+	if samples > 1000000 {
+		return nil, errors.New("too many samples")
+	}
+
+	// a_n y[k] = sum b_i u[k-n+i] - sum_{i<n} a_i y[k-n+i]
+	a := d.Denominator
+	b := d.Numerator
+	n := a.Degree()
+	uk := make([]float64, samples)
+	yk := make([]float64, samples)
+	points := make([]value.Value, samples)
+	for k := range samples {
+		t := float64(k) * d.T
+		var err error
+		uk[k], err = u(t)
+		if err != nil {
+			return nil, err
+		}
+		sum := 0.0
+		for i, bi := range b {
+			if j := k - n + i; j >= 0 {
+				sum += bi * uk[j]
+			}
+		}
+		for i := 0; i < n; i++ {
+			if j := k - n + i; j >= 0 {
+				sum -= a[i] * yk[j]
+			}
+		}
+		yk[k] = sum / a[n]
+		points[k] = graph.Vector3d{X: t, Y: yk[k]}
+	}
+	return value.NewList(points...), nil
+}
+
+func (d *Discrete) CreateBodeContent(style *graph.Style, title string, steps int, latency float64) []value.Value {
+	return createBodeContent(d, math.Pi/d.T, style, title, steps, latency)
+}
+
+// PZMap creates a plot of the poles and zeros together with the unit circle
+func (d *Discrete) PZMap() ([]graph.ChartContent, error) {
+	poles, err := d.Poles()
+	if err != nil {
+		return nil, err
+	}
+	zeros, err := d.Zeros()
+	if err != nil {
+		return nil, err
+	}
+	circle, err := graph.NewLinearParameterFunc(0, 2*math.Pi, 100)
+	if err != nil {
+		return nil, err
+	}
+	circle.Func = func(phi float64) (graph.Point, error) {
+		return graph.Point{X: math.Cos(phi), Y: math.Sin(phi)}, nil
+	}
+	circle.Style = graph.Gray
+	cp := []graph.ChartContent{circle, graph.Cross{Style: graph.Gray}}
+
+	markerStyle := graph.Black.SetStrokeWidth(2)
+	if poles.Count() > 0 {
+		cp = append(cp, graph.Scatter{
+			Points:         graph.PointsFromSlice(poles.ToPoints()...),
+			ShapeLineStyle: graph.ShapeLineStyle{Shape: graph.NewCrossMarker(4), ShapeStyle: markerStyle},
+			Title:          "Poles",
+		})
+	}
+	if zeros.Count() > 0 {
+		cp = append(cp, graph.Scatter{
+			Points:         graph.PointsFromSlice(zeros.ToPoints()...),
+			ShapeLineStyle: graph.ShapeLineStyle{Shape: graph.NewCircleMarker(4), ShapeStyle: markerStyle},
+			Title:          "Zeros",
+		})
+	}
+	return cp, nil
+}
+
+// Jury checks by the Jury stability criterion whether all roots of the polynomial
+// are inside the unit circle. Also the Jury table is returned. Every row contains
+// the coefficients starting with the constant term followed by the reversed row.
+func (p Polynomial) Jury() (bool, [][]float64, error) {
+	row := p.Canonical()
+	if row.Degree() < 1 {
+		return false, nil, errors.New("the polynomial needs to have at least degree one")
+	}
+	stable := true
+	var table [][]float64
+	for len(row) > 1 {
+		rev := make([]float64, len(row))
+		for i, v := range row {
+			rev[len(row)-1-i] = v
+		}
+		table = append(table, append([]float64{}, row...), rev)
+
+		m := len(row) - 1
+		if math.Abs(row[0]) >= math.Abs(row[m]) {
+			stable = false
+		}
+		next := make([]float64, m)
+		for k := range next {
+			next[k] = row[m]*row[k+1] - row[0]*row[m-1-k]
+		}
+		row = next
+	}
+	return stable, table, nil
+}
+
+func (d *Discrete) String() string {
+	var s string
+	if d.Denominator.IsOne() {
+		s = d.Numerator.stringVar("z")
+	} else {
+		n := d.Numerator.stringVar("z")
+		if d.Numerator.IsSum() {
+			n = "(" + n + ")"
+		}
+		s = fmt.Sprintf("%s/(%s)", n, d.Denominator.stringVar("z"))
+	}
+	return s + ", T=" + strconv.FormatFloat(d.T, 'g', -1, 64)
+}
+
+func (d *Discrete) ToLaTeX(w *bytes.Buffer) {
+	w.WriteString("\\frac{")
+	d.Numerator.toLaTeXVar(w, "z")
+	w.WriteString("}{")
+	d.Denominator.toLaTeXVar(w, "z")
+	w.WriteString("}")
+}
+
+func (d *Discrete) ToHtml(_ funcGen.Stack[value.Value], w *xmlWriter.XMLWriter) error {
+	w.Open("math").
+		Attr("xmlns", "http://www.w3.org/1998/Math/MathML")
+	w.Open("mstyle").
+		Attr("displaystyle", "true").
+		Attr("scriptlevel", "0")
+	w.Open("mrow")
+	if d.Denominator.IsOne() {
+		d.Numerator.toMathMLVar(w, "z")
+	} else {
+		w.Open("mfrac")
+		d.Numerator.toMathMLVar(w, "z")
+		d.Denominator.toMathMLVar(w, "z")
+		w.Close()
+	}
+	w.Open("mo").Write(",").Close()
+	w.Open("mspace").Attr("width", "1em").Close()
+	w.Open("mi").Write("T").Close()
+	w.Open("mo").Write("=").Close()
+	export.NewFormattedFloat(d.T, 6).MathMl(w)
+	w.Close()
+	w.Close()
+	w.Close()
+	return nil
+}
+
+func (d *Discrete) ToList() (*value.List, bool) {
+	return nil, false
+}
+
+func (d *Discrete) ToMap() (value.Map, bool) {
+	return value.Map{}, false
+}
+
+func (d *Discrete) ToInt() (int, bool) {
+	return 0, false
+}
+
+func (d *Discrete) ToFloat() (float64, bool) {
+	return 0, false
+}
+
+func (d *Discrete) ToString(_ funcGen.Stack[value.Value]) (string, error) {
+	return d.String(), nil
+}
+
+func (d *Discrete) GetType() value.Type {
+	return DiscreteValueType
+}
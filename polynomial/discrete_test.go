@@ -0,0 +1,107 @@
+package polynomial
+
+import (
+	"github.com/hneemann/control/graph"
+	"github.com/hneemann/parser2/funcGen"
+	"github.com/hneemann/parser2/value"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func assertPolyEqual(t *testing.T, want, got Polynomial) {
+	t.Helper()
+	got = got.Canonical()
+	if assert.Equal(t, len(want), len(got), "%v != %v", want, got) {
+		for i := range want {
+			assert.InDelta(t, want[i], got[i], 1e-6, "%v != %v", want, got)
+		}
+	}
+}
+
+func TestLinear_C2D(t *testing.T) {
+	e := math.Exp(-0.1)
+	lin := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}
+	tests := []struct {
+		method DiscretizationMethod
+		num    Polynomial
+		den    Polynomial
+	}{
+		{ZOH, Polynomial{1 - e}, Polynomial{-e, 1}},
+		{Tustin, Polynomial{1.0 / 21, 1.0 / 21}, Polynomial{-19.0 / 21, 1}},
+		{Matched, Polynomial{1 - e}, Polynomial{-e, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method.String(), func(t *testing.T) {
+			d, err := lin.C2D(0.1, tt.method)
+			assert.NoError(t, err)
+			assertPolyEqual(t, tt.num, d.Numerator)
+			assertPolyEqual(t, tt.den, d.Denominator)
+
+			c, err := d.D2C(tt.method)
+			assert.NoError(t, err)
+			assertPolyEqual(t, lin.Numerator, c.Numerator)
+			assertPolyEqual(t, lin.Denominator, c.Denominator)
+		})
+	}
+}
+
+func TestDiscrete_RoundTrip(t *testing.T) {
+	lin := &Linear{Numerator: Polynomial{2, 1}, Denominator: Polynomial{1, 1, 1}}
+	for _, m := range []DiscretizationMethod{ZOH, Tustin, Matched} {
+		t.Run(m.String(), func(t *testing.T) {
+			d, err := lin.C2D(0.2, m)
+			assert.NoError(t, err)
+			c, err := d.D2C(m)
+			assert.NoError(t, err)
+			c, err = c.Normalize()
+			assert.NoError(t, err)
+			assertPolyEqual(t, lin.Denominator, c.Denominator)
+			if m != Matched {
+				assertPolyEqual(t, lin.Numerator, c.Numerator)
+			}
+		})
+	}
+}
+
+func TestDiscrete_Simulate(t *testing.T) {
+	lin := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}
+	d, err := lin.C2D(0.1, ZOH)
+	assert.NoError(t, err)
+	list, err := d.Simulate(2, func(float64) (float64, error) { return 1, nil })
+	assert.NoError(t, err)
+	points, err := list.ToSlice(funcGen.NewEmptyStack[value.Value]())
+	assert.NoError(t, err)
+	assert.Equal(t, 21, len(points))
+	for _, p := range points {
+		v := p.(graph.Vector3d)
+		assert.InDelta(t, 1-math.Exp(-v.X), v.Y, 1e-9)
+	}
+}
+
+func TestPolynomial_Jury(t *testing.T) {
+	tests := []struct {
+		p      Polynomial
+		stable bool
+	}{
+		{Polynomial{0.5, -1, 1}, true},
+		{Polynomial{1, -2.5, 1}, false},
+		{Polynomial{-0.08, 0.3, 0.3, -1.2, 1}, true},
+		{Polynomial{-1.5, 1}, false},
+		{Polynomial{0.9, 0, 1}, true},
+		{Polynomial{1, 0, 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.p.String(), func(t *testing.T) {
+			stable, table, err := tt.p.Jury()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.stable, stable)
+			assert.Equal(t, 2*tt.p.Degree(), len(table))
+
+			d := &Discrete{Numerator: Polynomial{1}, Denominator: tt.p, T: 1}
+			s, err := d.IsStable()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.stable, s)
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package polynomial
+
+import (
+	"errors"
+	"github.com/hneemann/control/graph"
+	"github.com/hneemann/control/graph/grParser"
+	"github.com/hneemann/parser2/value"
+	"math"
+	"math/cmplx"
+)
+
+// FrequencyPoint is a measured point of a frequency response
+type FrequencyPoint struct {
+	W float64
+	// Magnitude is the absolute value of the frequency response
+	Magnitude float64
+	// Phase is the phase in degrees
+	Phase float64
+}
+
+// Complex returns the frequency response as a complex number
+func (f FrequencyPoint) Complex() complex128 {
+	return cmplx.Rect(f.Magnitude, f.Phase/180*math.Pi)
+}
+
+// FitFrequencyResponse fits a transfer function with a numerator of order num and a
+// monic denominator of order den to the measured frequency response. At first Levy's
+// linearized least squares problem is solved. It is followed by the iterations of
+// Sanathanan and Koerner which remove the bias towards high frequencies by weighting
+// the equations with the denominator of the previous iteration.
+func FitFrequencyResponse(data []FrequencyPoint, num, den int) (*Linear, error) {
+	if den < 1 || num < 0 || num > den {
+		return nil, errors.New("the orders need to satisfy 0<=num<=den and den>0")
+	}
+	unknowns := num + 1 + den
+	if 2*len(data) < unknowns {
+		return nil, errors.New("there are not enough frequencies for the number of parameters")
+	}
+	w0 := 0.0
+	for _, d := range data {
+		if d.W <= 0 {
+			return nil, errors.New("the frequencies need to be greater than zero")
+		}
+		w0 += math.Log(d.W)
+	}
+	// the frequencies are normalized to improve the condition of the problem
+	w0 = math.Exp(w0 / float64(len(data)))
+
+	s := make([]complex128, len(data))
+	h := make([]complex128, len(data))
+	for i, d := range data {
+		s[i] = complex(0, d.W/w0)
+		h[i] = d.Complex()
+	}
+
+	weight := make([]float64, len(data))
+	for i := range weight {
+		weight[i] = 1
+	}
+
+	var best *Linear
+	bestErr := math.Inf(1)
+	for iter := 0; iter < 50; iter++ {
+		// N(s) - H*(D(s)-sⁿ) = H*sⁿ
+		a := NewMatrix(2*len(data), unknowns)
+		b := make(Vector, 2*len(data))
+		for i := range data {
+			row := make([]complex128, unknowns)
+			sk := complex(1, 0)
+			for k := 0; k <= den; k++ {
+				if k <= num {
+					row[k] = sk
+				}
+				if k < den {
+					row[num+1+k] = -h[i] * sk
+				} else {
+					rhs := h[i] * sk
+					b[2*i] = real(rhs) * weight[i]
+					b[2*i+1] = imag(rhs) * weight[i]
+				}
+				sk *= s[i]
+			}
+			for j, c := range row {
+				a[2*i][j] = real(c) * weight[i]
+				a[2*i+1][j] = imag(c) * weight[i]
+			}
+		}
+		x, err := solveLeastSquares(a, b)
+		if err != nil {
+			return nil, err
+		}
+
+		n := Polynomial(append(Vector{}, x[:num+1]...))
+		d := make(Polynomial, den+1)
+		copy(d, x[num+1:])
+		d[den] = 1
+
+		e := 0.0
+		for i := range data {
+			dv := d.EvalCplx(s[i])
+			r := cmplx.Abs(n.EvalCplx(s[i])/dv-h[i]) / cmplx.Abs(h[i])
+			e += r * r
+			weight[i] = 1 / cmplx.Abs(dv)
+		}
+		if math.IsNaN(e) {
+			break
+		}
+		lin := &Linear{Numerator: n, Denominator: d}
+		if e < bestErr {
+			converged := bestErr-e < 1e-12*e
+			best = lin
+			bestErr = e
+			if converged {
+				break
+			}
+		}
+	}
+	if best == nil {
+		return nil, errors.New("the fit failed")
+	}
+	return best.scaleFrequency(w0), nil
+}
+
+// scaleFrequency returns the system G(s/w0) with a monic denominator
+func (l *Linear) scaleFrequency(w0 float64) *Linear {
+	scale := func(p Polynomial) Polynomial {
+		r := make(Polynomial, len(p))
+		f := 1.0
+		for i, c := range p {
+			r[i] = c * f
+			f /= w0
+		}
+		return r
+	}
+	n := scale(l.Numerator)
+	d := scale(l.Denominator)
+	lead := d[len(d)-1]
+	for i := range n {
+		n[i] /= lead
+	}
+	for i := range d {
+		d[i] /= lead
+	}
+	return &Linear{Numerator: n.Canonical(), Denominator: d.Canonical()}
+}
+
+// solveLeastSquares solves the over-determined system a*x=b in the least squares
+// sense by a QR decomposition using householder reflections.
+func solveLeastSquares(a Matrix, b Vector) (Vector, error) {
+	n := a.Cols()
+	r, b, err := householder(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	tol := 1e-14 * math.Abs(r[0][0])
+	x := make(Vector, n)
+	for k := n - 1; k >= 0; k-- {
+		if math.Abs(r[k][k]) <= tol {
+			return nil, errors.New("the system is singular")
+		}
+		sum := b[k]
+		for j := k + 1; j < n; j++ {
+			sum -= r[k][j] * x[j]
+		}
+		x[k] = sum / r[k][k]
+	}
+	return x, nil
+}
+
+// householder transforms a to the upper triangular matrix r=Q^T*a using
+// householder reflections. The vector b is transformed to Q^T*b. Both
+// arguments are not modified.
+func householder(a Matrix, b Vector) (Matrix, Vector, error) {
+	m := a.Rows()
+	n := a.Cols()
+	if m < n {
+		return nil, nil, errors.New("the system is under-determined")
+	}
+	a = a.Copy()
+	b = append(Vector{}, b...)
+	for k := 0; k < n; k++ {
+		norm := 0.0
+		for i := k; i < m; i++ {
+			norm += a[i][k] * a[i][k]
+		}
+		norm = math.Sqrt(norm)
+		if norm == 0 {
+			continue
+		}
+		if a[k][k] > 0 {
+			norm = -norm
+		}
+		// v = a[k:,k] - norm*e_k
+		v := make(Vector, m-k)
+		for i := k; i < m; i++ {
+			v[i-k] = a[i][k]
+		}
+		v[0] -= norm
+		vv := 0.0
+		for _, c := range v {
+			vv += c * c
+		}
+		for j := k; j < n; j++ {
+			f := 0.0
+			for i := k; i < m; i++ {
+				f += v[i-k] * a[i][j]
+			}
+			f = 2 * f / vv
+			for i := k; i < m; i++ {
+				a[i][j] -= f * v[i-k]
+			}
+		}
+		if len(b) > 0 {
+			f := 0.0
+			for i := k; i < m; i++ {
+				f += v[i-k] * b[i]
+			}
+			f = 2 * f / vv
+			for i := k; i < m; i++ {
+				b[i] -= f * v[i-k]
+			}
+		}
+	}
+	return a, b, nil
+}
+
+// CreateFrdContent creates the content of a bode plot which shows the measured frequency response
+func CreateFrdContent(data []FrequencyPoint, style *graph.Style, title string) []value.Value {
+	amp := make([]graph.Point, len(data))
+	pha := make([]graph.Point, len(data))
+	for i, d := range data {
+		amp[i] = graph.Point{X: d.W, Y: d.Magnitude}
+		pha[i] = graph.Point{X: d.W, Y: d.Phase}
+	}
+	sls := graph.ShapeLineStyle{Shape: graph.NewCircleMarker(3), ShapeStyle: style}
+	return []value.Value{
+		grParser.ChartContentValue{
+			Holder:      grParser.Holder[graph.ChartContent]{Value: graph.Scatter{Points: graph.PointsFromSlice(amp...), ShapeLineStyle: sls, Title: title}},
+			Initializer: bodeInitializer,
+		},
+		grParser.ChartContentValue{
+			Holder:        grParser.Holder[graph.ChartContent]{Value: graph.Scatter{Points: graph.PointsFromSlice(pha...), ShapeLineStyle: sls}},
+			SecondaryAxis: true,
+			Initializer:   bodeInitializer,
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package polynomial
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func createFrequencyData(l *Linear, wMin, wMax float64, n int) []FrequencyPoint {
+	data := make([]FrequencyPoint, n)
+	for i := range data {
+		w := wMin * math.Pow(wMax/wMin, float64(i)/float64(n-1))
+		c := l.EvalCplx(complex(0, w))
+		data[i] = FrequencyPoint{W: w, Magnitude: cmplx.Abs(c), Phase: cmplx.Phase(c) / math.Pi * 180}
+	}
+	return data
+}
+
+func TestFitFrequencyResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		sys      *Linear
+		num, den int
+	}{
+		{"PT1", &Linear{Numerator: Polynomial{2}, Denominator: Polynomial{1, 3}}, 0, 1},
+		{"PT2", &Linear{Numerator: Polynomial{5}, Denominator: Polynomial{1, 0.2, 1}}, 0, 2},
+		{"zero", &Linear{Numerator: Polynomial{1, 0.5}, Denominator: Polynomial{1, 3, 2}}, 1, 2},
+		{"integrator", &Linear{Numerator: Polynomial{10}, Denominator: Polynomial{0, 1, 1}}, 0, 2},
+		{"wide", &Linear{Numerator: Polynomial{1000}, Denominator: Polynomial{1, 1.001, 0.001}}, 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := createFrequencyData(tt.sys, 0.001, 10000, 50)
+			fit, err := FitFrequencyResponse(data, tt.num, tt.den)
+			assert.NoError(t, err)
+			for _, d := range data {
+				exp := d.Complex()
+				act := fit.EvalCplx(complex(0, d.W))
+				assert.InDelta(t, 0, cmplx.Abs(exp-act)/cmplx.Abs(exp), 1e-6, d.W)
+			}
+		})
+	}
+}
+
+func TestFitFrequencyResponseErrors(t *testing.T) {
+	data := createFrequencyData(&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}, 0.1, 10, 2)
+	_, err := FitFrequencyResponse(data, 2, 1)
+	assert.Error(t, err)
+	_, err = FitFrequencyResponse(data, 2, 3)
+	assert.Error(t, err)
+}
+
+func TestSolveLeastSquares(t *testing.T) {
+	// line through the points (0,1), (1,2), (2,4)
+	a := Matrix{{1, 0}, {1, 1}, {1, 2}}
+	x, err := solveLeastSquares(a, Vector{1, 2, 4})
+	assert.NoError(t, err)
+	assert.InDelta(t, 5.0/6, x[0], 1e-12)
+	assert.InDelta(t, 1.5, x[1], 1e-12)
+}
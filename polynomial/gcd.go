@@ -0,0 +1,257 @@
+package polynomial
+
+import (
+	"errors"
+	"math"
+)
+
+// convolution returns the matrix which multiplies p by a polynomial of degree n
+func convolution(p Polynomial, n int) Matrix {
+	m := NewMatrix(len(p)+n, n+1)
+	for j := 0; j <= n; j++ {
+		for i, c := range p {
+			m[i+j][j] = c
+		}
+	}
+	return m
+}
+
+func (p Polynomial) norm() float64 {
+	n := 0.0
+	for _, c := range p {
+		n += c * c
+	}
+	return math.Sqrt(n)
+}
+
+// Sylvester returns the Sylvester matrix of the two polynomials. The
+// coefficients are ordered starting with the highest power.
+func Sylvester(p, q Polynomial) Matrix {
+	p = p.Canonical()
+	q = q.Canonical()
+	m := p.Degree()
+	n := q.Degree()
+	s := NewMatrix(m+n, m+n)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= m; j++ {
+			s[i][i+j] = p[m-j]
+		}
+	}
+	for i := 0; i < m; i++ {
+		for j := 0; j <= n; j++ {
+			s[n+i][i+j] = q[n-j]
+		}
+	}
+	return s
+}
+
+// Resultant returns the resultant of the two polynomials, which is
+// zero if the polynomials have a common root.
+func Resultant(p, q Polynomial) (float64, error) {
+	s := Sylvester(p, q)
+	if len(s) == 0 {
+		return 1, nil
+	}
+	return s.Det()
+}
+
+// GCD returns the monic greatest common divisor of the two polynomials.
+// Two polynomials are considered to have a common divisor g if they can
+// be written as g*u and g*v with a relative error less than tol.
+func (p Polynomial) GCD(q Polynomial, tol float64) (Polynomial, error) {
+	g, _, _, err := p.gcd(q, tol)
+	return g, err
+}
+
+// gcd returns the monic greatest common divisor g and the cofactors
+// u and v with p≈g*u and q≈g*v.
+func (p Polynomial) gcd(q Polynomial, tol float64) (Polynomial, Polynomial, Polynomial, error) {
+	p = p.Canonical()
+	q = q.Canonical()
+	if p.IsZero() && q.IsZero() {
+		return nil, nil, nil, errors.New("the gcd of two zero polynomials is not defined")
+	}
+	if q.IsZero() {
+		g, f := p.Normalize()
+		return g, Polynomial{f}, Polynomial{0}, nil
+	}
+	if p.IsZero() {
+		g, f := q.Normalize()
+		return g, Polynomial{0}, Polynomial{f}, nil
+	}
+
+	m := p.Degree()
+	n := q.Degree()
+	pNorm := p.norm()
+	qNorm := q.norm()
+	pn := p.MulFloat(1 / pNorm)
+	qn := q.MulFloat(1 / qNorm)
+	for k := min(m, n); k > 0; k-- {
+		// the null space of the subresultant matrix contains the cofactors
+		s, err := Stack([][]Matrix{{convolution(pn, n-k), convolution(qn, m-k).MulFloat(-1)}})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		x, err := smallestSingularVector(s)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		v := Polynomial(x[:n-k+1])
+		u := Polynomial(x[n-k+1:])
+
+		g, ok := refineGCD(pn, qn, u, v, k, tol)
+		if ok {
+			uv, err := solveLeastSquares(convolution(g, m-k), Vector(pn))
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			vv, err := solveLeastSquares(convolution(g, n-k), Vector(qn))
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			// dividing by the leading coefficient makes g exactly monic
+			g, lead := g.Normalize()
+			return g, Polynomial(uv).MulFloat(lead * pNorm), Polynomial(vv).MulFloat(lead * qNorm), nil
+		}
+	}
+	return Polynomial{1}, p, q, nil
+}
+
+// smallestSingularVector returns the right singular vector belonging to the
+// smallest singular value of a. It is calculated by an inverse iteration
+// using the triangular factor of a QR decomposition.
+func smallestSingularVector(a Matrix) (Vector, error) {
+	r, _, err := householder(a, nil)
+	if err != nil {
+		return nil, err
+	}
+	n := a.Cols()
+	minDiag := epsMachine * r.maxAbs()
+	if minDiag == 0 {
+		return nil, errors.New("the matrix is zero")
+	}
+	for k := 0; k < n; k++ {
+		if math.Abs(r[k][k]) < minDiag {
+			r[k][k] = minDiag
+		}
+	}
+	x := make(Vector, n)
+	for i := range x {
+		x[i] = 1 / math.Sqrt(float64(n))
+	}
+	for iter := 0; iter < 5; iter++ {
+		// solve r^T*y=x
+		for i := 0; i < n; i++ {
+			sum := x[i]
+			for j := 0; j < i; j++ {
+				sum -= r[j][i] * x[j]
+			}
+			x[i] = sum / r[i][i]
+		}
+		// solve r*z=y
+		for i := n - 1; i >= 0; i-- {
+			sum := x[i]
+			for j := i + 1; j < n; j++ {
+				sum -= r[i][j] * x[j]
+			}
+			x[i] = sum / r[i][i]
+		}
+		norm := math.Sqrt(x.Mul(x))
+		for i := range x {
+			x[i] /= norm
+		}
+	}
+	return x, nil
+}
+
+// refineGCD calculates the common divisor of degree k from the cofactors
+// u and v and improves it by alternating least squares steps. It returns
+// false if the residual exceeds the tolerance.
+func refineGCD(p, q, u, v Polynomial, k int, tol float64) (Polynomial, bool) {
+	var g Polynomial
+	for iter := 0; iter < 3; iter++ {
+		a, err := Stack([][]Matrix{{convolution(u, k)}, {convolution(v, k)}})
+		if err != nil {
+			return nil, false
+		}
+		gv, err := solveLeastSquares(a, append(append(Vector{}, p...), q...))
+		if err != nil {
+			return nil, false
+		}
+		g = Polynomial(gv)
+		uv, err := solveLeastSquares(convolution(g, len(u)-1), Vector(p))
+		if err != nil {
+			return nil, false
+		}
+		vv, err := solveLeastSquares(convolution(g, len(v)-1), Vector(q))
+		if err != nil {
+			return nil, false
+		}
+		u = Polynomial(uv)
+		v = Polynomial(vv)
+	}
+	res := p.Add(g.Mul(u).MulFloat(-1)).norm() + q.Add(g.Mul(v).MulFloat(-1)).norm()
+	return g, res <= tol && math.Abs(g[k]) > eps
+}
+
+// Diophantine solves the polynomial equation a*x+b*y=c. The solution with
+// deg(y)<deg(a) is returned. If b/a is a plant, y/x is the controller which
+// places the closed loop poles at the roots of c.
+func Diophantine(a, b, c Polynomial) (Polynomial, Polynomial, error) {
+	a = a.Canonical()
+	b = b.Canonical()
+	c = c.Canonical()
+	na := a.Degree()
+	nb := b.Degree()
+	if na < 1 {
+		return nil, nil, errors.New("the polynomial a needs to have at least degree one")
+	}
+	if b.IsZero() {
+		return nil, nil, errors.New("the polynomial b must not be zero")
+	}
+	nx := max(c.Degree()-na, nb-1, 0)
+	ny := na - 1
+	rows := na + nx + 1
+	cb := convolution(b, ny)
+	for len(cb) < rows {
+		cb = append(cb, make(Vector, ny+1))
+	}
+	m, err := Stack([][]Matrix{{convolution(a, nx), cb}})
+	if err != nil {
+		return nil, nil, err
+	}
+	rhs := NewMatrix(rows, 1)
+	for i, ci := range c {
+		rhs[i][0] = ci
+	}
+	sol, err := m.Solve(rhs)
+	if err != nil {
+		return nil, nil, errors.New("no unique solution, a and b have a common factor")
+	}
+	x := make(Polynomial, nx+1)
+	for i := range x {
+		x[i] = sol[i][0]
+	}
+	y := make(Polynomial, ny+1)
+	for i := range y {
+		y[i] = sol[nx+1+i][0]
+	}
+	return x.Canonical(), y.Canonical(), nil
+}
+
+// Cancel removes the common factors of numerator and denominator. A pole and
+// a zero are cancelled if the polynomials can be factored with a relative error
+// less than tol.
+func (l *Linear) Cancel(tol float64) (*Linear, error) {
+	g, n, d, err := l.Numerator.gcd(l.Denominator, tol)
+	if err != nil {
+		return nil, err
+	}
+	if g.Degree() == 0 {
+		return l, nil
+	}
+	// keep the leading coefficient of the denominator
+	d = d.Canonical()
+	f := l.Denominator.Canonical()[l.Denominator.Degree()] / d[len(d)-1]
+	return &Linear{Numerator: n.Canonical().MulFloat(f), Denominator: d.MulFloat(f), Delay: l.Delay}, nil
+}
@@ -0,0 +1,98 @@
+package polynomial
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPolynomial_GCD(t *testing.T) {
+	tests := []struct {
+		name string
+		p, q Polynomial
+		tol  float64
+		want Polynomial
+	}{
+		{"common", Polynomial{1, 1}.Mul(Polynomial{2, 1}).Mul(Polynomial{5, 2, 1}), Polynomial{1, 1}.Mul(Polynomial{3, 1}).Mul(Polynomial{5, 2, 1}), 1e-9, Polynomial{5, 7, 3, 1}},
+		{"coprime", Polynomial{2, 3, 1}, Polynomial{7, 1}, 1e-9, Polynomial{1}},
+		{"multiple", Polynomial{1, 1}.Pow(3).Mul(Polynomial{2, 1}), Polynomial{1, 1}.Pow(3).Mul(Polynomial{2, 1}).Derivative(), 1e-9, Polynomial{1, 2, 1}},
+		{"zero", Polynomial{4, 2}, Polynomial{0}, 1e-9, Polynomial{2, 1}},
+		{"near", Polynomial{1, 1}.Mul(Polynomial{2, 1}), Polynomial{1.0001, 1}.Mul(Polynomial{3, 1}), 1e-3, Polynomial{1, 1}},
+		{"near strict", Polynomial{1, 1}.Mul(Polynomial{2, 1}), Polynomial{1.0001, 1}.Mul(Polynomial{3, 1}), 1e-9, Polynomial{1}},
+		{"high degree",
+			NewRoots(-1, -2, -3, -4, -5, -6, -7, -8).Polynomial(),
+			NewRoots(-1.5, -2, -3.5, -4, -5.5, -6, -7.5, -8).Polynomial(), 1e-9,
+			NewRoots(-2, -4, -6, -8).Polynomial()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := tt.p.GCD(tt.q, tt.tol)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.Degree(), g.Degree())
+			for i := range tt.want {
+				assert.InDelta(t, tt.want[i], g[i], 1e-6*tt.want[0]+tt.tol*10)
+			}
+		})
+	}
+
+	g, err := Polynomial{1, 1}.Pow(2).Mul(Polynomial{2, 1}).GCD(Polynomial{1, 1}.Pow(2).Mul(Polynomial{3, 1}), 1e-9)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, g[len(g)-1])
+
+	_, err = Polynomial{0}.GCD(Polynomial{0}, 1e-9)
+	assert.Error(t, err)
+}
+
+func TestSylvester(t *testing.T) {
+	s := Sylvester(Polynomial{2, 3, 1}, Polynomial{5, 4})
+	assert.Equal(t, Matrix{{1, 3, 2}, {4, 5, 0}, {0, 4, 5}}, s)
+
+	r, err := Resultant(Polynomial{2, 3, 1}, Polynomial{5, 4})
+	assert.NoError(t, err)
+	// 4^2*p(-5/4)
+	assert.InDelta(t, 16*Polynomial{2, 3, 1}.Eval(-5.0/4), r, 1e-12)
+
+	r, err = Resultant(Polynomial{2, 3, 1}, Polynomial{2, 1})
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, r, 1e-12)
+}
+
+func TestDiophantine(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b, c Polynomial
+	}{
+		{"integrator", Polynomial{0, 1, 1}, Polynomial{1}, Polynomial{1, 1}.Pow(2).Mul(Polynomial{4, 1})},
+		{"with zero", Polynomial{2, 3, 1}, Polynomial{3, 1}, Polynomial{1, 1}.Pow(2).Mul(Polynomial{4, 1})},
+		{"high order c", Polynomial{-1, 1}, Polynomial{2}, Polynomial{1, 1}.Pow(4)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y, err := Diophantine(tt.a, tt.b, tt.c)
+			assert.NoError(t, err)
+			assert.Less(t, y.Degree(), tt.a.Degree())
+			r := tt.a.Mul(x).Add(tt.b.Mul(y))
+			assert.Equal(t, tt.c.Degree(), r.Degree())
+			for i := range tt.c {
+				assert.InDelta(t, tt.c[i], r[i], 1e-9)
+			}
+		})
+	}
+
+	_, _, err := Diophantine(Polynomial{2, 3, 1}, Polynomial{1, 1}, Polynomial{1, 1, 1})
+	assert.Error(t, err)
+}
+
+func TestLinear_Cancel(t *testing.T) {
+	lin := &Linear{Numerator: Polynomial{1.0001, 1}, Denominator: Polynomial{1, 1}.Mul(Polynomial{2, 1})}
+	c, err := lin.Cancel(1e-3)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, c.Numerator.Degree())
+	assert.Equal(t, 1, c.Denominator.Degree())
+	assert.InDelta(t, 1, c.Denominator[1], 1e-12)
+	assert.InDelta(t, 2, c.Denominator[0], 1e-3)
+	assert.InDelta(t, lin.DCGain(), c.DCGain(), 1e-3)
+
+	c, err = lin.Cancel(1e-9)
+	assert.NoError(t, err)
+	assert.Equal(t, lin, c)
+}
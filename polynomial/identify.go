@@ -0,0 +1,386 @@
+package polynomial
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+type identKind int
+
+const (
+	identPT1 identKind = iota
+	identPT2
+	identPT1T
+	identTF
+)
+
+// IdentModel is the structure of the model used by the system identification
+type IdentModel struct {
+	kind     identKind
+	num, den int
+}
+
+// IdentModelByName returns the model structure with the given name
+func IdentModelByName(name string) (IdentModel, error) {
+	switch name {
+	case "PT1":
+		return IdentModel{kind: identPT1}, nil
+	case "PT2":
+		return IdentModel{kind: identPT2}, nil
+	case "PT1T":
+		return IdentModel{kind: identPT1T}, nil
+	default:
+		return IdentModel{}, fmt.Errorf("unknown model '%s', allowed are 'PT1', 'PT2' and 'PT1T'", name)
+	}
+}
+
+// NewTFModel creates a general transfer function model with a
+// numerator of order num and a denominator of order den.
+func NewTFModel(num, den int) (IdentModel, error) {
+	if den < 1 || num < 0 || num > den {
+		return IdentModel{}, errors.New("the orders need to satisfy 0<=num<=den and den>0")
+	}
+	return IdentModel{kind: identTF, num: num, den: den}, nil
+}
+
+func (m IdentModel) String() string {
+	switch m.kind {
+	case identPT1:
+		return "PT1"
+	case identPT2:
+		return "PT2"
+	case identPT1T:
+		return "PT1T"
+	default:
+		return fmt.Sprintf("%d/%d", m.num, m.den)
+	}
+}
+
+// linear creates the transfer function from the parameter vector.
+// Parameters which need to be positive are stored as logarithms.
+func (m IdentModel) linear(p []float64) *Linear {
+	switch m.kind {
+	case identPT1:
+		return &Linear{Numerator: Polynomial{p[0]}, Denominator: Polynomial{1, math.Exp(p[1])}}
+	case identPT2:
+		t := math.Exp(p[1])
+		d := math.Exp(p[2])
+		return &Linear{Numerator: Polynomial{p[0]}, Denominator: Polynomial{1, 2 * d * t, t * t}}
+	case identPT1T:
+		return &Linear{Numerator: Polynomial{p[0]}, Denominator: Polynomial{1, math.Exp(p[1])}, Delay: p[2] * p[2]}
+	default:
+		num := Polynomial(append([]float64{}, p[:m.num+1]...))
+		den := make(Polynomial, m.den+1)
+		den[0] = 1
+		copy(den[1:], p[m.num+1:])
+		return &Linear{Numerator: num, Denominator: den}
+	}
+}
+
+// initial creates the initial parameter vector from a gain k, a time constant
+// t and a dead time l estimated from the data
+func (m IdentModel) initial(k, t, l float64) []float64 {
+	switch m.kind {
+	case identPT1:
+		return []float64{k, math.Log(t + l)}
+	case identPT2:
+		return []float64{k, math.Log((t + l) / 2), 0}
+	case identPT1T:
+		return []float64{k, math.Log(t), math.Sqrt(l)}
+	default:
+		p := make([]float64, m.num+1+m.den)
+		p[0] = k
+		// the coefficients of (τs+1)^n
+		tau := (t + l) / float64(m.den)
+		c := 1.0
+		for i := 1; i <= m.den; i++ {
+			c = c * float64(m.den-i+1) / float64(i)
+			p[m.num+i] = c * math.Pow(tau, float64(i))
+		}
+		return p
+	}
+}
+
+// IdentResult is the result of a system identification
+type IdentResult struct {
+	Model *Linear
+	// RMS is the root mean square of the residuals
+	RMS float64
+	// R2 is the coefficient of determination
+	R2 float64
+}
+
+// Identify fits the model to the measured output y which is the response to the
+// input u at the times t. If u is nil, a unit step is assumed. The system is assumed
+// to be at rest before t[0]. The sum of the squared residuals is minimized by the
+// Levenberg-Marquardt method.
+func Identify(t, u, y []float64, model IdentModel) (*IdentResult, error) {
+	if u == nil {
+		u = make([]float64, len(t))
+		for i := range u {
+			u[i] = 1
+		}
+	}
+	if len(t) != len(y) || len(t) != len(u) {
+		return nil, errors.New("the number of times and values does not match")
+	}
+	if len(t) < 5 {
+		return nil, errors.New("at least five samples are required")
+	}
+	for i := 1; i < len(t); i++ {
+		if t[i] <= t[i-1] {
+			return nil, errors.New("the times need to be strictly increasing")
+		}
+	}
+
+	k, tc, l := estimateStep(t, u, y)
+	p := model.initial(k, tc, l)
+	if len(p) >= len(t) {
+		return nil, errors.New("there are not enough samples for the number of parameters")
+	}
+
+	residuals := func(p []float64) ([]float64, float64) {
+		yp, err := model.linear(p).responseAt(t, u)
+		if err != nil {
+			return nil, math.Inf(1)
+		}
+		r := make([]float64, len(y))
+		cost := 0.0
+		for i := range r {
+			r[i] = yp[i] - y[i]
+			cost += r[i] * r[i]
+		}
+		if math.IsNaN(cost) {
+			return nil, math.Inf(1)
+		}
+		return r, cost
+	}
+
+	p, err := levenbergMarquardt(residuals, p)
+	if err != nil {
+		return nil, err
+	}
+
+	r, cost := residuals(p)
+	if r == nil {
+		return nil, errors.New("the identification failed")
+	}
+	mean := 0.0
+	for _, v := range y {
+		mean += v
+	}
+	mean /= float64(len(y))
+	ssTot := 0.0
+	for _, v := range y {
+		ssTot += (v - mean) * (v - mean)
+	}
+	r2 := 1.0
+	if ssTot > 0 {
+		r2 = 1 - cost/ssTot
+	}
+	return &IdentResult{
+		Model: model.linear(p),
+		RMS:   math.Sqrt(cost / float64(len(y))),
+		R2:    r2,
+	}, nil
+}
+
+// estimateStep estimates the gain, the time constant and the dead time by
+// the times at which the response reaches 28.3% and 63.2% of its final value.
+func estimateStep(t, u, y []float64) (float64, float64, float64) {
+	n := len(t) - 1
+	span := t[n] - t[0]
+	k := 1.0
+	if u[n] != 0 && y[n] != 0 {
+		k = y[n] / u[n]
+	}
+	crossing := func(v float64) float64 {
+		for i := range t {
+			if y[i]/y[n] >= v {
+				return t[i] - t[0]
+			}
+		}
+		return math.NaN()
+	}
+	if y[n] == 0 {
+		return k, span / 5, 0
+	}
+	t28 := crossing(0.283)
+	t63 := crossing(0.632)
+	if math.IsNaN(t63) || t63 <= 0 {
+		return k, span / 5, 0
+	}
+	tc := 1.5 * (t63 - t28)
+	l := t63 - tc
+	if tc <= 0 || l < 0 {
+		return k, t63, 0
+	}
+	return k, tc, l
+}
+
+// levenbergMarquardt minimizes the sum of the squared residuals. The
+// function returns the residuals and their squared sum.
+func levenbergMarquardt(residuals func(p []float64) ([]float64, float64), p []float64) ([]float64, error) {
+	r, cost := residuals(p)
+	if r == nil {
+		return nil, errors.New("the initial model could not be evaluated")
+	}
+	n := len(p)
+	lambda := 1e-3
+	for iter := 0; iter < 500; iter++ {
+		// numerical jacobian
+		jac := make([][]float64, n)
+		for j := range p {
+			d := 1e-7 * math.Max(1, math.Abs(p[j]))
+			pd := append([]float64{}, p...)
+			pd[j] += d
+			rd, _ := residuals(pd)
+			if rd == nil {
+				return nil, errors.New("the model could not be evaluated")
+			}
+			jac[j] = make([]float64, len(r))
+			for i := range r {
+				jac[j][i] = (rd[i] - r[i]) / d
+			}
+		}
+		jtj := NewMatrix(n, n)
+		jtr := NewMatrix(n, 1)
+		for a := 0; a < n; a++ {
+			for b := 0; b < n; b++ {
+				s := 0.0
+				for i := range r {
+					s += jac[a][i] * jac[b][i]
+				}
+				jtj[a][b] = s
+			}
+			s := 0.0
+			for i := range r {
+				s += jac[a][i] * r[i]
+			}
+			jtr[a][0] = -s
+		}
+
+		improved := false
+		for !improved {
+			m := jtj.Copy()
+			for a := 0; a < n; a++ {
+				m[a][a] += lambda * (jtj[a][a] + 1e-12)
+			}
+			delta, err := m.Solve(jtr)
+			if err == nil {
+				pn := make([]float64, n)
+				for a := range pn {
+					pn[a] = p[a] + delta[a][0]
+				}
+				rn, costN := residuals(pn)
+				if rn != nil && costN < cost {
+					improved = true
+					converged := cost-costN < 1e-12*cost
+					p, r, cost = pn, rn, costN
+					lambda = math.Max(lambda/10, 1e-12)
+					if converged {
+						return p, nil
+					}
+					continue
+				}
+			}
+			lambda *= 10
+			if lambda > 1e12 {
+				return p, nil
+			}
+		}
+	}
+	return p, nil
+}
+
+// responseAt calculates the response of the system to the input u at the times t.
+// The input is interpolated linearly and is zero before t[0]. The dead time is
+// applied by shifting the response of the rational part, so that the response
+// depends smoothly on the dead time.
+func (l *Linear) responseAt(t, u []float64) ([]float64, error) {
+	if l.Delay < 0 {
+		return nil, errors.New("a negative dead time is not causal")
+	}
+	ss, err := (&Linear{Numerator: l.Numerator, Denominator: l.Denominator}).StateSpace()
+	if err != nil {
+		return nil, err
+	}
+	n := len(t) - 1
+	input := func(tau float64) float64 {
+		if tau < t[0] {
+			return 0
+		}
+		if tau >= t[n] {
+			return u[n]
+		}
+		lo, hi := 0, n
+		for hi-lo > 1 {
+			mid := (lo + hi) / 2
+			if t[mid] <= tau {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		return u[lo] + (u[hi]-u[lo])*(tau-t[lo])/(t[hi]-t[lo])
+	}
+
+	span := t[n] - t[0]
+	steps := max(2000, 2*len(t))
+	dt := span / float64(steps)
+	if ss.Order() > 0 {
+		poles, err := l.Poles()
+		if err != nil {
+			return nil, err
+		}
+		fastest := 0.0
+		for _, p := range poles.roots {
+			fastest = math.Max(fastest, cmplx.Abs(p))
+		}
+		if fastest*dt > 0.5 {
+			dt = 0.5 / fastest
+			if span/dt > 1000000 {
+				return nil, errors.New("the model is too stiff")
+			}
+			steps = int(math.Ceil(span / dt))
+		}
+	}
+
+	c := ss.C[0]
+	d := ss.D[0][0]
+	f := func(tau float64, x, dx Vector) error {
+		ut := input(tau + t[0])
+		ss.A.Mul(dx, x)
+		for i := range dx {
+			dx[i] += ss.B[i][0] * ut
+		}
+		return nil
+	}
+	tt := make([]float64, 0, steps+1)
+	yt := make([]float64, 0, steps+1)
+	err = fixedStep(RK4, f, make(Vector, ss.Order()), steps, dt, func(tau float64, x Vector) error {
+		tt = append(tt, tau+t[0])
+		yt = append(yt, c.Mul(x)+d*input(tau+t[0]))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]float64, len(t))
+	j := 0
+	for i, ti := range t {
+		ti -= l.Delay
+		if ti < t[0] {
+			// the system is at rest
+			continue
+		}
+		for j < len(tt)-2 && tt[j+1] < ti {
+			j++
+		}
+		res[i] = yt[j] + (yt[j+1]-yt[j])*(ti-tt[j])/(tt[j+1]-tt[j])
+	}
+	return res, nil
+}
@@ -0,0 +1,91 @@
+package polynomial
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func createIdentData(t *testing.T, l *Linear, u []float64, noise float64) ([]float64, []float64) {
+	n := 200
+	tMax := 20.0
+	ti := make([]float64, n)
+	for i := range ti {
+		ti[i] = tMax * float64(i) / float64(n-1)
+	}
+	in := u
+	if in == nil {
+		in = make([]float64, n)
+		for i := range in {
+			in[i] = 1
+		}
+	}
+	y, err := l.responseAt(ti, in)
+	assert.NoError(t, err)
+	r := rand.New(rand.NewSource(1))
+	for i := range y {
+		y[i] += noise * r.NormFloat64()
+	}
+	return ti, y
+}
+
+func TestIdentify(t *testing.T) {
+	pt1, _ := IdentModelByName("PT1")
+	pt2, _ := IdentModelByName("PT2")
+	pt1t, _ := IdentModelByName("PT1T")
+	tf, _ := NewTFModel(1, 2)
+	tests := []struct {
+		name  string
+		sys   *Linear
+		model IdentModel
+	}{
+		{"PT1", &Linear{Numerator: Polynomial{2}, Denominator: Polynomial{1, 3}}, pt1},
+		{"PT2", &Linear{Numerator: Polynomial{1.5}, Denominator: Polynomial{1, 1.2, 4}}, pt2},
+		{"PT1T", &Linear{Numerator: Polynomial{1.5}, Denominator: Polynomial{1, 2}, Delay: 0.7}, pt1t},
+		{"TF", &Linear{Numerator: Polynomial{1, 0.5}, Denominator: Polynomial{1, 3, 2}}, tf},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ti, y := createIdentData(t, tt.sys, nil, 0.001)
+			res, err := Identify(ti, nil, y, tt.model)
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.sys.Delay, res.Model.Delay, 0.01)
+			assert.True(t, res.RMS < 0.002, res.RMS)
+			assert.True(t, res.R2 > 0.999, res.R2)
+			for _, w := range []float64{0.1, 0.5, 1, 2} {
+				exp := tt.sys.EvalCplx(complex(0, w))
+				act := res.Model.EvalCplx(complex(0, w))
+				assert.InDelta(t, real(exp), real(act), 0.01)
+				assert.InDelta(t, imag(exp), imag(act), 0.01)
+			}
+		})
+	}
+}
+
+func TestIdentifyInput(t *testing.T) {
+	sys := &Linear{Numerator: Polynomial{2}, Denominator: Polynomial{1, 3}}
+	u := make([]float64, 200)
+	for i := range u {
+		u[i] = math.Sin(float64(i) / 10)
+	}
+	ti, y := createIdentData(t, sys, u, 0)
+	pt1, _ := IdentModelByName("PT1")
+	res, err := Identify(ti, u, y, pt1)
+	assert.NoError(t, err)
+	assert.InDelta(t, 2, res.Model.Numerator[0], 1e-3)
+	assert.InDelta(t, 3, res.Model.Denominator[1], 1e-3)
+}
+
+func TestIdentifyErrors(t *testing.T) {
+	_, err := IdentModelByName("PT3")
+	assert.Error(t, err)
+	_, err = NewTFModel(3, 2)
+	assert.Error(t, err)
+
+	pt1, _ := IdentModelByName("PT1")
+	_, err = Identify([]float64{0, 1, 2}, nil, []float64{0, 1, 1}, pt1)
+	assert.Error(t, err)
+	_, err = Identify([]float64{0, 1, 1, 2, 3}, nil, []float64{0, 1, 1, 1, 1}, pt1)
+	assert.Error(t, err)
+}
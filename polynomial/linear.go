@@ -20,23 +20,36 @@ import (
 type Linear struct {
 	Numerator   Polynomial
 	Denominator Polynomial
-	zeros       Roots
-	poles       Roots
-	pzForm      bool
+	// Delay is the dead time T, the transfer function is multiplied by e^{-sT}
+	Delay  float64
+	zeros  Roots
+	poles  Roots
+	pzForm bool
 }
 
 var _ export.ToHtmlInterface = &Linear{}
 
 func (l *Linear) EvalCplx(s complex128) complex128 {
 	c := l.Numerator.EvalCplx(s) / l.Denominator.EvalCplx(s)
+	if l.Delay != 0 {
+		c *= cmplx.Exp(-s * complex(l.Delay, 0))
+	}
 	return c
 }
 
 func (l *Linear) Eval(s float64) float64 {
 	c := l.Numerator.Eval(s) / l.Denominator.Eval(s)
+	if l.Delay != 0 {
+		c *= math.Exp(-s * l.Delay)
+	}
 	return c
 }
 
+// delayExponent returns the exponent -sT of the dead time
+func (l *Linear) delayExponent() Polynomial {
+	return Polynomial{0, -l.Delay}
+}
+
 func (l *Linear) PZForm() (*Linear, error) {
 	pz := *l
 	pz.pzForm = true
@@ -57,6 +70,7 @@ func (l *Linear) Normalize() (*Linear, error) {
 	return &Linear{
 		Numerator:   num,
 		Denominator: den,
+		Delay:       l.Delay,
 		poles:       l.poles,
 		zeros:       l.zeros,
 	}, nil
@@ -68,16 +82,25 @@ func (l *Linear) NormalizeTail() (*Linear, error) {
 	return &Linear{
 		Numerator:   num,
 		Denominator: den,
+		Delay:       l.Delay,
 		poles:       l.poles,
 		zeros:       l.zeros,
 	}, nil
 }
 
 func (l *Linear) Equals(b *Linear) bool {
-	return l.Numerator.Equals(b.Numerator) && l.Denominator.Equals(b.Denominator)
+	return l.Numerator.Equals(b.Numerator) && l.Denominator.Equals(b.Denominator) && math.Abs(l.Delay-b.Delay) < eps
 }
 
 func (l *Linear) String() string {
+	s := l.rationalString()
+	if l.Delay != 0 {
+		s += "*exp(" + l.delayExponent().String() + ")"
+	}
+	return s
+}
+
+func (l *Linear) rationalString() string {
 	var n string
 	if l.zerosCalculated() {
 		n = l.zeros.String()
@@ -107,7 +130,13 @@ func (l *Linear) String() string {
 func (l *Linear) ToHtml(_ funcGen.Stack[value.Value], w *xmlWriter.XMLWriter) error {
 	w.Open("math").
 		Attr("xmlns", "http://www.w3.org/1998/Math/MathML")
+	l.ToMathML(w)
+	w.Close()
+	return nil
+}
 
+// ToMathML writes the transfer function without the surrounding math tag.
+func (l *Linear) ToMathML(w *xmlWriter.XMLWriter) {
 	w.Open("mstyle").
 		Attr("displaystyle", "true").
 		Attr("scriptlevel", "0")
@@ -123,9 +152,13 @@ func (l *Linear) ToHtml(_ funcGen.Stack[value.Value], w *xmlWriter.XMLWriter) er
 	}
 
 	w.Close()
+	if l.Delay != 0 {
+		w.Open("msup")
+		w.Open("mi").Write("e").Close()
+		l.delayExponent().ToMathML(w)
+		w.Close()
+	}
 	w.Close()
-	w.Close()
-	return nil
 }
 
 func (l *Linear) ToLaTeX(w *bytes.Buffer) {
@@ -142,6 +175,11 @@ func (l *Linear) ToLaTeX(w *bytes.Buffer) {
 		l.Denominator.ToLaTeX(w)
 	}
 	w.WriteString("}")
+	if l.Delay != 0 {
+		w.WriteString("e^{")
+		l.delayExponent().ToLaTeX(w)
+		w.WriteString("}")
+	}
 }
 
 func (l *Linear) ToUnicode() string {
@@ -170,6 +208,11 @@ func (l *Linear) ToUnicode() string {
 		w.WriteString(l.Denominator.ToUnicode())
 	}
 	w.WriteString(")")
+	if l.Delay != 0 {
+		w.WriteString("·e^(")
+		w.WriteString(l.delayExponent().ToUnicode())
+		w.WriteString(")")
+	}
 	return w.String()
 }
 
@@ -204,7 +247,7 @@ func (l *Linear) Poles() (Roots, error) {
 }
 
 func (l *Linear) IsCausal() bool {
-	return l.Numerator.Degree() <= l.Denominator.Degree()
+	return l.Numerator.Degree() <= l.Denominator.Degree() && l.Delay >= 0
 }
 
 func FromRoots(zeros, poles Roots) *Linear {
@@ -223,6 +266,7 @@ func (l *Linear) reduce() *Linear {
 			return &Linear{
 				Numerator:   nZeros.Polynomial(),
 				Denominator: nPoles.Polynomial(),
+				Delay:       l.Delay,
 				zeros:       nZeros,
 				poles:       nPoles,
 			}
@@ -253,6 +297,7 @@ func (l *Linear) Mul(b *Linear) *Linear {
 	return (&Linear{
 		Numerator:   n,
 		Denominator: d,
+		Delay:       l.Delay + b.Delay,
 		zeros:       z,
 		poles:       p,
 	}).reduce()
@@ -262,6 +307,7 @@ func (l *Linear) Inv() *Linear {
 	return &Linear{
 		Numerator:   l.Denominator,
 		Denominator: l.Numerator,
+		Delay:       -l.Delay,
 		zeros:       l.poles,
 		poles:       l.zeros,
 	}
@@ -272,6 +318,7 @@ func (l *Linear) Div(b *Linear) *Linear {
 		return &Linear{
 			Numerator:   l.Numerator,
 			Denominator: b.Numerator,
+			Delay:       l.Delay - b.Delay,
 		}
 	}
 
@@ -296,16 +343,21 @@ func (l *Linear) Div(b *Linear) *Linear {
 	return (&Linear{
 		Numerator:   n,
 		Denominator: d,
+		Delay:       l.Delay - b.Delay,
 		zeros:       z,
 		poles:       p,
 	}).reduce()
 }
 
 func (l *Linear) Add(b *Linear) (*Linear, error) {
+	if math.Abs(l.Delay-b.Delay) > eps {
+		return nil, errors.New("systems with different dead times can not be added, use pade to approximate the dead time")
+	}
 	if l.Denominator.Equals(b.Denominator) {
 		return &Linear{
 			Numerator:   l.Numerator.Add(b.Numerator),
 			Denominator: l.Denominator,
+			Delay:       l.Delay,
 		}, nil
 	}
 
@@ -317,6 +369,7 @@ func (l *Linear) Add(b *Linear) (*Linear, error) {
 		return &Linear{
 			Numerator:   n,
 			Denominator: d.Polynomial(),
+			Delay:       l.Delay,
 			poles:       d,
 		}, nil
 	} else {
@@ -324,6 +377,7 @@ func (l *Linear) Add(b *Linear) (*Linear, error) {
 		return &Linear{
 			Numerator:   n,
 			Denominator: d,
+			Delay:       l.Delay,
 		}, nil
 	}
 }
@@ -331,9 +385,14 @@ func (l *Linear) Add(b *Linear) (*Linear, error) {
 func (l *Linear) Derivative() *Linear {
 	n := l.Numerator.Derivative().Mul(l.Denominator).Add(l.Numerator.Mul(l.Denominator.Derivative()).MulFloat(-1))
 	d := l.Denominator.Mul(l.Denominator)
+	if l.Delay != 0 {
+		// the derivative of e^{-sT} is -Te^{-sT}
+		n = n.Add(l.Numerator.Mul(l.Denominator).MulFloat(-l.Delay))
+	}
 	return &Linear{
 		Numerator:   n,
 		Denominator: d,
+		Delay:       l.Delay,
 	}
 }
 
@@ -341,6 +400,7 @@ func (l *Linear) Pow(n int) *Linear {
 	return &Linear{
 		Numerator:   l.Numerator.Pow(n),
 		Denominator: l.Denominator.Pow(n),
+		Delay:       l.Delay * float64(n),
 	}
 }
 
@@ -351,12 +411,75 @@ func NewConst(c float64) *Linear {
 	}
 }
 
-func (l *Linear) Loop() *Linear {
+// errDeadTimeLoop is returned if a loop containing a dead time is closed
+var errDeadTimeLoop = errors.New("the closed loop of a system with dead time is not a rational function, use pade to approximate the dead time or simulateBlocks to simulate the loop")
+
+func (l *Linear) Loop() (*Linear, error) {
+	if l.Delay != 0 {
+		return nil, errDeadTimeLoop
+	}
 	return &Linear{
 		Numerator:   l.Numerator,
 		zeros:       l.zeros,
 		Denominator: l.Numerator.Add(l.Denominator),
+	}, nil
+}
+
+// errFeedbackSign is returned if the sign of a feedback is zero
+var errFeedbackSign = errors.New("the sign of the feedback must not be zero")
+
+// Feedback closes the loop with the system h in the feedback path.
+// A negative sign results in a negative feedback G/(1+GH), a positive sign
+// in a positive feedback G/(1-GH). A sign of zero is rejected.
+func (l *Linear) Feedback(h *Linear, sign float64) (*Linear, error) {
+	if l.Delay != 0 || h.Delay != 0 {
+		return nil, errDeadTimeLoop
 	}
+	if sign == 0 {
+		return nil, errFeedbackSign
+	}
+	if sign < 0 {
+		sign = -1
+	} else {
+		sign = 1
+	}
+	d := l.Denominator.Mul(h.Denominator).Add(l.Numerator.Mul(h.Numerator).MulFloat(-sign)).Canonical()
+	if d.IsZero() {
+		return nil, errors.New("the feedback loop is not well-posed")
+	}
+	return &Linear{
+		Numerator:   l.Numerator.Mul(h.Denominator),
+		Denominator: d,
+	}, nil
+}
+
+// Pade returns a rational approximation of the system. The dead time
+// e^{-sT} is replaced by the Padé approximation of order n.
+func (l *Linear) Pade(n int) (*Linear, error) {
+	if n < 1 {
+		return nil, errors.New("the order of the Padé approximation needs to be at least one")
+	}
+	if l.Delay == 0 {
+		return l, nil
+	}
+	num := make(Polynomial, n+1)
+	den := make(Polynomial, n+1)
+	c := 1.0
+	tk := 1.0
+	for k := 0; k <= n; k++ {
+		den[k] = c * tk
+		if k&1 == 0 {
+			num[k] = den[k]
+		} else {
+			num[k] = -den[k]
+		}
+		// c_{k+1} = c_k (n-k) / ((2n-k)(k+1))
+		c = c * float64(n-k) / float64((2*n-k)*(k+1))
+		tk *= l.Delay
+	}
+	rational := *l
+	rational.Delay = 0
+	return rational.Mul(&Linear{Numerator: num, Denominator: den}), nil
 }
 
 func (l *Linear) Reduce() (*Linear, error) {
@@ -372,6 +495,7 @@ func (l *Linear) Reduce() (*Linear, error) {
 		return (&Linear{
 			Numerator:   nz.Polynomial(),
 			Denominator: np.Polynomial(),
+			Delay:       l.Delay,
 			zeros:       nz,
 			poles:       np,
 		}).reduceFactor(), nil
@@ -389,6 +513,7 @@ func (l *Linear) reduceFactor() *Linear {
 		return &Linear{
 			Numerator:   nz.Polynomial(),
 			Denominator: np.Polynomial(),
+			Delay:       l.Delay,
 			zeros:       nz,
 			poles:       np,
 		}
@@ -400,6 +525,7 @@ func (l *Linear) MulFloat(f float64) *Linear {
 	return &Linear{
 		Numerator:   l.Numerator.MulFloat(f),
 		Denominator: l.Denominator,
+		Delay:       l.Delay,
 	}
 }
 
@@ -407,6 +533,7 @@ func (l *Linear) DivFloat(f float64) *Linear {
 	return &Linear{
 		Numerator:   l.Numerator,
 		Denominator: l.Denominator.MulFloat(f),
+		Delay:       l.Delay,
 	}
 }
 
@@ -414,6 +541,7 @@ func (l *Linear) MulPoly(p Polynomial) *Linear {
 	return &Linear{
 		Numerator:   l.Numerator.Mul(p),
 		Denominator: l.Denominator,
+		Delay:       l.Delay,
 	}
 }
 
@@ -421,6 +549,7 @@ func (l *Linear) DivPoly(p Polynomial) *Linear {
 	return &Linear{
 		Numerator:   l.Numerator,
 		Denominator: l.Denominator.Mul(p),
+		Delay:       l.Delay,
 	}
 }
 
@@ -653,6 +782,9 @@ func setImReLabels(chart *graph.Chart) {
 }
 
 func (l *Linear) CreateEvans(kMin, kMax float64) (listMap.ListMap[value.Value], error) {
+	if l.Delay != 0 {
+		return nil, errors.New("the root locus requires a rational transfer function, use pade to approximate the dead time")
+	}
 
 	lin, err := l.Reduce()
 	if err != nil {
@@ -993,12 +1125,24 @@ func RootLocus(cpp PolynomialProvider, kMin, kMax float64, parName string) ([]gr
 	}
 }
 
+// FrequencyResponse is a system which can be evaluated at the angular frequency ω
+type FrequencyResponse interface {
+	fmt.Stringer
+	FrequencyResponse(w float64) complex128
+}
+
+func (l *Linear) FrequencyResponse(w float64) complex128 {
+	return l.EvalCplx(complex(0, w))
+}
+
 type BodeChartContent struct {
-	Linear  *Linear
+	System  FrequencyResponse
 	Latency float64
 	Style   *graph.Style
 	Title   string
 	Steps   int
+	// WLimit is the maximum frequency, zero if there is no limit
+	WLimit float64
 
 	wMin, wMax float64
 	data       []bodeData
@@ -1032,18 +1176,28 @@ func bodeInitializer(chart *graph.Chart) {
 }
 
 func (l *Linear) CreateBodeContent(style *graph.Style, title string, steps int, latency float64) []value.Value {
+	return createBodeContent(l, 0, style, title, steps, latency)
+}
+
+// bodeSteps limits the number of steps used to create a bode plot
+func bodeSteps(steps int) int {
 	if steps == 0 {
-		steps = 200
+		return 200
 	} else if steps < 100 {
-		steps = 100
+		return 100
 	} else if steps > 5000 {
-		steps = 5000
+		return 5000
 	}
+	return steps
+}
+
+func createBodeContent(sys FrequencyResponse, wLimit float64, style *graph.Style, title string, steps int, latency float64) []value.Value {
 	bcc := &BodeChartContent{
-		Linear:  l,
+		System:  sys,
+		WLimit:  wLimit,
 		Style:   style,
 		Title:   title,
-		Steps:   steps,
+		Steps:   bodeSteps(steps),
 		Latency: latency,
 	}
 	return []value.Value{
@@ -1061,7 +1215,7 @@ func (l *Linear) CreateBodeContent(style *graph.Style, title string, steps int,
 }
 
 func (bpc *BodeChartContent) String() string {
-	return fmt.Sprintf("BodeChartContent(%s)", bpc.Linear.String())
+	return fmt.Sprintf("BodeChartContent(%s)", bpc.System.String())
 }
 
 func (bpc *BodeChartContent) generateExp(wMin, wMax, exp float64) {
@@ -1110,6 +1264,9 @@ func (bpc *BodeChartContent) generate(wMin, wMax float64) {
 	if wMin <= 0 {
 		wMin = 0.001
 	}
+	if bpc.WLimit > 0 && wMax > bpc.WLimit {
+		wMax = bpc.WLimit
+	}
 	if wMax <= wMin {
 		wMax = wMin * 1000
 	}
@@ -1117,10 +1274,10 @@ func (bpc *BodeChartContent) generate(wMin, wMax float64) {
 		bpc.wMin = wMin
 		bpc.wMax = wMax
 
-		l := bpc.Linear
+		l := bpc.System
 		w := wMin
 		pha := calculateCompletePhase(l, w)
-		amp := cmplx.Abs(l.EvalCplx(complex(0, w)))
+		amp := cmplx.Abs(l.FrequencyResponse(w))
 		latFactor := bpc.Latency / math.Pi * 180
 
 		data := []bodeData{{
@@ -1136,7 +1293,7 @@ func (bpc *BodeChartContent) generate(wMin, wMax float64) {
 		modeFor:
 			for {
 				wCalc := w * wMult
-				c := l.EvalCplx(complex(0, wCalc))
+				c := l.FrequencyResponse(wCalc)
 				a := cmplx.Abs(c)
 				p := pha.advanceTo(c)
 				deltaPhase := math.Abs(pha.fullPhase() - p.fullPhase())
@@ -1175,15 +1332,15 @@ func (bpc *BodeChartContent) generate(wMin, wMax float64) {
 
 // calculateCompletePhase calculates the complete phase including all phase rotations
 // by integrating the phase changes from the given frequency down to zero.
-func calculateCompletePhase(l *Linear, w float64) fullPhase {
+func calculateCompletePhase(l FrequencyResponse, w float64) fullPhase {
 	phase := 0.0
-	if real(l.EvalCplx(complex(0, 0))) < 0 {
+	if real(l.FrequencyResponse(0)) < 0 {
 		phase = -180
 	}
-	initialDirect := cmplx.Phase(l.EvalCplx(complex(0, w))) / math.Pi * 180
+	initialDirect := cmplx.Phase(l.FrequencyResponse(w)) / math.Pi * 180
 	lastDirect := initialDirect
 	for w > 0.01 {
-		c := l.EvalCplx(complex(0, w))
+		c := l.FrequencyResponse(w)
 		direct := cmplx.Phase(c) / math.Pi * 180
 		dPhase := lastDirect - direct
 		if dPhase > 180 {
@@ -1243,7 +1400,7 @@ func (b bodePhase) Legend() []graph.Legend {
 }
 
 func (b bodePhase) String() string {
-	return fmt.Sprintf("BodePhase(%s)", b.bodeContent.Linear.String())
+	return fmt.Sprintf("BodePhase(%s)", b.bodeContent.System.String())
 }
 
 type bodeAmplitude struct {
@@ -1251,7 +1408,7 @@ type bodeAmplitude struct {
 }
 
 func (b bodeAmplitude) String() string {
-	return fmt.Sprintf("BodeAmplitude(%s)", b.bodeContent.Linear.String())
+	return fmt.Sprintf("BodeAmplitude(%s)", b.bodeContent.System.String())
 }
 
 func (b bodeAmplitude) Bounds() (x, y graph.Bounds, err error) {
@@ -1341,7 +1498,7 @@ func (l *Linear) completeNyquist(pf *graph.ParameterFunc) {
 	var end complex128
 	if l.Numerator.Degree() < l.Denominator.Degree() {
 		addEnd = true
-	} else if l.Numerator.Degree() == l.Denominator.Degree() {
+	} else if l.Numerator.Degree() == l.Denominator.Degree() && l.Delay == 0 {
 		n := l.Numerator[len(l.Numerator)-1]
 		d := l.Denominator[len(l.Denominator)-1]
 		if d != 0 {
@@ -1466,62 +1623,57 @@ func (v dataSet) toPointList(i0, i1 int) *value.List {
 }
 
 func (l *Linear) Simulate(tMax, dt float64, u func(float64) (float64, error)) (*value.List, error) {
-	if tMax <= 0 {
-		return nil, fmt.Errorf("tMax must be greater than 0")
+	u, err := l.delayInput(u)
+	if err != nil {
+		return nil, err
 	}
-
-	lin := l.reduce()
-
-	a, c, d, err := lin.GetStateSpaceRepresentation()
+	// the dead time is already applied to the input signal
+	rational := *l.reduce()
+	rational.Delay = 0
+	ss, err := rational.StateSpace()
 	if err != nil {
 		return nil, err
 	}
+	return ss.Simulate(tMax, dt, u)
+}
 
-	if dt <= 0 {
-		dt = 1e-5
+// delayInput delays the input signal by the dead time of the system
+func (l *Linear) delayInput(u func(float64) (float64, error)) (func(float64) (float64, error), error) {
+	if l.Delay == 0 {
+		return u, nil
 	}
-
-	const shouldPointsExported = 1000
-	skip := int(tMax/dt) / shouldPointsExported
-	if skip < 1 {
-		return nil, fmt.Errorf("step width (dt=%v) is too large for a meaningful simulation", dt)
+	if l.Delay < 0 {
+		return nil, errors.New("a negative dead time is not causal")
 	}
-	pointsExported := int(tMax / (dt * float64(skip)))
-
-	t := 0.0
-	n := lin.Denominator.Degree()
-	x := make(Vector, n)
-	xDot := make(Vector, n)
-
-	data := newDataSet(pointsExported+1, 2)
-	row := 0
-	counter := 0
-	for {
-		ut, err := u(t)
-		if err != nil {
-			return nil, err
-		}
-		y := c.Mul(x) + d*ut
-		if counter == 0 {
-			data.set(row, 0, t)
-			data.set(row, 1, y)
-			row++
-			if row > pointsExported {
-				return data.toPointList(0, 1), nil
-			}
-			counter = skip
+	return func(t float64) (float64, error) {
+		if t < l.Delay {
+			return 0, nil
 		}
-		counter--
+		return u(t - l.Delay)
+	}, nil
+}
 
-		a.Mul(xDot, x)
-		xDot[n-1] += ut
-		x.Add(dt, xDot)
-		t += dt
+func (l *Linear) SimulateAdaptive(tMax, hMax float64, u func(float64) (float64, error)) (*value.List, int, error) {
+	return l.SimulateSolver(RK45, tMax, hMax, u)
+}
+
+func (l *Linear) SimulateSolver(solver Solver, tMax, dt float64, u func(float64) (float64, error)) (*value.List, int, error) {
+	u, err := l.delayInput(u)
+	if err != nil {
+		return nil, 0, err
+	}
+	// the dead time is already applied to the input signal
+	rational := *l.reduce()
+	rational.Delay = 0
+	ss, err := rational.StateSpace()
+	if err != nil {
+		return nil, 0, err
 	}
+	return ss.SimulateSolver(solver, tMax, dt, u)
 }
 
 func (l *Linear) GetStateSpaceRepresentation() (Matrix, Vector, float64, error) {
-	if !l.IsCausal() {
+	if l.Numerator.Degree() > l.Denominator.Degree() {
 		return nil, nil, 0, fmt.Errorf("not a propper transfer function, numerator has higher order than denominator")
 	}
 
@@ -1581,14 +1733,26 @@ func (l *Linear) PMargin() (float64, float64, error) {
 		w0 = w1
 	}
 
+	return w0, l.phaseMargin(w0), nil
+}
+
+// phaseMargin returns the phase margin in degrees at the crossover frequency w0
+func (l *Linear) phaseMargin(w0 float64) float64 {
+	if l.Delay != 0 {
+		// the dead time can rotate the phase by more than 360°
+		rational := *l
+		rational.Delay = 0
+		ph := calculateCompletePhase(&rational, w0).fullPhase() - w0*l.Delay/math.Pi*180
+		return ph + 180
+	}
+
 	ph := cmplx.Phase(l.EvalCplx(complex(0, w0))) / math.Pi * 180
 	if ph > 0 {
 		ph = ph - 180
 	} else {
 		ph = ph + 180
 	}
-
-	return w0, ph, nil
+	return ph
 }
 
 func (l *Linear) GMargin() (float64, float64, error) {
@@ -1622,43 +1786,3 @@ func (l *Linear) GMargin() (float64, float64, error) {
 	gm := cmplx.Abs(l.EvalCplx(complex(0, w0)))
 	return w0, 20 * math.Log10(1/gm), nil
 }
-
-type Vector []float64
-
-func (v Vector) Mul(x Vector) float64 {
-	result := 0.0
-	for i := range v {
-		result += v[i] * x[i]
-	}
-	return result
-}
-
-func (v Vector) Add(dt float64, dot Vector) {
-	for i := range v {
-		v[i] += dt * dot[i]
-	}
-}
-
-type Matrix []Vector
-
-func NewMatrix(rows, cols int) Matrix {
-	m := make(Matrix, rows)
-	for i := range m {
-		m[i] = make(Vector, cols)
-	}
-	return m
-}
-
-func (m Matrix) String() string {
-	var str string
-	for _, row := range m {
-		str += fmt.Sprintf("%v\n", row)
-	}
-	return str
-}
-
-func (m Matrix) Mul(target Vector, x Vector) {
-	for i := 0; i < len(m); i++ {
-		target[i] = m[i].Mul(x)
-	}
-}
@@ -161,7 +161,7 @@ func TestLinear_Loop(t *testing.T) {
 	}
 
 	testFunc(t, g0, func(a *Linear) *Linear {
-		return a.Loop()
+		return Must(a.Loop())
 	}, expected)
 }
 
@@ -206,7 +206,8 @@ func Test_Integration(t *testing.T) {
 	gw, err := g0.Reduce()
 	assert.NoError(t, err)
 
-	gw = gw.Loop()
+	gw, err = gw.Loop()
+	assert.NoError(t, err)
 
 	p, err := gw.Poles()
 	assert.NoError(t, err)
@@ -660,6 +661,73 @@ func TestLinear_PMargin(t *testing.T) {
 	}
 }
 
+func TestLinear_Delay(t *testing.T) {
+	g := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}, Delay: 1}
+	c := g.EvalCplx(complex(0, 2))
+	assert.InDelta(t, 1/math.Sqrt(5), cmplx.Abs(c), 1e-9)
+	assert.InDelta(t, -math.Atan(2)-2, cmplx.Phase(c), 1e-9)
+
+	m := g.Mul(g)
+	assert.InDelta(t, 2, m.Delay, 1e-9)
+	assert.InDelta(t, 1, m.Div(g).Delay, 1e-9)
+	assert.Equal(t, "1/(s+1)*exp(-s)", g.String())
+
+	_, err := g.Add(NewConst(1))
+	assert.Error(t, err)
+	_, err = g.Loop()
+	assert.Error(t, err)
+
+	list, _, err := g.SimulateSolver(RK4, 5, 0.01, func(float64) (float64, error) { return 1, nil })
+	assert.NoError(t, err)
+	points, err := list.ToSlice(funcGen.NewEmptyStack[value.Value]())
+	assert.NoError(t, err)
+	for _, p := range points {
+		v := p.(graph.Vector3d)
+		if v.X < 1 {
+			assert.InDelta(t, 0, v.Y, 1e-9)
+		} else {
+			assert.InDelta(t, 1-math.Exp(-(v.X-1)), v.Y, 5e-3)
+		}
+	}
+}
+
+func TestLinear_DelayMargin(t *testing.T) {
+	// G=e^{-s}/s, crossover at ω=1, phase -90°-1rad
+	g := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{0, 1}, Delay: 1}
+	w0, ph, err := g.PMargin()
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, w0, 1e-6)
+	assert.InDelta(t, 90-180/math.Pi, ph, 1e-6)
+
+	// phase -180° at ω=π/2
+	w180, gm, err := g.GMargin()
+	assert.NoError(t, err)
+	assert.InDelta(t, math.Pi/2, w180, 1e-6)
+	assert.InDelta(t, 20*math.Log10(math.Pi/2), gm, 1e-6)
+
+	// a large dead time rotates the phase by more than 360°
+	g = &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{0, 1}, Delay: 10}
+	_, ph, err = g.PMargin()
+	assert.NoError(t, err)
+	assert.InDelta(t, 90-1800/math.Pi, ph, 1e-4)
+}
+
+func TestLinear_Pade(t *testing.T) {
+	g := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}, Delay: 0.5}
+	for _, n := range []int{1, 2, 3, 4} {
+		p, err := g.Pade(n)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, p.Delay)
+		assert.Equal(t, n+1, p.Denominator.Degree())
+		assert.InDelta(t, 1, p.Eval(0), 1e-9)
+
+		s := complex(0, 1)
+		assert.InDelta(t, 0, cmplx.Abs(g.EvalCplx(s)-p.EvalCplx(s)), math.Pow(0.1, float64(n+1)))
+	}
+	_, err := g.Pade(0)
+	assert.Error(t, err)
+}
+
 func Test_calculateStartPhase(t *testing.T) {
 	l := &Linear{
 		Numerator:   Polynomial{10},
@@ -0,0 +1,599 @@
+package polynomial
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hneemann/parser2/funcGen"
+	"github.com/hneemann/parser2/value"
+	"github.com/hneemann/parser2/value/export"
+	"github.com/hneemann/parser2/value/export/xmlWriter"
+	"math"
+)
+
+type Vector []float64
+
+func (v Vector) Mul(x Vector) float64 {
+	result := 0.0
+	for i := range v {
+		result += v[i] * x[i]
+	}
+	return result
+}
+
+func (v Vector) Add(dt float64, dot Vector) {
+	for i := range v {
+		v[i] += dt * dot[i]
+	}
+}
+
+type Matrix []Vector
+
+var _ export.ToHtmlInterface = Matrix{}
+
+func NewMatrix(rows, cols int) Matrix {
+	m := make(Matrix, rows)
+	for i := range m {
+		m[i] = make(Vector, cols)
+	}
+	return m
+}
+
+func Identity(n int) Matrix {
+	m := NewMatrix(n, n)
+	for i := range m {
+		m[i][i] = 1
+	}
+	return m
+}
+
+// ColumnVector creates a matrix with a single column
+func ColumnVector(v Vector) Matrix {
+	m := NewMatrix(len(v), 1)
+	for i, x := range v {
+		m[i][0] = x
+	}
+	return m
+}
+
+// RowVector creates a matrix with a single row
+func RowVector(v Vector) Matrix {
+	r := make(Vector, len(v))
+	copy(r, v)
+	return Matrix{r}
+}
+
+func (m Matrix) Rows() int {
+	return len(m)
+}
+
+func (m Matrix) Cols() int {
+	if len(m) == 0 {
+		return 0
+	}
+	return len(m[0])
+}
+
+func (m Matrix) String() string {
+	var str string
+	for _, row := range m {
+		str += fmt.Sprintf("%v\n", row)
+	}
+	return str
+}
+
+// Mul multiplies the matrix with the vector x and stores the result in target
+func (m Matrix) Mul(target Vector, x Vector) {
+	for i := 0; i < len(m); i++ {
+		target[i] = m[i].Mul(x)
+	}
+}
+
+func (m Matrix) Copy() Matrix {
+	c := NewMatrix(m.Rows(), m.Cols())
+	for i := range m {
+		copy(c[i], m[i])
+	}
+	return c
+}
+
+func (m Matrix) Transpose() Matrix {
+	t := NewMatrix(m.Cols(), m.Rows())
+	for i := range m {
+		for j := range m[i] {
+			t[j][i] = m[i][j]
+		}
+	}
+	return t
+}
+
+func (m Matrix) MulMatrix(b Matrix) (Matrix, error) {
+	if m.Cols() != b.Rows() {
+		return nil, fmt.Errorf("matrix dimensions do not match: %dx%d * %dx%d", m.Rows(), m.Cols(), b.Rows(), b.Cols())
+	}
+	return mul(m, b, b.Cols()), nil
+}
+
+// mul multiplies the matrices a and b without checking the dimensions.
+// The number of columns of the result is given explicitly because
+// it cannot be obtained from a matrix without rows.
+func mul(a, b Matrix, cols int) Matrix {
+	r := NewMatrix(a.Rows(), cols)
+	for i := range r {
+		for j := range r[i] {
+			sum := 0.0
+			for k := range b {
+				sum += a[i][k] * b[k][j]
+			}
+			r[i][j] = sum
+		}
+	}
+	return r
+}
+
+// add adds the matrices a and b without checking the dimensions.
+func add(a, b Matrix) Matrix {
+	r := a.Copy()
+	for i := range r {
+		for j := range r[i] {
+			r[i][j] += b[i][j]
+		}
+	}
+	return r
+}
+
+func (m Matrix) AddMatrix(b Matrix) (Matrix, error) {
+	if m.Rows() != b.Rows() || m.Cols() != b.Cols() {
+		return nil, fmt.Errorf("matrix dimensions do not match: %dx%d + %dx%d", m.Rows(), m.Cols(), b.Rows(), b.Cols())
+	}
+	return add(m, b), nil
+}
+
+func (m Matrix) SubMatrix(b Matrix) (Matrix, error) {
+	return m.AddMatrix(b.MulFloat(-1))
+}
+
+func (m Matrix) MulFloat(f float64) Matrix {
+	r := NewMatrix(m.Rows(), m.Cols())
+	for i := range r {
+		for j := range r[i] {
+			r[i][j] = m[i][j] * f
+		}
+	}
+	return r
+}
+
+func (m Matrix) IsSquare() bool {
+	return m.Rows() == m.Cols()
+}
+
+func (m Matrix) Trace() float64 {
+	t := 0.0
+	for i := range m {
+		t += m[i][i]
+	}
+	return t
+}
+
+// Stack creates a block matrix from the given blocks.
+// All blocks in a row of blocks need to have the same number of rows.
+func Stack(blocks [][]Matrix) (Matrix, error) {
+	var r Matrix
+	for _, blockRow := range blocks {
+		if len(blockRow) == 0 {
+			continue
+		}
+		rows := blockRow[0].Rows()
+		for _, b := range blockRow {
+			if b.Rows() != rows {
+				return nil, errors.New("block dimensions do not match")
+			}
+		}
+		for i := 0; i < rows; i++ {
+			var row Vector
+			for _, b := range blockRow {
+				row = append(row, b[i]...)
+			}
+			if len(r) > 0 && len(r[0]) != len(row) {
+				return nil, errors.New("block dimensions do not match")
+			}
+			r = append(r, row)
+		}
+	}
+	return r, nil
+}
+
+// Solve solves the equation m*x=b using the gauss elimination with partial pivoting.
+// The matrix b may contain several columns.
+func (m Matrix) Solve(b Matrix) (Matrix, error) {
+	n := m.Rows()
+	if !m.IsSquare() {
+		return nil, errors.New("matrix is not square")
+	}
+	if b.Rows() != n {
+		return nil, errors.New("matrix dimensions do not match")
+	}
+	a := m.Copy()
+	x := b.Copy()
+	tol := 1e-14 * (1 + a.maxAbs())
+	for k := 0; k < n; k++ {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(a[i][k]) > math.Abs(a[p][k]) {
+				p = i
+			}
+		}
+		if math.Abs(a[p][k]) < tol {
+			return nil, errors.New("matrix is singular")
+		}
+		a[k], a[p] = a[p], a[k]
+		x[k], x[p] = x[p], x[k]
+		for i := k + 1; i < n; i++ {
+			f := a[i][k] / a[k][k]
+			if f == 0 {
+				continue
+			}
+			for j := k; j < n; j++ {
+				a[i][j] -= f * a[k][j]
+			}
+			for j := range x[i] {
+				x[i][j] -= f * x[k][j]
+			}
+		}
+	}
+	for k := n - 1; k >= 0; k-- {
+		for j := range x[k] {
+			sum := x[k][j]
+			for i := k + 1; i < n; i++ {
+				sum -= a[k][i] * x[i][j]
+			}
+			x[k][j] = sum / a[k][k]
+		}
+	}
+	return x, nil
+}
+
+func (m Matrix) Inverse() (Matrix, error) {
+	return m.Solve(Identity(m.Rows()))
+}
+
+func (m Matrix) Det() (float64, error) {
+	if !m.IsSquare() {
+		return 0, errors.New("matrix is not square")
+	}
+	a := m.Copy()
+	n := len(a)
+	det := 1.0
+	for k := 0; k < n; k++ {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(a[i][k]) > math.Abs(a[p][k]) {
+				p = i
+			}
+		}
+		if a[p][k] == 0 {
+			return 0, nil
+		}
+		if p != k {
+			a[k], a[p] = a[p], a[k]
+			det = -det
+		}
+		det *= a[k][k]
+		for i := k + 1; i < n; i++ {
+			f := a[i][k] / a[k][k]
+			for j := k; j < n; j++ {
+				a[i][j] -= f * a[k][j]
+			}
+		}
+	}
+	return det, nil
+}
+
+func (m Matrix) maxAbs() float64 {
+	mx := 0.0
+	for _, row := range m {
+		for _, v := range row {
+			mx = math.Max(mx, math.Abs(v))
+		}
+	}
+	return mx
+}
+
+// Rank returns the numerical rank of the matrix.
+// A gaussian elimination with partial pivoting is used.
+func (m Matrix) Rank() int {
+	a := m.Copy()
+	rows := a.Rows()
+	cols := a.Cols()
+	tol := 1e-9 * math.Max(1, a.maxAbs())
+	rank := 0
+	for c := 0; c < cols && rank < rows; c++ {
+		p := rank
+		for i := rank + 1; i < rows; i++ {
+			if math.Abs(a[i][c]) > math.Abs(a[p][c]) {
+				p = i
+			}
+		}
+		if math.Abs(a[p][c]) < tol {
+			continue
+		}
+		a[rank], a[p] = a[p], a[rank]
+		for i := rank + 1; i < rows; i++ {
+			f := a[i][c] / a[rank][c]
+			for j := c; j < cols; j++ {
+				a[i][j] -= f * a[rank][j]
+			}
+		}
+		rank++
+	}
+	return rank
+}
+
+// norm returns the maximum absolute row sum norm of the matrix
+func (m Matrix) norm() float64 {
+	mx := 0.0
+	for _, row := range m {
+		sum := 0.0
+		for _, v := range row {
+			sum += math.Abs(v)
+		}
+		mx = math.Max(mx, sum)
+	}
+	return mx
+}
+
+// Exp returns the matrix exponential e^A.
+// The taylor series is used in combination with scaling and squaring.
+func (m Matrix) Exp() (Matrix, error) {
+	if !m.IsSquare() {
+		return nil, errors.New("matrix is not square")
+	}
+	n := m.Rows()
+	sq := 0
+	if nm := m.norm(); nm > 0.5 {
+		sq = int(math.Ceil(math.Log2(nm))) + 1
+	}
+	a := m.MulFloat(math.Pow(2, -float64(sq)))
+	res := Identity(n)
+	term := Identity(n)
+	for k := 1; k < 30; k++ {
+		term = mul(term, a, n).MulFloat(1 / float64(k))
+		res = add(res, term)
+		if term.norm() < 1e-17*res.norm() {
+			break
+		}
+	}
+	for range sq {
+		res = mul(res, res, n)
+	}
+	return res, nil
+}
+
+// Sqrt returns the principal square root of the matrix.
+// The Denman-Beavers iteration is used.
+func (m Matrix) Sqrt() (Matrix, error) {
+	if !m.IsSquare() {
+		return nil, errors.New("matrix is not square")
+	}
+	y := m.Copy()
+	z := Identity(m.Rows())
+	for range 100 {
+		yi, err := y.Inverse()
+		if err != nil {
+			return nil, errors.New("matrix has no square root")
+		}
+		zi, err := z.Inverse()
+		if err != nil {
+			return nil, errors.New("matrix has no square root")
+		}
+		yn := add(y, zi).MulFloat(0.5)
+		z = add(z, yi).MulFloat(0.5)
+		d := add(yn, y.MulFloat(-1)).norm()
+		y = yn
+		if d <= 1e-14*y.norm() {
+			return y, nil
+		}
+	}
+	return nil, errors.New("square root does not converge")
+}
+
+// Log returns the principal matrix logarithm.
+// The inverse scaling and squaring method is used.
+func (m Matrix) Log() (Matrix, error) {
+	if !m.IsSquare() {
+		return nil, errors.New("matrix is not square")
+	}
+	n := m.Rows()
+	id := Identity(n)
+	x := m
+	sq := 0
+	for add(x, id.MulFloat(-1)).norm() > 0.25 {
+		if sq > 50 {
+			return nil, errors.New("matrix logarithm does not converge")
+		}
+		var err error
+		x, err = x.Sqrt()
+		if err != nil {
+			return nil, fmt.Errorf("matrix has no real logarithm: %w", err)
+		}
+		sq++
+	}
+	e := add(x, id.MulFloat(-1))
+	res := NewMatrix(n, n)
+	term := id
+	for k := 1; k < 200; k++ {
+		term = mul(term, e, n)
+		t := term.MulFloat(1 / float64(k))
+		if k%2 == 0 {
+			t = t.MulFloat(-1)
+		}
+		res = add(res, t)
+		if t.norm() < 1e-17*math.Max(1, res.norm()) {
+			break
+		}
+	}
+	return res.MulFloat(math.Pow(2, float64(sq))), nil
+}
+
+// CharPoly returns the characteristic polynomial det(sI-A) of the matrix.
+// The Faddeev-LeVerrier algorithm is used.
+func (m Matrix) CharPoly() (Polynomial, error) {
+	if !m.IsSquare() {
+		return nil, errors.New("matrix is not square")
+	}
+	n := m.Rows()
+	p := make(Polynomial, n+1)
+	p[n] = 1
+	mk := NewMatrix(n, n)
+	for k := 1; k <= n; k++ {
+		var err error
+		mk, err = m.MulMatrix(mk)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			mk[i][i] += p[n-k+1]
+		}
+		am, err := m.MulMatrix(mk)
+		if err != nil {
+			return nil, err
+		}
+		p[n-k] = -am.Trace() / float64(k)
+	}
+	return p, nil
+}
+
+// Eigenvalues returns the eigenvalues of the matrix
+func (m Matrix) Eigenvalues() (Roots, error) {
+	cp, err := m.CharPoly()
+	if err != nil {
+		return Roots{}, err
+	}
+	return cp.Roots()
+}
+
+func (m Matrix) ToList() (*value.List, bool) {
+	return value.NewListConvert(func(row Vector) (value.Value, error) {
+		return value.NewListConvert(func(f float64) (value.Value, error) {
+			return value.Float(f), nil
+		}, row), nil
+	}, m), true
+}
+
+func (m Matrix) ToMap() (value.Map, bool) {
+	return value.Map{}, false
+}
+
+func (m Matrix) ToInt() (int, bool) {
+	return 0, false
+}
+
+func (m Matrix) ToFloat() (float64, bool) {
+	if m.Rows() == 1 && m.Cols() == 1 {
+		return m[0][0], true
+	}
+	return 0, false
+}
+
+func (m Matrix) ToString(_ funcGen.Stack[value.Value]) (string, error) {
+	return m.String(), nil
+}
+
+func (m Matrix) GetType() value.Type {
+	return MatrixValueType
+}
+
+func (m Matrix) ToHtml(_ funcGen.Stack[value.Value], w *xmlWriter.XMLWriter) error {
+	w.Open("math").
+		Attr("xmlns", "http://www.w3.org/1998/Math/MathML")
+	m.ToMathML(w)
+	w.Close()
+	return nil
+}
+
+// ToMathML writes the matrix enclosed in parentheses.
+// The surrounding math tag is not written.
+func (m Matrix) ToMathML(w *xmlWriter.XMLWriter) {
+	w.Open("mrow").
+		Open("mo").Write("(").Close()
+	w.Open("mtable")
+	for _, row := range m {
+		w.Open("mtr")
+		for _, v := range row {
+			w.Open("mtd")
+			export.NewFormattedFloat(v, 6).MathMl(w)
+			w.Close()
+		}
+		w.Close()
+	}
+	w.Close()
+	w.Open("mo").Write(")").Close().
+		Close()
+}
+
+// ToMatrix converts a value to a matrix. Accepted are matrices,
+// lists of lists which are interpreted as a list of rows, and scalars.
+func ToMatrix(st funcGen.Stack[value.Value], v value.Value) (Matrix, error) {
+	switch m := v.(type) {
+	case Matrix:
+		return m, nil
+	case value.Float, value.Int:
+		f, _ := m.ToFloat()
+		return Matrix{Vector{f}}, nil
+	}
+	list, ok := v.ToList()
+	if !ok {
+		return nil, errors.New("a matrix requires a list of rows")
+	}
+	rows, err := list.ToSlice(st)
+	if err != nil {
+		return nil, err
+	}
+	var m Matrix
+	cols := -1
+	for _, r := range rows {
+		rl, ok := r.ToList()
+		if !ok {
+			return nil, errors.New("the rows of a matrix need to be lists")
+		}
+		rv, err := rl.ToSlice(st)
+		if err != nil {
+			return nil, err
+		}
+		if cols < 0 {
+			cols = len(rv)
+		} else if cols != len(rv) {
+			return nil, errors.New("all rows of a matrix need to have the same length")
+		}
+		row := make(Vector, len(rv))
+		for i, e := range rv {
+			if f, ok := e.ToFloat(); ok {
+				row[i] = f
+			} else {
+				return nil, errors.New("the elements of a matrix need to be floats")
+			}
+		}
+		m = append(m, row)
+	}
+	return m, nil
+}
+
+// ToVector converts a list of floats to a vector
+func ToVector(st funcGen.Stack[value.Value], v value.Value) (Vector, error) {
+	list, ok := v.ToList()
+	if !ok {
+		return nil, errors.New("a vector requires a list of floats")
+	}
+	elements, err := list.ToSlice(st)
+	if err != nil {
+		return nil, err
+	}
+	vec := make(Vector, len(elements))
+	for i, e := range elements {
+		if f, ok := e.ToFloat(); ok {
+			vec[i] = f
+		} else {
+			return nil, errors.New("the elements of a vector need to be floats")
+		}
+	}
+	return vec, nil
+}
@@ -0,0 +1,95 @@
+package polynomial
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMatrix_Inverse(t *testing.T) {
+	m := Matrix{{4, 7}, {2, 6}}
+	inv, err := m.Inverse()
+	assert.NoError(t, err)
+	p, err := m.MulMatrix(inv)
+	assert.NoError(t, err)
+	for i := range p {
+		for j := range p[i] {
+			if i == j {
+				assert.InDelta(t, 1, p[i][j], 1e-12)
+			} else {
+				assert.InDelta(t, 0, p[i][j], 1e-12)
+			}
+		}
+	}
+
+	_, err = Matrix{{1, 2}, {2, 4}}.Inverse()
+	assert.Error(t, err)
+}
+
+func TestMatrix_Det(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Matrix
+		want float64
+	}{
+		{"2x2", Matrix{{4, 7}, {2, 6}}, 10},
+		{"3x3", Matrix{{0, 2, 1}, {1, 0, 3}, {2, 1, 0}}, 13},
+		{"singular", Matrix{{1, 2}, {2, 4}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := tt.m.Det()
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.want, d, 1e-12)
+		})
+	}
+}
+
+func TestMatrix_Rank(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Matrix
+		want int
+	}{
+		{"full", Matrix{{4, 7}, {2, 6}}, 2},
+		{"singular", Matrix{{1, 2}, {2, 4}}, 1},
+		{"zero", Matrix{{0, 0}, {0, 0}}, 0},
+		{"wide", Matrix{{1, 0, 1}, {0, 1, 1}}, 2},
+		{"tall", Matrix{{1, 2}, {2, 4}, {3, 6}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.m.Rank())
+		})
+	}
+}
+
+func TestMatrix_CharPoly(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Matrix
+		want Polynomial
+	}{
+		{"1x1", Matrix{{-2}}, Polynomial{2, 1}},
+		{"2x2", Matrix{{0, 1}, {-2, -3}}, Polynomial{2, 3, 1}},
+		{"3x3", Matrix{{1, 2, 0}, {0, 3, 1}, {1, 0, 2}}, Polynomial{-8, 11, -6, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := tt.m.CharPoly()
+			assert.NoError(t, err)
+			assert.True(t, tt.want.Equals(p), p.String())
+		})
+	}
+}
+
+func TestStack(t *testing.T) {
+	m, err := Stack([][]Matrix{
+		{Matrix{{1}}, Matrix{{2, 3}}},
+		{Matrix{{4}}, Matrix{{5, 6}}},
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, Matrix{{1, 2, 3}, {4, 5, 6}}, m)
+
+	_, err = Stack([][]Matrix{{Matrix{{1}}, Matrix{{2}, {3}}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,692 @@
+package polynomial
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/hneemann/control/graph"
+	"github.com/hneemann/control/graph/grParser"
+	"github.com/hneemann/parser2/funcGen"
+	"github.com/hneemann/parser2/value"
+	"github.com/hneemann/parser2/value/export"
+	"github.com/hneemann/parser2/value/export/xmlWriter"
+	"math"
+	"math/cmplx"
+)
+
+// TransferMatrix is a system with multiple inputs and outputs. The element
+// in row i and column j is the transfer function from input j to output i.
+type TransferMatrix [][]*Linear
+
+var _ export.ToHtmlInterface = TransferMatrix{}
+
+// transferMatrixTol is the tolerance used to cancel common factors if a
+// transfer matrix is calculated from a state space system
+const transferMatrixTol = 1e-8
+
+// NewTransferMatrix creates a transfer matrix from the given rows
+func NewTransferMatrix(rows [][]*Linear) (TransferMatrix, error) {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return nil, errors.New("a transfer matrix must not be empty")
+	}
+	for _, r := range rows {
+		if len(r) != len(rows[0]) {
+			return nil, errors.New("all rows of a transfer matrix need to have the same length")
+		}
+	}
+	return rows, nil
+}
+
+// TransferMatrixFromMatrix creates a transfer matrix of static gains
+func TransferMatrixFromMatrix(m Matrix) TransferMatrix {
+	t := make(TransferMatrix, m.Rows())
+	for i, row := range m {
+		t[i] = make([]*Linear, len(row))
+		for j, v := range row {
+			t[i][j] = NewConst(v)
+		}
+	}
+	return t
+}
+
+func (t TransferMatrix) Outputs() int {
+	return len(t)
+}
+
+func (t TransferMatrix) Inputs() int {
+	if len(t) == 0 {
+		return 0
+	}
+	return len(t[0])
+}
+
+func (t TransferMatrix) isSquare() bool {
+	return t.Inputs() == t.Outputs()
+}
+
+func isZero(l *Linear) bool {
+	return l.Numerator.Canonical().IsZero()
+}
+
+// addLinear adds two transfer functions. Zero elements are skipped
+// to avoid an unnecessary increase of the order.
+func addLinear(a, b *Linear) (*Linear, error) {
+	if isZero(a) {
+		return b, nil
+	}
+	if isZero(b) {
+		return a, nil
+	}
+	return a.Add(b)
+}
+
+func (t TransferMatrix) Mul(o TransferMatrix) (TransferMatrix, error) {
+	if t.Inputs() != o.Outputs() {
+		return nil, fmt.Errorf("transfer matrix dimensions do not match: %dx%d * %dx%d", t.Outputs(), t.Inputs(), o.Outputs(), o.Inputs())
+	}
+	r := make(TransferMatrix, t.Outputs())
+	for i := range r {
+		r[i] = make([]*Linear, o.Inputs())
+		for j := range r[i] {
+			sum := NewConst(0)
+			for k := range o {
+				var err error
+				sum, err = addLinear(sum, t[i][k].Mul(o[k][j]))
+				if err != nil {
+					return nil, err
+				}
+			}
+			r[i][j] = sum
+		}
+	}
+	return r, nil
+}
+
+func (t TransferMatrix) Add(o TransferMatrix) (TransferMatrix, error) {
+	if t.Inputs() != o.Inputs() || t.Outputs() != o.Outputs() {
+		return nil, fmt.Errorf("transfer matrix dimensions do not match: %dx%d + %dx%d", t.Outputs(), t.Inputs(), o.Outputs(), o.Inputs())
+	}
+	r := make(TransferMatrix, t.Outputs())
+	for i := range r {
+		r[i] = make([]*Linear, t.Inputs())
+		for j := range r[i] {
+			var err error
+			r[i][j], err = addLinear(t[i][j], o[i][j])
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+// MulLinear multiplies all elements by the given transfer function
+func (t TransferMatrix) MulLinear(l *Linear) TransferMatrix {
+	r := make(TransferMatrix, t.Outputs())
+	for i := range r {
+		r[i] = make([]*Linear, t.Inputs())
+		for j := range r[i] {
+			r[i][j] = t[i][j].Mul(l)
+		}
+	}
+	return r
+}
+
+func (t TransferMatrix) Transpose() TransferMatrix {
+	r := make(TransferMatrix, t.Inputs())
+	for j := range r {
+		r[j] = make([]*Linear, t.Outputs())
+		for i := range r[j] {
+			r[j][i] = t[i][j]
+		}
+	}
+	return r
+}
+
+// EvalCplx evaluates all elements at the complex frequency s
+func (t TransferMatrix) EvalCplx(s complex128) [][]complex128 {
+	r := make([][]complex128, t.Outputs())
+	for i := range r {
+		r[i] = make([]complex128, t.Inputs())
+		for j := range r[i] {
+			r[i][j] = t[i][j].EvalCplx(s)
+		}
+	}
+	return r
+}
+
+// StateSpace returns a state space realization. Each column is realized in
+// the controllable canonical form using the least common multiple of the
+// denominators of the column. The realization is not minimal if different
+// columns share poles.
+func (t TransferMatrix) StateSpace() (*StateSpace, error) {
+	type part struct {
+		a Matrix
+		c []Vector
+	}
+	parts := make([]part, t.Inputs())
+	n := 0
+	d := NewMatrix(t.Outputs(), t.Inputs())
+	for j := range parts {
+		den := Polynomial{1}
+		for i := range t {
+			l := t[i][j]
+			if l.Delay != 0 {
+				return nil, errDeadTime
+			}
+			if isZero(l) {
+				continue
+			}
+			g, err := den.GCD(l.Denominator, transferMatrixTol)
+			if err != nil {
+				return nil, err
+			}
+			q, _, err := l.Denominator.Div(g)
+			if err != nil {
+				return nil, err
+			}
+			den, _ = den.Mul(q).Normalize()
+		}
+
+		p := part{c: make([]Vector, t.Outputs())}
+		for i := range t {
+			l := t[i][j]
+			if isZero(l) {
+				continue
+			}
+			// expand the numerator to the common denominator
+			f, _, err := den.Div(l.Denominator)
+			if err != nil {
+				return nil, err
+			}
+			a, c, dij, err := (&Linear{Numerator: l.Numerator.Mul(f), Denominator: den}).GetStateSpaceRepresentation()
+			if err != nil {
+				return nil, err
+			}
+			p.a = a
+			p.c[i] = c
+			d[i][j] = dij
+		}
+		parts[j] = p
+		n += len(p.a)
+	}
+
+	a := NewMatrix(n, n)
+	b := NewMatrix(n, t.Inputs())
+	c := NewMatrix(t.Outputs(), n)
+	o := 0
+	for j, p := range parts {
+		k := len(p.a)
+		if k == 0 {
+			continue
+		}
+		for r := 0; r < k; r++ {
+			copy(a[o+r][o:], p.a[r])
+		}
+		for i, ci := range p.c {
+			copy(c[i][o:], ci)
+		}
+		b[o+k-1][j] = 1
+		o += k
+	}
+	return NewStateSpace(a, b, c, d)
+}
+
+// TransferMatrix returns the transfer matrix of the system.
+// Common factors of numerator and denominator are cancelled.
+func (s *StateSpace) TransferMatrix() (TransferMatrix, error) {
+	n := s.Order()
+	t := make(TransferMatrix, s.Outputs())
+	for i := range t {
+		t[i] = make([]*Linear, s.Inputs())
+		for j := range t[i] {
+			b := NewMatrix(n, 1)
+			for k := range b {
+				b[k][0] = s.B[k][j]
+			}
+			sub := &StateSpace{A: s.A, B: b, C: Matrix{s.C[i]}, D: Matrix{{s.D[i][j]}}}
+			l, err := sub.Linear()
+			if err != nil {
+				return nil, err
+			}
+			// remove the rounding errors of the numerator
+			limit := l.Denominator.norm() * 1e-12
+			num := make(Polynomial, len(l.Numerator))
+			for k, c := range l.Numerator {
+				if math.Abs(c) > limit {
+					num[k] = c
+				}
+			}
+			num = num.Canonical()
+			if num.IsZero() {
+				t[i][j] = NewConst(0)
+				continue
+			}
+			l, err = (&Linear{Numerator: num, Denominator: l.Denominator}).Cancel(transferMatrixTol)
+			if err != nil {
+				return nil, err
+			}
+			t[i][j] = l
+		}
+	}
+	return t, nil
+}
+
+// Feedback closes the loop. The system t is placed in the forward path,
+// the system h in the feedback path. If sign is negative, a negative feedback is used,
+// if it is positive, a positive feedback is used. A sign of zero is rejected.
+// The closed loop is calculated from a minimal state space realization.
+func (t TransferMatrix) Feedback(h TransferMatrix, sign float64) (TransferMatrix, error) {
+	s, err := t.StateSpace()
+	if err != nil {
+		return nil, err
+	}
+	o, err := h.StateSpace()
+	if err != nil {
+		return nil, err
+	}
+	fb, err := s.Feedback(o, sign)
+	if err != nil {
+		return nil, err
+	}
+	// the realization of t and h is not minimal if elements share poles
+	fb, err = fb.minimal()
+	if err != nil {
+		return nil, err
+	}
+	r, err := fb.TransferMatrix()
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range r {
+		for j, l := range row {
+			row[j] = &Linear{Numerator: roundSignificant(l.Numerator), Denominator: roundSignificant(l.Denominator)}
+		}
+	}
+	return r, nil
+}
+
+// roundSignificant rounds the coefficients to 12 significant digits. This
+// removes the rounding errors of the state space calculation, which would
+// otherwise turn a coefficient like 2 into 1.999999999999998.
+func roundSignificant(p Polynomial) Polynomial {
+	r := make(Polynomial, len(p))
+	for i, c := range p {
+		if c == 0 {
+			continue
+		}
+		f := math.Pow(10, 11-math.Floor(math.Log10(math.Abs(c))))
+		r[i] = math.Round(c*f) / f
+	}
+	return r
+}
+
+// Loop closes the loop with a unity negative feedback
+func (t TransferMatrix) Loop() (TransferMatrix, error) {
+	if !t.isSquare() {
+		return nil, errors.New("the loop can only be closed if the number of inputs and outputs is equal")
+	}
+	return t.Feedback(TransferMatrixFromMatrix(Identity(t.Outputs())), -1)
+}
+
+// SimulateVector simulates the system with the input vector u(t).
+// One list of points is returned for each output.
+func (t TransferMatrix) SimulateVector(solver Solver, tMax, dt float64, u func(t float64, uv Vector) error) ([]*value.List, int, error) {
+	s, err := t.StateSpace()
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.SimulateVector(solver, tMax, dt, u)
+}
+
+// DCGain returns the matrix of static gains
+func (t TransferMatrix) DCGain() (Matrix, error) {
+	m := NewMatrix(t.Outputs(), t.Inputs())
+	for i, row := range t {
+		for j, l := range row {
+			g := l.DCGain()
+			if math.IsInf(g, 0) || math.IsNaN(g) {
+				return nil, fmt.Errorf("the static gain of the element (%d,%d) is not finite", i, j)
+			}
+			m[i][j] = g
+		}
+	}
+	return m, nil
+}
+
+// RGA returns the relative gain array G(0)∘G(0)⁻ᵀ of a square system.
+// The element (i,j) is the ratio of the gain from input j to output i
+// with all other loops open to the gain with all other loops closed.
+func (t TransferMatrix) RGA() (Matrix, error) {
+	if !t.isSquare() {
+		return nil, errors.New("the relative gain array requires a square transfer matrix")
+	}
+	g, err := t.DCGain()
+	if err != nil {
+		return nil, err
+	}
+	inv, err := g.Inverse()
+	if err != nil {
+		return nil, errors.New("the static gain matrix is singular")
+	}
+	r := NewMatrix(g.Rows(), g.Cols())
+	for i := range r {
+		for j := range r[i] {
+			r[i][j] = g[i][j] * inv[j][i]
+		}
+	}
+	return r, nil
+}
+
+// Det returns the determinant of a square transfer matrix
+func (t TransferMatrix) Det() (*Linear, error) {
+	if !t.isSquare() {
+		return nil, errors.New("the determinant requires a square transfer matrix")
+	}
+	return det(t)
+}
+
+// det calculates the determinant by the expansion along the first row
+func det(t TransferMatrix) (*Linear, error) {
+	n := len(t)
+	if n == 1 {
+		return t[0][0], nil
+	}
+	sum := NewConst(0)
+	for j := 0; j < n; j++ {
+		if isZero(t[0][j]) {
+			continue
+		}
+		minor := make(TransferMatrix, n-1)
+		for i := range minor {
+			minor[i] = append(append([]*Linear{}, t[i+1][:j]...), t[i+1][j+1:]...)
+		}
+		d, err := det(minor)
+		if err != nil {
+			return nil, err
+		}
+		term := t[0][j].Mul(d)
+		if j%2 == 1 {
+			term = term.MulFloat(-1)
+		}
+		sum, err = addLinear(sum, term)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sum, nil
+}
+
+// Zeros returns the transmission zeros of a square system. These are the
+// invariant zeros of a minimal realization, which means the values of s for
+// which the rosenbrock system matrix [sI-A, -B; C, D] loses its rank.
+func (t TransferMatrix) Zeros() (Roots, error) {
+	if !t.isSquare() {
+		return Roots{}, errors.New("the zeros require a square transfer matrix")
+	}
+	ss, err := t.StateSpace()
+	if err != nil {
+		return Roots{}, err
+	}
+	ss, err = ss.minimal()
+	if err != nil {
+		return Roots{}, err
+	}
+	return ss.invariantZeros()
+}
+
+// invariantZeros returns the invariant zeros of a square system. As long as D is
+// singular, there are combinations of the outputs which do not depend on the
+// input directly. If these outputs are kept at zero, some of the states need to
+// be zero as well. These states are removed and their derivatives, which also
+// need to be zero, are added to the outputs instead. This does not change the
+// zeros of the system. If D is regular, the zeros are the eigenvalues of A-BD⁻¹C.
+func (s *StateSpace) invariantZeros() (Roots, error) {
+	errSingular := errors.New("the transfer matrix is singular")
+	a, b, c, d := s.A, s.B, s.C, s.D
+	m := s.Inputs()
+	for {
+		n := a.Rows()
+
+		// the rows of UᵀD=[D₁; 0] are compressed
+		colSpace := extendBasis(nil, d.columns())
+		rho := len(colSpace)
+		if rho == m {
+			break
+		}
+		if n == 0 {
+			return Roots{}, errSingular
+		}
+		u := columnMatrix(m, colSpace, complement(colSpace, m))
+		ut := u.Transpose()
+		ud := mul(ut, d, m)
+		uc := mul(ut, c, n)
+
+		// the columns of C₂V=[0, C₂₂] are compressed
+		rowSpace := extendBasis(nil, uc[rho:])
+		r := len(rowSpace)
+		if r < m-rho {
+			// there are outputs which are zero all the time
+			return Roots{}, errSingular
+		}
+		v := columnMatrix(n, complement(rowSpace, n), rowSpace)
+		vt := v.Transpose()
+		at := mul(mul(vt, a, n), v, n)
+		bt := mul(vt, b, m)
+		ct := mul(uc, v, n)
+
+		// the last r states are zero
+		k := n - r
+		a = NewMatrix(k, k)
+		b = NewMatrix(k, m)
+		c = NewMatrix(m, k)
+		for i := 0; i < k; i++ {
+			copy(a[i], at[i][:k])
+			copy(b[i], bt[i])
+		}
+		for i := 0; i < rho; i++ {
+			copy(c[i], ct[i][:k])
+		}
+		for i := 0; i < r; i++ {
+			copy(c[rho+i], at[k+i][:k])
+		}
+		d = append(Matrix{}, ud[:rho]...)
+		d = append(d, bt[k:]...)
+	}
+
+	if a.Rows() == 0 {
+		return NewRoots(), nil
+	}
+	x, err := d.Solve(c)
+	if err != nil {
+		return Roots{}, errSingular
+	}
+	return add(a, mul(b, x, a.Rows()).MulFloat(-1)).Eigenvalues()
+}
+
+// SingularValues returns the singular values of G(jω) in descending order
+func (t TransferMatrix) SingularValues(w float64) (Vector, error) {
+	g := t.EvalCplx(complex(0, w))
+	p := t.Outputs()
+	m := t.Inputs()
+	// the hermitian matrix GᴴG or GGᴴ, whichever is smaller
+	k := min(p, m)
+	h := make([][]complex128, k)
+	for i := range h {
+		h[i] = make([]complex128, k)
+		for j := range h[i] {
+			var sum complex128
+			if p >= m {
+				for l := 0; l < p; l++ {
+					sum += cmplx.Conj(g[l][i]) * g[l][j]
+				}
+			} else {
+				for l := 0; l < m; l++ {
+					sum += g[i][l] * cmplx.Conj(g[j][l])
+				}
+			}
+			h[i][j] = sum
+		}
+	}
+	// the real symmetric matrix [Re -Im; Im Re] has the eigenvalues of h, each twice
+	r := NewMatrix(2*k, 2*k)
+	for i := range h {
+		for j, c := range h[i] {
+			r[i][j] = real(c)
+			r[i+k][j+k] = real(c)
+			r[i][j+k] = -imag(c)
+			r[i+k][j] = imag(c)
+		}
+	}
+	ev, _, err := r.symmetricEigen()
+	if err != nil {
+		return nil, err
+	}
+	sv := make(Vector, k)
+	for i := range sv {
+		sv[i] = math.Sqrt(math.Max(ev[2*i], 0))
+	}
+	return sv, nil
+}
+
+// singularValue is the frequency response of the i-th singular value
+type singularValue struct {
+	t TransferMatrix
+	i int
+}
+
+func (s singularValue) String() string {
+	return fmt.Sprintf("σ%d(%v)", s.i+1, s.t)
+}
+
+func (s singularValue) FrequencyResponse(w float64) complex128 {
+	sv, err := s.t.SingularValues(w)
+	if err != nil {
+		return cmplx.NaN()
+	}
+	return complex(sv[s.i], 0)
+}
+
+func sigmaInitializer(chart *graph.Chart) {
+	chart.X = graph.AxisDescription{
+		Bounds:  graph.NewBounds(0.01, 100),
+		Factory: graph.LogAxis,
+		Label:   "ω [rad/s]",
+		Grid:    grParser.GridStyle,
+	}
+	chart.Y = graph.AxisDescription{
+		Factory: graph.DBAxis,
+		Label:   "Singular Values",
+		Grid:    grParser.GridStyle,
+	}
+	chart.ProtectLabels = true
+}
+
+// CreateSigmaContent creates the chart content showing the singular values
+// of G(jω). The largest singular value is drawn solid, the others dashed.
+func (t TransferMatrix) CreateSigmaContent(style *graph.Style, title string, steps int) []value.Value {
+	var content []value.Value
+	for i := 0; i < min(t.Inputs(), t.Outputs()); i++ {
+		bcc := &BodeChartContent{
+			System: singularValue{t: t, i: i},
+			Style:  style,
+			Steps:  bodeSteps(steps),
+		}
+		if i == 0 {
+			bcc.Title = title
+		} else {
+			bcc.Style = style.SetDash(7, 7)
+		}
+		content = append(content, grParser.ChartContentValue{
+			Holder:      grParser.Holder[graph.ChartContent]{Value: bodeAmplitude{bcc}},
+			Initializer: sigmaInitializer,
+		})
+	}
+	return content
+}
+
+func (t TransferMatrix) String() string {
+	var b bytes.Buffer
+	b.WriteString("[")
+	for i, row := range t {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("[")
+		for j, l := range row {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(l.String())
+		}
+		b.WriteString("]")
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+func (t TransferMatrix) ToLaTeX(w *bytes.Buffer) {
+	w.WriteString("\\left(\\table{")
+	for i, row := range t {
+		if i > 0 {
+			w.WriteString("\\\\")
+		}
+		for j, l := range row {
+			if j > 0 {
+				w.WriteString("&")
+			}
+			l.ToLaTeX(w)
+		}
+	}
+	w.WriteString("}\\right)")
+}
+
+func (t TransferMatrix) ToHtml(_ funcGen.Stack[value.Value], w *xmlWriter.XMLWriter) error {
+	w.Open("math").
+		Attr("xmlns", "http://www.w3.org/1998/Math/MathML")
+	w.Open("mrow").
+		Open("mo").Write("(").Close()
+	w.Open("mtable")
+	for _, row := range t {
+		w.Open("mtr")
+		for _, l := range row {
+			w.Open("mtd")
+			l.ToMathML(w)
+			w.Close()
+		}
+		w.Close()
+	}
+	w.Close()
+	w.Open("mo").Write(")").Close().
+		Close()
+	w.Close()
+	return nil
+}
+
+func (t TransferMatrix) ToList() (*value.List, bool) {
+	return value.NewListConvert(func(row []*Linear) (value.Value, error) {
+		return value.NewListConvert(func(l *Linear) (value.Value, error) {
+			return l, nil
+		}, row), nil
+	}, t), true
+}
+
+func (t TransferMatrix) ToMap() (value.Map, bool) {
+	return value.Map{}, false
+}
+
+func (t TransferMatrix) ToInt() (int, bool) {
+	return 0, false
+}
+
+func (t TransferMatrix) ToFloat() (float64, bool) {
+	return 0, false
+}
+
+func (t TransferMatrix) ToString(_ funcGen.Stack[value.Value]) (string, error) {
+	return t.String(), nil
+}
+
+func (t TransferMatrix) GetType() value.Type {
+	return TransferMatrixValueType
+}
@@ -0,0 +1,194 @@
+package polynomial
+
+import (
+	"github.com/hneemann/control/graph"
+	"github.com/hneemann/parser2/funcGen"
+	"github.com/hneemann/parser2/value"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func twoTank() TransferMatrix {
+	return TransferMatrix{
+		{&Linear{Numerator: Polynomial{2}, Denominator: Polynomial{1, 10}}, &Linear{Numerator: Polynomial{0.5}, Denominator: Polynomial{1, 5}}},
+		{&Linear{Numerator: Polynomial{0.8}, Denominator: Polynomial{1, 8}}, &Linear{Numerator: Polynomial{1.5}, Denominator: Polynomial{1, 12}}},
+	}
+}
+
+func twoTankController(t *testing.T) TransferMatrix {
+	k1, err := PID(2, 5, 0, 0)
+	assert.NoError(t, err)
+	k2, err := PID(3, 8, 0, 0)
+	assert.NoError(t, err)
+	return TransferMatrix{{k1, NewConst(0)}, {NewConst(0), k2}}
+}
+
+func assertTransferMatrixEqual(t *testing.T, want, got TransferMatrix, s complex128) {
+	w := want.EvalCplx(s)
+	g := got.EvalCplx(s)
+	for i := range w {
+		for j := range w[i] {
+			assert.InDelta(t, 0, cmplx.Abs(w[i][j]-g[i][j]), 1e-6*(1+cmplx.Abs(w[i][j])), "element (%d,%d) at %v", i, j, s)
+		}
+	}
+}
+
+func TestTransferMatrix_StateSpace(t *testing.T) {
+	g := twoTank()
+	ss, err := g.StateSpace()
+	assert.NoError(t, err)
+	assert.Equal(t, 4, ss.Order())
+
+	// the integrators of the controller columns are shared
+	l, err := g.Mul(twoTankController(t))
+	assert.NoError(t, err)
+	ss, err = l.StateSpace()
+	assert.NoError(t, err)
+	assert.Equal(t, 6, ss.Order())
+
+	back, err := ss.TransferMatrix()
+	assert.NoError(t, err)
+	for _, s := range []complex128{complex(0.1, 0.2), complex(0, 1), complex(-2, 3)} {
+		assertTransferMatrixEqual(t, l, back, s)
+	}
+
+	_, err = TransferMatrix{{&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}, Delay: 1}}}.StateSpace()
+	assert.Equal(t, errDeadTime, err)
+}
+
+func TestTransferMatrix_Feedback(t *testing.T) {
+	g := TransferMatrix{{&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}}}
+	fb, err := g.Feedback(TransferMatrixFromMatrix(Matrix{{2}}), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, Polynomial{1}, fb[0][0].Numerator.Canonical())
+	assert.InDelta(t, 3, fb[0][0].Denominator[0], 1e-12)
+	assert.InDelta(t, 1, fb[0][0].Denominator[1], 1e-12)
+
+	// (I+L)T=L
+	l, err := twoTank().Mul(twoTankController(t))
+	assert.NoError(t, err)
+	cl, err := l.Loop()
+	assert.NoError(t, err)
+	for _, s := range []complex128{complex(0.1, 0.2), complex(0, 1), complex(-2, 3)} {
+		lv := l.EvalCplx(s)
+		tv := cl.EvalCplx(s)
+		for i := 0; i < 2; i++ {
+			for j := 0; j < 2; j++ {
+				sum := tv[i][j]
+				for k := 0; k < 2; k++ {
+					sum += lv[i][k] * tv[k][j]
+				}
+				assert.InDelta(t, 0, cmplx.Abs(sum-lv[i][j]), 1e-6*cmplx.Abs(lv[i][j]))
+			}
+		}
+	}
+
+	// the integral action removes the static coupling
+	dc, err := cl.DCGain()
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, dc[0][0], 1e-6)
+	assert.InDelta(t, 0, dc[0][1], 1e-6)
+	assert.InDelta(t, 0, dc[1][0], 1e-6)
+	assert.InDelta(t, 1, dc[1][1], 1e-6)
+}
+
+func TestTransferMatrix_SimulateVector(t *testing.T) {
+	l, err := twoTank().Mul(twoTankController(t))
+	assert.NoError(t, err)
+	cl, err := l.Loop()
+	assert.NoError(t, err)
+	lists, _, err := cl.SimulateVector(RK4, 150, 0.05, func(t float64, uv Vector) error {
+		uv[0] = 1
+		uv[1] = 0
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, lists, 2)
+	for i, want := range []float64{1, 0} {
+		last, err := lists[i].Last(funcGen.NewEmptyStack[value.Value]())
+		assert.NoError(t, err)
+		assert.InDelta(t, want, last.(graph.Vector3d).Y, 1e-3)
+	}
+}
+
+func TestTransferMatrix_RGA(t *testing.T) {
+	rga, err := twoTank().RGA()
+	assert.NoError(t, err)
+	l11 := 1 / (1 - 0.5*0.8/(2*1.5))
+	assert.InDelta(t, l11, rga[0][0], 1e-12)
+	assert.InDelta(t, 1-l11, rga[0][1], 1e-12)
+	assert.InDelta(t, 1-l11, rga[1][0], 1e-12)
+	assert.InDelta(t, l11, rga[1][1], 1e-12)
+
+	integrator := TransferMatrix{{&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{0, 1}}}}
+	_, err = integrator.RGA()
+	assert.Error(t, err)
+}
+
+func TestTransferMatrix_Zeros(t *testing.T) {
+	// the classic example with a zero in the right half plane
+	g := TransferMatrix{
+		{&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}, &Linear{Numerator: Polynomial{2}, Denominator: Polynomial{3, 1}}},
+		{&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}, &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}},
+	}
+	z, err := g.Zeros()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, z.Count())
+	assert.InDelta(t, 1, real(z.roots[0]), 1e-9)
+
+	z, err = twoTank().Zeros()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, z.Count())
+	num := Polynomial{2.6, 30.2, 72}
+	for _, r := range z.roots {
+		assert.InDelta(t, 0, cmplx.Abs(num.EvalCplx(r)), 1e-9)
+	}
+
+	// the zero of one element is not cancelled by the pole of another element
+	z, err = TransferMatrix{
+		{&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}, NewConst(0)},
+		{NewConst(0), &Linear{Numerator: Polynomial{1, 1}, Denominator: Polynomial{2, 1}}},
+	}.Zeros()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, z.Count())
+	assert.InDelta(t, -1, real(z.roots[0]), 1e-9)
+
+	_, err = TransferMatrix{{NewConst(1), NewConst(2)}}.Zeros()
+	assert.Error(t, err)
+
+	_, err = TransferMatrix{
+		{&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}, &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}},
+		{&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}, &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}},
+	}.Zeros()
+	assert.Error(t, err)
+}
+
+func TestTransferMatrix_SingularValues(t *testing.T) {
+	g := twoTank()
+	for _, w := range []float64{0, 0.05, 0.3, 2} {
+		sv, err := g.SingularValues(w)
+		assert.NoError(t, err)
+		assert.Len(t, sv, 2)
+		assert.GreaterOrEqual(t, sv[0], sv[1])
+
+		m := g.EvalCplx(complex(0, w))
+		frob := 0.0
+		for _, row := range m {
+			for _, c := range row {
+				frob += real(c)*real(c) + imag(c)*imag(c)
+			}
+		}
+		det := m[0][0]*m[1][1] - m[0][1]*m[1][0]
+		assert.InDelta(t, frob, sv[0]*sv[0]+sv[1]*sv[1], 1e-9)
+		assert.InDelta(t, cmplx.Abs(det), sv[0]*sv[1], 1e-9)
+	}
+
+	// a single row has one singular value which is the euclidean norm
+	row := TransferMatrix{g[0]}
+	sv, err := row.SingularValues(0)
+	assert.NoError(t, err)
+	assert.Len(t, sv, 1)
+	assert.InDelta(t, math.Sqrt(2*2+0.5*0.5), sv[0], 1e-9)
+}
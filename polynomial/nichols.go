@@ -0,0 +1,180 @@
+package polynomial
+
+import (
+	"fmt"
+	"github.com/hneemann/control/graph"
+	"github.com/hneemann/control/graph/grParser"
+	"math"
+	"math/cmplx"
+)
+
+func nicholsInitializer(chart *graph.Chart) {
+	chart.X = graph.AxisDescription{
+		Factory: graph.CreateFixedStepAxis(45, 15),
+		Label:   "Phase [°]",
+		Grid:    grParser.GridStyle,
+	}
+	chart.Y = graph.AxisDescription{
+		Label: "Amplitude [dB]",
+		Grid:  grParser.GridStyle,
+	}
+}
+
+// Nichols creates the Nichols chart of the open loop: the amplitude in dB versus the phase.
+// The phase is unwrapped in the same way as in the bode plot.
+func (l *Linear) Nichols(style *graph.Style, title string, wMin, wMax float64) []graph.ChartContent {
+	if wMax == 0 {
+		wMax = l.findNyquistMax()
+	}
+	if wMin == 0 {
+		wMin = wMax * 1e-6
+	}
+	bcc := &BodeChartContent{
+		System:  &Linear{Numerator: l.Numerator, Denominator: l.Denominator},
+		Latency: l.Delay,
+		Steps:   500,
+	}
+	bcc.generate(wMin, wMax)
+	points := make([]graph.Point, 0, len(bcc.data))
+	for _, d := range bcc.data {
+		if d.amplitude > 0 && !math.IsInf(d.amplitude, 0) {
+			points = append(points, graph.Point{X: d.phase, Y: 20 * math.Log10(d.amplitude)})
+		}
+	}
+	return []graph.ChartContent{
+		graph.Scatter{
+			Points:         graph.PointsFromSlice(points...),
+			ShapeLineStyle: graph.ShapeLineStyle{LineStyle: style},
+			Title:          title,
+		},
+		graph.Scatter{
+			Points:         graph.PointsFromPoint(graph.Point{X: -180, Y: 0}),
+			ShapeLineStyle: graph.ShapeLineStyle{Shape: graph.NewCrossMarker(4), ShapeStyle: graph.Red},
+		},
+	}
+}
+
+// nicholsContour is a curve of constant magnitude or phase of the closed loop
+type nicholsContour struct {
+	segments [][]graph.Point
+	label    string
+	labelPos graph.Point
+}
+
+// add adds a point to the contour, a new segment is started if the phase wraps around
+func (c *nicholsContour) add(p graph.Point, newSegment bool) {
+	l := len(c.segments)
+	if l == 0 || newSegment || math.Abs(p.X-c.segments[l-1][len(c.segments[l-1])-1].X) > 180 {
+		c.segments = append(c.segments, []graph.Point{p})
+	} else {
+		c.segments[l-1] = append(c.segments[l-1], p)
+	}
+}
+
+var (
+	nicholsMagnitudes = []float64{-20, -12, -6, -3, -1, 0, 0.5, 1, 3, 6, 12}
+	nicholsPhases     = []float64{-2, -5, -10, -20, -30, -45, -60, -90, -120, -150, -180, 2, 5, 10, 20, 30, 45, 60, 90, 120, 150}
+)
+
+// nicholsPoint returns the point in the Nichols chart of the open loop g,
+// the phase is in the range (-360,0]
+func nicholsPoint(g complex128) graph.Point {
+	ph := cmplx.Phase(g) / math.Pi * 180
+	if ph > 0 {
+		ph -= 360
+	}
+	return graph.Point{X: ph, Y: 20 * math.Log10(cmplx.Abs(g))}
+}
+
+// createNicholsContours creates the contours of the closed loop T=G/(1+G).
+// The open loop is obtained by G=T/(1-T).
+func createNicholsContours() []nicholsContour {
+	var contours []nicholsContour
+	for _, mdb := range nicholsMagnitudes {
+		m := math.Pow(10, mdb/20)
+		var c nicholsContour
+		gap := false
+		for i := -360; i <= 360; i++ {
+			if mdb == 0 && i > -4 && i < 4 {
+				// the open loop becomes infinite
+				gap = true
+				continue
+			}
+			t := cmplx.Rect(m, float64(i)/360*math.Pi)
+			c.add(nicholsPoint(t/(1-t)), gap)
+			gap = false
+		}
+		t := cmplx.Rect(m, -math.Pi/2)
+		c.label = fmt.Sprintf("%gdB", mdb)
+		c.labelPos = nicholsPoint(t / (1 - t))
+		contours = append(contours, c)
+	}
+	for _, p := range nicholsPhases {
+		alpha := p / 180 * math.Pi
+		var c nicholsContour
+		for i := -300; i <= 300; i++ {
+			t := cmplx.Rect(math.Pow(10, float64(i)/100), alpha)
+			c.add(nicholsPoint(t/(1-t)), false)
+		}
+		t := cmplx.Rect(0.3, alpha)
+		c.label = fmt.Sprintf("%g°", p)
+		c.labelPos = nicholsPoint(t / (1 - t))
+		contours = append(contours, c)
+	}
+	return contours
+}
+
+var nicholsContours = createNicholsContours()
+
+// NicholsGrid draws the contours of constant magnitude and phase of the
+// closed loop. The grid is repeated every 360° to cover the whole chart.
+type NicholsGrid struct {
+	Style *graph.Style
+}
+
+func (n NicholsGrid) String() string {
+	return "Nichols grid"
+}
+
+func (n NicholsGrid) Bounds() (graph.Bounds, graph.Bounds, error) {
+	return graph.Bounds{}, graph.Bounds{}, nil
+}
+
+func (n NicholsGrid) DependantBounds(_, _ graph.Bounds) (graph.Bounds, graph.Bounds, error) {
+	return graph.Bounds{}, graph.Bounds{}, nil
+}
+
+func (n NicholsGrid) DrawTo(env *graph.ChartContentEnvironment) error {
+	canvas := env.Canvas
+	r := canvas.Rect()
+	textStyle := n.Style.Text()
+	textSize := canvas.Context().TextSize * 0.7
+	for k := math.Floor(r.Min.X / 360); k <= math.Ceil(r.Max.X/360); k++ {
+		shift := graph.Point{X: k * 360}
+		for _, c := range nicholsContours {
+			path := graph.NewPath(false)
+			for _, seg := range c.segments {
+				for i, p := range seg {
+					if i == 0 {
+						path = path.MoveTo(p.Add(shift))
+					} else {
+						path = path.LineTo(p.Add(shift))
+					}
+				}
+			}
+			err := canvas.DrawPath(r.IntersectPath(path), n.Style)
+			if err != nil {
+				return err
+			}
+			lp := c.labelPos.Add(shift)
+			if r.Contains(lp) {
+				canvas.DrawText(lp, c.label, graph.Left|graph.Bottom, textStyle, textSize)
+			}
+		}
+	}
+	return nil
+}
+
+func (n NicholsGrid) Legend() []graph.Legend {
+	return nil
+}
@@ -0,0 +1,67 @@
+package polynomial
+
+import (
+	"github.com/hneemann/control/graph"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func nicholsCurve(t *testing.T, l *Linear) []graph.Point {
+	cp := l.Nichols(graph.Black, "", 0.01, 100)
+	var points []graph.Point
+	for p, err := range cp[0].(graph.Scatter).Points {
+		assert.NoError(t, err)
+		points = append(points, p)
+	}
+	return points
+}
+
+func TestLinear_Nichols(t *testing.T) {
+	points := nicholsCurve(t, &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{0, 1, 1}})
+	first := points[0]
+	last := points[len(points)-1]
+	assert.InDelta(t, -90, first.X, 1)
+	assert.InDelta(t, 40, first.Y, 0.1)
+	assert.InDelta(t, -180, last.X, 1)
+	assert.InDelta(t, -80, last.Y, 0.5)
+
+	// the dead time rotates the phase beyond -360°
+	points = nicholsCurve(t, &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}, Delay: 0.1})
+	for _, p := range points {
+		// the frequency is obtained by the amplitude
+		w := math.Sqrt(math.Pow(10, -p.Y/10) - 1)
+		assert.InDelta(t, -math.Atan(w)/math.Pi*180-w*0.1/math.Pi*180, p.X, 0.5)
+	}
+	assert.Less(t, points[len(points)-1].X, -360.0)
+	for i := 1; i < len(points); i++ {
+		assert.Less(t, points[i].X, points[i-1].X)
+	}
+}
+
+func TestNicholsContours(t *testing.T) {
+	toOpenLoop := func(p graph.Point) complex128 {
+		return cmplx.Rect(math.Pow(10, p.Y/20), p.X/180*math.Pi)
+	}
+	for i, mdb := range nicholsMagnitudes {
+		m := math.Pow(10, mdb/20)
+		for _, seg := range nicholsContours[i].segments {
+			for _, p := range seg {
+				assert.GreaterOrEqual(t, p.X, -360.0)
+				assert.LessOrEqual(t, p.X, 0.0)
+				g := toOpenLoop(p)
+				assert.InDelta(t, m, cmplx.Abs(g/(1+g)), 1e-6)
+			}
+		}
+	}
+	for i, ph := range nicholsPhases {
+		for _, seg := range nicholsContours[len(nicholsMagnitudes)+i].segments {
+			for _, p := range seg {
+				g := toOpenLoop(p)
+				d := cmplx.Phase(g/(1+g))/math.Pi*180 - ph
+				assert.InDelta(t, 0, math.Remainder(d, 360), 1e-6)
+			}
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package polynomial
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hneemann/control/graph"
+	"math"
+	"math/cmplx"
+	"sort"
+	"strings"
+)
+
+// NyquistCriterion is the result of the nyquist stability criterion
+type NyquistCriterion struct {
+	// P is the number of open loop poles in the right half plane
+	P int
+	// N is the number of clockwise encirclements of the critical point -1
+	N int
+	// Z is the number of closed loop poles in the right half plane
+	Z int
+	// ImagPoles is the number of open loop poles on the imaginary axis
+	ImagPoles int
+}
+
+// Stable returns true if the closed loop is stable
+func (nc *NyquistCriterion) Stable() bool {
+	return nc.Z == 0
+}
+
+// Explanation returns a textual explanation of the result
+func (nc *NyquistCriterion) Explanation() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The open loop has P=%d poles in the right half plane. ", nc.P)
+	switch {
+	case nc.ImagPoles == 1:
+		b.WriteString("The pole on the imaginary axis is bypassed on the right by a small semicircle. ")
+	case nc.ImagPoles > 1:
+		fmt.Fprintf(&b, "The %d poles on the imaginary axis are bypassed on the right by small semicircles. ", nc.ImagPoles)
+	}
+	times := func(n int) string {
+		if n == 1 {
+			return "once"
+		}
+		return fmt.Sprintf("%d times", n)
+	}
+	switch {
+	case nc.N > 0:
+		fmt.Fprintf(&b, "The nyquist curve encircles the critical point -1 %s clockwise, so N=%d. ", times(nc.N), nc.N)
+	case nc.N < 0:
+		fmt.Fprintf(&b, "The nyquist curve encircles the critical point -1 %s counterclockwise, so N=%d. ", times(-nc.N), nc.N)
+	default:
+		b.WriteString("The nyquist curve does not encircle the critical point -1, so N=0. ")
+	}
+	fmt.Fprintf(&b, "Thus the closed loop has Z=N+P=%d poles in the right half plane", nc.Z)
+	if nc.Stable() {
+		b.WriteString(" and is stable.")
+	} else {
+		b.WriteString(" and is unstable.")
+	}
+	return b.String()
+}
+
+// contourSegment is a part of the nyquist contour in the s-plane with the parameter t in [0,1]
+type contourSegment struct {
+	s     func(t float64) complex128
+	steps int
+}
+
+// NyquistCriterion applies the nyquist stability criterion to the open loop.
+// The encirclements of the critical point -1 are counted along the nyquist
+// contour, which bypasses the poles on the imaginary axis on the right.
+// Because the contour is symmetric to the real axis, only the upper half
+// is traversed.
+func (l *Linear) NyquistCriterion() (*NyquistCriterion, error) {
+	poles, err := l.Poles()
+	if err != nil {
+		return nil, err
+	}
+	zeros, err := l.Zeros()
+	if err != nil {
+		return nil, err
+	}
+	if l.Numerator.Degree() > l.Denominator.Degree() {
+		return nil, errors.New("the nyquist criterion requires a proper transfer function")
+	}
+
+	scale := 1.0
+	for _, r := range append(append([]complex128{}, poles.roots...), zeros.roots...) {
+		scale = math.Max(scale, cmplx.Abs(r))
+	}
+
+	nc := &NyquistCriterion{}
+	var imagPoles []float64
+	for _, p := range poles.roots {
+		mul := 2
+		if imag(p) == 0 {
+			mul = 1
+		}
+		switch {
+		case math.Abs(real(p)) < 1e-8*math.Max(1, cmplx.Abs(p)):
+			nc.ImagPoles += mul
+			w := math.Abs(imag(p))
+			found := false
+			for _, ip := range imagPoles {
+				if math.Abs(ip-w) < 1e-8*scale {
+					found = true
+				}
+			}
+			if !found {
+				imagPoles = append(imagPoles, w)
+			}
+		case real(p) > 0:
+			nc.P += mul
+		}
+	}
+	sort.Float64s(imagPoles)
+
+	// the radius of the indentation around a pole on the imaginary axis
+	radius := func(w float64) float64 {
+		rho := 1e-3 * scale
+		for _, r := range append(append([]complex128{}, poles.roots...), zeros.roots...) {
+			for _, c := range []complex128{r, cmplx.Conj(r)} {
+				d := cmplx.Abs(c - complex(0, w))
+				if d > 1e-8*scale {
+					rho = math.Min(rho, 1e-3*d)
+				}
+			}
+		}
+		return rho
+	}
+
+	// the radius of the large semicircle
+	R := 100 * scale
+	if l.Delay != 0 {
+		// the large semicircle is skipped, so |G| must be less than one on it
+		if l.Numerator.Degree() == l.Denominator.Degree() && math.Abs(l.Numerator[len(l.Numerator)-1]/l.Denominator[len(l.Denominator)-1]) >= 0.5 {
+			return nil, errors.New("the nyquist criterion requires a strictly proper transfer function if there is a delay")
+		}
+		for cmplx.Abs(l.EvalCplx(complex(0, R))) > 0.1 {
+			R *= 2
+			if R > 1e6*scale {
+				return nil, errors.New("the amplitude of the open loop does not decrease")
+			}
+		}
+	}
+
+	var segments []contourSegment
+	w0 := 0.0
+	for _, w := range imagPoles {
+		rho := radius(w)
+		if w == 0 {
+			// quarter circle from the real axis to the imaginary axis
+			segments = append(segments, contourSegment{s: func(t float64) complex128 {
+				return cmplx.Rect(rho, t*math.Pi/2)
+			}, steps: 100})
+		} else {
+			segments = append(segments, imagAxisSegment(w0, w-rho))
+			segments = append(segments, contourSegment{s: func(t float64) complex128 {
+				return complex(0, w) + cmplx.Rect(rho, (t-0.5)*math.Pi)
+			}, steps: 100})
+		}
+		w0 = w + rho
+	}
+	segments = append(segments, imagAxisSegment(w0, R))
+	if l.Delay == 0 {
+		segments = append(segments, contourSegment{s: func(t float64) complex128 {
+			return cmplx.Rect(R, (1-t)*math.Pi/2)
+		}, steps: 100})
+	}
+
+	f := func(s complex128) complex128 {
+		return 1 + l.EvalCplx(s)
+	}
+	phase := 0.0
+	for _, seg := range segments {
+		ph, err := windingPhase(f, seg)
+		if err != nil {
+			return nil, err
+		}
+		phase += ph
+	}
+
+	// the contour is traversed clockwise
+	n := -2 * phase / (2 * math.Pi)
+	nc.N = int(math.Round(n))
+	if math.Abs(n-float64(nc.N)) > 0.1 {
+		return nil, errors.New("the nyquist curve passes through the critical point -1")
+	}
+	nc.Z = nc.N + nc.P
+	return nc, nil
+}
+
+// imagAxisSegment is the segment of the imaginary axis from jω0 to jω1
+func imagAxisSegment(w0, w1 float64) contourSegment {
+	if w0 > 0 {
+		// logarithmic spacing
+		return contourSegment{s: func(t float64) complex128 {
+			return complex(0, w0*math.Pow(w1/w0, t))
+		}, steps: 500}
+	}
+	return contourSegment{s: func(t float64) complex128 {
+		return complex(0, w1*t)
+	}, steps: 1000}
+}
+
+// windingPhase returns the continuous change of the phase of f along the segment
+func windingPhase(f func(s complex128) complex128, seg contourSegment) (float64, error) {
+	var rec func(t0, t1 float64, v0, v1 complex128, depth int) (float64, error)
+	rec = func(t0, t1 float64, v0, v1 complex128, depth int) (float64, error) {
+		d := cmplx.Phase(v1 / v0)
+		if math.Abs(d) < 0.1 {
+			return d, nil
+		}
+		if depth > 50 {
+			return 0, errors.New("the nyquist curve passes through the critical point -1")
+		}
+		tm := (t0 + t1) / 2
+		vm := f(seg.s(tm))
+		if vm == 0 || cmplx.IsNaN(vm) || cmplx.IsInf(vm) {
+			return 0, errors.New("the nyquist curve passes through the critical point -1")
+		}
+		d0, err := rec(t0, tm, v0, vm, depth+1)
+		if err != nil {
+			return 0, err
+		}
+		d1, err := rec(tm, t1, vm, v1, depth+1)
+		if err != nil {
+			return 0, err
+		}
+		return d0 + d1, nil
+	}
+
+	phase := 0.0
+	v0 := f(seg.s(0))
+	for i := 1; i <= seg.steps; i++ {
+		t1 := float64(i) / float64(seg.steps)
+		v1 := f(seg.s(t1))
+		if v1 == 0 || cmplx.IsNaN(v1) || cmplx.IsInf(v1) || v0 == 0 || cmplx.IsNaN(v0) || cmplx.IsInf(v0) {
+			return 0, errors.New("the nyquist curve passes through the critical point -1")
+		}
+		d, err := rec(float64(i-1)/float64(seg.steps), t1, v0, v1, 0)
+		if err != nil {
+			return 0, err
+		}
+		phase += d
+		v0 = v1
+	}
+	return phase, nil
+}
+
+// NyquistArrows creates arrows which show the direction of the nyquist curve with increasing ω.
+// If neg is true, also the arrows of the curve with negative ω are created.
+// Arrows which are too close to the previous arrow are omitted.
+func (l *Linear) NyquistArrows(style *graph.Style, neg bool) []graph.ChartContent {
+	wMax := l.findNyquistMax()
+	toPoint := func(c complex128) graph.Point {
+		return graph.Point{X: real(c), Y: imag(c)}
+	}
+	var ws []float64
+	size := 1.0
+	for i := 1; i <= 16; i++ {
+		w := wMax * math.Pow(10, -float64(i)/2)
+		c := l.EvalCplx(complex(0, w))
+		a := cmplx.Abs(c)
+		if cmplx.IsNaN(c) || a < 0.05 || a > 20 {
+			continue
+		}
+		ws = append(ws, w)
+		size = math.Max(size, a)
+	}
+
+	// the minimal distance of two arrows is relative to the size of the curve
+	minDist := size / 5
+	var cc []graph.ChartContent
+	last := cmplx.NaN()
+	for _, w := range ws {
+		c := l.EvalCplx(complex(0, w))
+		if cmplx.Abs(c-last) < minDist {
+			continue
+		}
+		last = c
+		cc = append(cc, graph.Arrow{From: toPoint(c), To: toPoint(l.EvalCplx(complex(0, w*1.01))), Style: style, Mode: 1})
+		if neg {
+			cc = append(cc, graph.Arrow{From: toPoint(l.EvalCplx(complex(0, -w*1.01))), To: toPoint(cmplx.Conj(c)), Style: style, Mode: 1})
+		}
+	}
+	return cc
+}
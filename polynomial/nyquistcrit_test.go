@@ -0,0 +1,72 @@
+package polynomial
+
+import (
+	"github.com/hneemann/control/graph"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestLinear_NyquistCriterion(t *testing.T) {
+	tests := []struct {
+		name string
+		l    *Linear
+		p    int
+		n    int
+		imag int
+	}{
+		{name: "stable", l: &Linear{Numerator: Polynomial{3}, Denominator: Polynomial{2, 3, 1}}},
+		{name: "unstableOpenLoop", l: &Linear{Numerator: Polynomial{6, 6}, Denominator: Polynomial{3, -4, 1}}, p: 2, n: -2},
+		{name: "integrator", l: &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{0, 2, 3, 1}}, imag: 1},
+		{name: "integratorUnstable", l: &Linear{Numerator: Polynomial{10}, Denominator: Polynomial{0, 2, 3, 1}}, n: 2, imag: 1},
+		{name: "doubleIntegrator", l: &Linear{Numerator: Polynomial{1, 1}, Denominator: Polynomial{0, 0, 1}}, imag: 2},
+		{name: "oscillator", l: &Linear{Numerator: Polynomial{1, 1}, Denominator: Polynomial{4, 0, 1}}, n: 0, imag: 2},
+		{name: "delay", l: &Linear{Numerator: Polynomial{5}, Denominator: Polynomial{1, 1}, Delay: 1}, n: 2},
+		{name: "delayStable", l: &Linear{Numerator: Polynomial{0.5}, Denominator: Polynomial{1, 1}, Delay: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nc, err := tt.l.NyquistCriterion()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.p, nc.P)
+			assert.Equal(t, tt.n, nc.N)
+			assert.Equal(t, tt.p+tt.n, nc.Z)
+			assert.Equal(t, tt.imag, nc.ImagPoles)
+
+			if tt.l.Delay == 0 {
+				// compare with the roots of the closed loop
+				cl, err := tt.l.Numerator.Add(tt.l.Denominator).Roots()
+				assert.NoError(t, err)
+				z := 0
+				for _, r := range cl.roots {
+					if real(r) > 0 {
+						if imag(r) == 0 {
+							z++
+						} else {
+							z += 2
+						}
+					}
+				}
+				assert.Equal(t, z, nc.Z)
+			}
+		})
+	}
+}
+
+func TestLinear_NyquistCriterionMarginal(t *testing.T) {
+	l := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{0, 0, 1}}
+	_, err := l.NyquistCriterion()
+	assert.Error(t, err)
+}
+
+func TestLinear_NyquistArrows(t *testing.T) {
+	// the dead time creates a spiral, at low frequencies all points are close to G(0)=1
+	l := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 2, 1}, Delay: 2}
+	arrows := l.NyquistArrows(graph.Black, false)
+	assert.True(t, len(arrows) > 2)
+	for i := 1; i < len(arrows); i++ {
+		p0 := arrows[i-1].(graph.Arrow).From
+		p1 := arrows[i].(graph.Arrow).From
+		assert.True(t, math.Hypot(p1.X-p0.X, p1.Y-p0.Y) >= 0.2)
+	}
+}
@@ -0,0 +1,210 @@
+package polynomial
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Solver selects the integration method used by the simulations
+type Solver int
+
+const (
+	Euler Solver = iota
+	RK4
+	RK45
+)
+
+func (s Solver) String() string {
+	switch s {
+	case RK4:
+		return "rk4"
+	case RK45:
+		return "rk45"
+	default:
+		return "euler"
+	}
+}
+
+// SolverByName returns the solver with the given name
+func SolverByName(name string) (Solver, error) {
+	switch name {
+	case "euler":
+		return Euler, nil
+	case "rk4":
+		return RK4, nil
+	case "rk45":
+		return RK45, nil
+	default:
+		return Euler, fmt.Errorf("unknown solver '%s', allowed are 'euler', 'rk4' and 'rk45'", name)
+	}
+}
+
+// odeFunc calculates the derivative dx of the state x at the time t
+type odeFunc func(t float64, x, dx Vector) error
+
+// odeOutput is called with every accepted state
+type odeOutput func(t float64, x Vector) error
+
+const (
+	odeTolerance = 1e-6
+	odeMaxSteps  = 1000000
+)
+
+// Dormand-Prince coefficients
+var (
+	dpC = [7]float64{0, 1.0 / 5, 3.0 / 10, 4.0 / 5, 8.0 / 9, 1, 1}
+	dpA = [7][6]float64{
+		{},
+		{1.0 / 5},
+		{3.0 / 40, 9.0 / 40},
+		{44.0 / 45, -56.0 / 15, 32.0 / 9},
+		{19372.0 / 6561, -25360.0 / 2187, 64448.0 / 6561, -212.0 / 729},
+		{9017.0 / 3168, -355.0 / 33, 46732.0 / 5247, 49.0 / 176, -5103.0 / 18656},
+		{35.0 / 384, 0, 500.0 / 1113, 125.0 / 192, -2187.0 / 6784, 11.0 / 84},
+	}
+	// dpE is the difference of the fifth and the fourth order solution
+	dpE = [7]float64{71.0 / 57600, 0, -71.0 / 16695, 71.0 / 1920, -17253.0 / 339200, 22.0 / 525, -1.0 / 40}
+)
+
+// rk45 integrates the ode using the Dormand-Prince method with an adaptive step width.
+// The state x is used as the initial value and is modified. The output function
+// is called at t=0 and after every accepted step. The step width does not exceed hMax.
+// Returns the number of accepted steps.
+func rk45(f odeFunc, x Vector, tMax, hMax float64, out odeOutput) (int, error) {
+	n := len(x)
+	var k [7]Vector
+	for i := range k {
+		k[i] = make(Vector, n)
+	}
+	xs := make(Vector, n)
+	xn := make(Vector, n)
+
+	t := 0.0
+	err := out(t, x)
+	if err != nil {
+		return 0, err
+	}
+	err = f(t, x, k[0])
+	if err != nil {
+		return 0, err
+	}
+
+	h := hMax / 10
+	steps := 0
+	for t < tMax {
+		if t+h > tMax || tMax-(t+h) < 1e-10*tMax {
+			h = tMax - t
+		}
+		for s := 1; s < 7; s++ {
+			for i := range xs {
+				sum := 0.0
+				for j := 0; j < s; j++ {
+					sum += dpA[s][j] * k[j][i]
+				}
+				xs[i] = x[i] + h*sum
+			}
+			err = f(t+dpC[s]*h, xs, k[s])
+			if err != nil {
+				return 0, err
+			}
+		}
+		// the seventh stage is evaluated at the new solution
+		copy(xn, xs)
+
+		e := 0.0
+		for i := range x {
+			errEst := 0.0
+			for j := range dpE {
+				errEst += dpE[j] * k[j][i]
+			}
+			sc := odeTolerance * (1 + math.Max(math.Abs(x[i]), math.Abs(xn[i])))
+			e = math.Max(e, math.Abs(h*errEst)/sc)
+		}
+
+		if e <= 1 {
+			t += h
+			copy(x, xn)
+			copy(k[0], k[6])
+			steps++
+			if steps > odeMaxSteps {
+				return 0, errors.New("too many integration steps")
+			}
+			err = out(t, x)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		factor := 5.0
+		if e > 0 {
+			factor = math.Min(5, math.Max(0.2, 0.9*math.Pow(e, -0.2)))
+		}
+		h = math.Min(h*factor, hMax)
+		if e > 1 && h < tMax*1e-12 {
+			return 0, errors.New("step width too small, the system may be stiff")
+		}
+	}
+	return steps, nil
+}
+
+// fixedStep integrates the ode using the euler or the classical fourth order Runge-Kutta
+// method with the constant step width dt. The state x is used as the initial value and
+// is modified. The output function is called at t=0 and after every step.
+func fixedStep(solver Solver, f odeFunc, x Vector, steps int, dt float64, out odeOutput) error {
+	n := len(x)
+	k1 := make(Vector, n)
+	var k2, k3, k4, xs Vector
+	if solver == RK4 {
+		k2 = make(Vector, n)
+		k3 = make(Vector, n)
+		k4 = make(Vector, n)
+		xs = make(Vector, n)
+	}
+
+	t := 0.0
+	err := out(t, x)
+	if err != nil {
+		return err
+	}
+	for range steps {
+		err = f(t, x, k1)
+		if err != nil {
+			return err
+		}
+		if solver == RK4 {
+			for i := range xs {
+				xs[i] = x[i] + dt/2*k1[i]
+			}
+			err = f(t+dt/2, xs, k2)
+			if err != nil {
+				return err
+			}
+			for i := range xs {
+				xs[i] = x[i] + dt/2*k2[i]
+			}
+			err = f(t+dt/2, xs, k3)
+			if err != nil {
+				return err
+			}
+			for i := range xs {
+				xs[i] = x[i] + dt*k3[i]
+			}
+			err = f(t+dt, xs, k4)
+			if err != nil {
+				return err
+			}
+			for i := range x {
+				x[i] += dt / 6 * (k1[i] + 2*k2[i] + 2*k3[i] + k4[i])
+			}
+		} else {
+			x.Add(dt, k1)
+		}
+		t += dt
+		err = out(t, x)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package polynomial
+
+import (
+	"github.com/hneemann/control/graph"
+	"github.com/hneemann/parser2/funcGen"
+	"github.com/hneemann/parser2/value"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func Test_rk45(t *testing.T) {
+	tests := []struct {
+		name     string
+		hMax     float64
+		maxSteps int
+	}{
+		{"small", 0.01, 105},
+		{"large", 10, 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := Vector{1, 0}
+			// harmonic oscillator x''=-x
+			steps, err := rk45(func(t float64, x, dx Vector) error {
+				dx[0] = x[1]
+				dx[1] = -x[0]
+				return nil
+			}, x, 1, tt.hMax, func(tt float64, x Vector) error {
+				assert.InDelta(t, math.Cos(tt), x[0], 1e-5)
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, steps, tt.maxSteps)
+			assert.InDelta(t, math.Cos(1), x[0], 1e-5)
+			assert.InDelta(t, -math.Sin(1), x[1], 1e-5)
+		})
+	}
+}
+
+func TestLinear_SimulateAdaptive(t *testing.T) {
+	// fast pole at -1000 and a slow pole at -1
+	lin := &Linear{Numerator: Polynomial{1000}, Denominator: Polynomial{1000, 1001, 1}}
+	list, steps, err := lin.SimulateAdaptive(10, 0, func(t float64) (float64, error) { return 1, nil })
+	assert.NoError(t, err)
+
+	points, err := list.ToSlice(funcGen.NewEmptyStack[value.Value]())
+	assert.NoError(t, err)
+	assert.Equal(t, steps+1, len(points))
+	// the fast pole limits the step width, but far fewer steps than with euler are required
+	assert.Less(t, steps, 5000)
+	for _, p := range points {
+		v := p.(graph.Vector3d)
+		exact := 1 - (1000*math.Exp(-v.X)-math.Exp(-1000*v.X))/999
+		assert.InDelta(t, exact, v.Y, 1e-4)
+	}
+}
@@ -21,12 +21,17 @@ import (
 )
 
 var (
-	ComplexValueType      value.Type
-	PolynomialValueType   value.Type
-	LinearValueType       value.Type
-	BlockFactoryValueType value.Type
-	TwoPortValueType      value.Type
-	GuiElementsType       value.Type
+	ComplexValueType        value.Type
+	PolynomialValueType     value.Type
+	LinearValueType         value.Type
+	BlockFactoryValueType   value.Type
+	TwoPortValueType        value.Type
+	GuiElementsType         value.Type
+	MatrixValueType         value.Type
+	StateSpaceValueType     value.Type
+	DiscreteValueType       value.Type
+	SymbolicValueType       value.Type
+	TransferMatrixValueType value.Type
 )
 
 type BlockFactoryValue struct {
@@ -281,7 +286,95 @@ func polyMethods() value.MethodMap {
 			}
 			return rootsAsValueList(r), nil
 		}).SetMethodDescription("Returns the roots of the polynomial."),
-		"bode": createBodeMethod(func(poly Polynomial) *Linear { return &Linear{Numerator: poly, Denominator: Polynomial{1}} }),
+		"rootInfo": value.MethodAtType(0, func(pol Polynomial, st funcGen.Stack[value.Value]) (value.Value, error) {
+			info, err := pol.RootInfo()
+			if err != nil {
+				return nil, err
+			}
+			return value.NewListConvert(func(ri RootInfo) (value.Value, error) {
+				return value.NewMap(value.RealMap{
+					"root":         Complex(ri.Root),
+					"multiplicity": value.Int(ri.Multiplicity),
+					"error":        value.Float(ri.Error),
+				}), nil
+			}, info), nil
+		}).SetMethodDescription("Returns a list of maps containing the 'root', its 'multiplicity' and an estimate of its 'error'. " +
+			"Of a complex conjugate pair only the root with the positive imaginary part is contained."),
+		"bode": createBodeMethod(func(poly Polynomial) (*Linear, error) {
+			return &Linear{Numerator: poly, Denominator: Polynomial{1}}, nil
+		}),
+		"jury": value.MethodAtType(0, func(pol Polynomial, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return juryValue(pol)
+		}).SetMethodDescription("Checks by the Jury stability criterion if all roots are inside the unit circle. " +
+			"Returns a map containing the boolean 'stable' and the Jury 'table'."),
+		"routh": value.MethodAtType(0, func(pol Polynomial, st funcGen.Stack[value.Value]) (value.Value, error) {
+			rt, err := pol.Routh()
+			if err != nil {
+				return nil, err
+			}
+			latex := rt.LaTeX()
+			return value.NewMap(value.RealMap{
+				"stable": value.Bool(rt.Stable()),
+				"rhp":    value.Int(rt.SignChanges()),
+				"origin": value.Bool(rt.RootAtOrigin()),
+				"table": value.NewListConvert(func(row []float64) (value.Value, error) {
+					return value.NewListConvert(func(f float64) (value.Value, error) {
+						return value.Float(f), nil
+					}, row), nil
+				}, rt.Rows),
+				"latex":   value.String(latex),
+				"formula": value.String("$$$" + latex + "$"),
+			}), nil
+		}).SetMethodDescription("Creates the Routh array of the polynomial. " +
+			"Returns a map containing the boolean 'stable', the number 'rhp' of roots in the right half plane, the boolean " +
+			"'origin' which is true if there is a root at s=0, the Routh 'table' and the table as 'formula'. " +
+			"A zero in the first column is replaced by a small positive ε, a row of zeros by the derivative of the " +
+			"auxiliary polynomial. A zero in the last row is kept, it indicates a root at the origin."),
+		"div": value.MethodAtType(1, func(pol Polynomial, st funcGen.Stack[value.Value]) (value.Value, error) {
+			q, err := getPolynomial(st, 1)
+			if err != nil {
+				return nil, err
+			}
+			quot, rem, err := pol.Div(q)
+			if err != nil {
+				return nil, err
+			}
+			return value.NewMap(value.RealMap{
+				"quotient":  quot,
+				"remainder": rem,
+			}), nil
+		}).SetMethodDescription("q", "Divides the polynomial by q. Returns a map containing the 'quotient' and the 'remainder'."),
+		"gcd": value.MethodAtType(2, func(pol Polynomial, st funcGen.Stack[value.Value]) (value.Value, error) {
+			q, err := getPolynomial(st, 1)
+			if err != nil {
+				return nil, err
+			}
+			tol, ok := st.GetOptional(2, value.Float(1e-9)).ToFloat()
+			if !ok {
+				return nil, errors.New("gcd requires a float as tolerance")
+			}
+			return pol.GCD(q, tol)
+		}).SetMethodDescription("q", "tol", "Returns the monic greatest common divisor of the polynomial and q. "+
+			"A common divisor is accepted if both polynomials can be factored with a relative error less than tol. "+
+			"The default tolerance is 1e-9.").VarArgsMethod(1, 2),
+		"sylvester": value.MethodAtType(1, func(pol Polynomial, st funcGen.Stack[value.Value]) (value.Value, error) {
+			q, err := getPolynomial(st, 1)
+			if err != nil {
+				return nil, err
+			}
+			return Sylvester(pol, q), nil
+		}).SetMethodDescription("q", "Returns the Sylvester matrix of the polynomial and q."),
+		"resultant": value.MethodAtType(1, func(pol Polynomial, st funcGen.Stack[value.Value]) (value.Value, error) {
+			q, err := getPolynomial(st, 1)
+			if err != nil {
+				return nil, err
+			}
+			r, err := Resultant(pol, q)
+			if err != nil {
+				return nil, err
+			}
+			return value.Float(r), nil
+		}).SetMethodDescription("q", "Returns the resultant of the polynomial and q, which is zero if both have a common root."),
 		"toLaTeX": value.MethodAtType(0, func(pol Polynomial, st funcGen.Stack[value.Value]) (value.Value, error) {
 			var b bytes.Buffer
 			pol.ToLaTeX(&b)
@@ -312,6 +405,59 @@ func rootsAsValueList(r Roots) value.Value {
 	return value.NewList(val...)
 }
 
+func complexValue(c complex128) value.Value {
+	if imag(c) == 0 {
+		return value.Float(real(c))
+	}
+	return Complex(c)
+}
+
+func (pf *PartialFractions) toMap() value.Value {
+	var res, poles, power []value.Value
+	for _, t := range pf.Terms {
+		res = append(res, complexValue(t.Residue))
+		poles = append(poles, complexValue(t.Pole))
+		power = append(power, value.Int(t.Power))
+	}
+	return value.NewMap(value.RealMap{
+		"residues": value.NewList(res...),
+		"poles":    value.NewList(poles...),
+		"power":    value.NewList(power...),
+		"direct":   pf.Direct,
+		"delay":    value.Float(pf.Delay),
+	})
+}
+
+func (cl *ControlLoop) toMap() value.Value {
+	return value.NewMap(value.RealMap{
+		"L":  cl.L,
+		"S":  cl.S,
+		"T":  cl.T,
+		"GS": cl.GS,
+		"KS": cl.KS,
+		"RY": cl.RY,
+		"NY": cl.NY,
+	})
+}
+
+func (pf *PartialFractions) toTimeResponse() value.Value {
+	latex := pf.LaTeX()
+	return value.NewMap(value.RealMap{
+		"f": value.Closure(funcGen.Function[value.Value]{
+			Func: func(stack funcGen.Stack[value.Value], _ []value.Value) (value.Value, error) {
+				if t, ok := stack.Get(0).ToFloat(); ok {
+					return value.Float(pf.Eval(t)), nil
+				}
+				return nil, errors.New("the time response requires a float as argument")
+			},
+			Args:   1,
+			IsPure: true,
+		}),
+		"latex":   value.String(latex),
+		"formula": value.String("$$$" + latex + "$"),
+	})
+}
+
 func (l *Linear) ToList() (*value.List, bool) {
 	return nil, false
 }
@@ -346,8 +492,31 @@ func linMethods() value.MethodMap {
 			return grParser.ChartContentValue{Holder: grParser.Holder[graph.ChartContent]{Value: gf}}, nil
 		}).SetMethodDescription("Returns the graph (ℝ→ℝ) of the linear system."),
 		"loop": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
-			return lin.Loop(), nil
-		}).SetMethodDescription("Closes the loop. Calculates the closed loop transfer function G/(G+1)=N/(N+D)."),
+			return lin.Loop()
+		}).SetMethodDescription("Closes the loop. Calculates the closed loop transfer function G/(G+1)=N/(N+D). " +
+			"Not possible if the system contains a dead time."),
+		"feedback": value.MethodAtType(2, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			h, ok := getLinear(st, 1)
+			if !ok {
+				return nil, fmt.Errorf("feedback requires a linear system as first argument")
+			}
+			if sign, ok := st.GetOptional(2, value.Float(-1)).ToFloat(); ok {
+				return lin.Feedback(h, sign)
+			}
+			return nil, fmt.Errorf("feedback requires a float as second argument")
+		}).SetMethodDescription("H", "sign", "Closes the loop with the system H in the feedback path. "+
+			"By default a negative feedback G/(1+GH) is used. If sign is positive, a positive feedback is used. "+
+			"A sign of zero is rejected.").VarArgsMethod(1, 2),
+		"pade": value.MethodAtType(1, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			if n, ok := st.Get(1).(value.Int); ok {
+				return lin.Pade(int(n))
+			}
+			return nil, fmt.Errorf("pade requires an int as argument")
+		}).SetMethodDescription("n", "Replaces the dead time by its Padé approximation of order n. "+
+			"The result is a rational transfer function which can be used e.g. for the root locus."),
+		"delay": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.Float(lin.Delay), nil
+		}).SetMethodDescription("Returns the dead time of the linear system."),
 		"derivative": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
 			return lin.Derivative(), nil
 		}).SetMethodDescription("Calculates the derivative of the transfer function."),
@@ -377,9 +546,28 @@ func linMethods() value.MethodMap {
 		"pzForm": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
 			return lin.PZForm()
 		}).SetMethodDescription("Returns the pole-zero form of the linear system."),
-		"reduce": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+		"controllableForm": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return lin.ControllableForm()
+		}).SetMethodDescription("Returns the controllable canonical form of the linear system as a state space system."),
+		"observableForm": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return lin.ObservableForm()
+		}).SetMethodDescription("Returns the observable canonical form of the linear system as a state space system."),
+		"modalForm": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return lin.ModalForm()
+		}).SetMethodDescription("Returns the modal canonical form of the linear system as a state space system. " +
+			"Complex poles lead to 2x2 blocks, repeated poles to jordan blocks."),
+		"reduce": value.MethodAtType(1, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			if st.Size() > 1 {
+				tol, ok := st.Get(1).ToFloat()
+				if !ok {
+					return nil, errors.New("reduce requires a float as tolerance")
+				}
+				return lin.Cancel(tol)
+			}
 			return lin.Reduce()
-		}).SetMethodDescription("Reduces the linear system."),
+		}).SetMethodDescription("tol", "Reduces the linear system. If a tolerance is given, the common factors of numerator "+
+			"and denominator are cancelled by a gcd calculation. In this case, nearly identical pole zero pairs "+
+			"are cancelled if the relative error is less than the tolerance.").VarArgsMethod(0, 1),
 		"normalize": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
 			return lin.Normalize()
 		}).SetMethodDescription("Normalizes the linear system. The denominator is divided by its leading coefficient, " +
@@ -391,7 +579,7 @@ func linMethods() value.MethodMap {
 		"string": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
 			return value.String(lin.String()), nil
 		}).SetMethodDescription("Creates a string representation of the linear system."),
-		"bode": createBodeMethod(func(lin *Linear) *Linear { return lin }),
+		"bode": createBodeMethod(func(lin *Linear) (*Linear, error) { return lin, nil }),
 		"evans": value.MethodAtType(2, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
 			if k, ok := st.Get(1).ToFloat(); ok {
 				var kMin, kMax float64
@@ -441,6 +629,59 @@ func linMethods() value.MethodMap {
 			}, contentList), nil
 		}).SetMethodDescription("neg", "wMax", "wMin", "steps", "Creates a nyquist chart content. If neg is true also the range -∞<ω<0 is included. "+
 			"The value wMax gives the maximum value for ω. It defaults to 1000rad/s.").VarArgsMethod(0, 4),
+		"nyquistCriterion": value.MethodAtType(1, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			neg, ok := st.GetOptional(1, value.Bool(true)).(value.Bool)
+			if !ok {
+				return nil, fmt.Errorf("nyquistCriterion requires a boolean as first argument")
+			}
+			nc, err := lin.NyquistCriterion()
+			if err != nil {
+				return nil, err
+			}
+			arrows := lin.NyquistArrows(graph.Black.SetStrokeWidth(2), bool(neg))
+			return value.NewMap(value.RealMap{
+				"P":         value.Int(nc.P),
+				"N":         value.Int(nc.N),
+				"Z":         value.Int(nc.Z),
+				"imagPoles": value.Int(nc.ImagPoles),
+				"stable":    value.Bool(nc.Stable()),
+				"text":      value.String(nc.Explanation()),
+				"arrows": value.NewListConvert(func(i graph.ChartContent) (value.Value, error) {
+					return grParser.NewChartContentValue(i, setImReLabels), nil
+				}, arrows),
+			}), nil
+		}).SetMethodDescription("neg", "Applies the nyquist stability criterion to the open loop. "+
+			"Counts the clockwise encirclements N of the critical point -1, where poles on the imaginary axis are bypassed on the right. "+
+			"Returns a map containing the number 'P' of open loop poles in the right half plane, 'N', the number 'Z'=N+P "+
+			"of closed loop poles in the right half plane, the boolean 'stable', an explanation 'text' and the "+
+			"'arrows' which show the direction of the nyquist curve. If neg is false, no arrows are created for ω<0.").VarArgsMethod(0, 1),
+		"nichols": value.MethodAtType(4, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			style, err := grParser.GetStyle(st, 1, graph.Black)
+			if err != nil {
+				return nil, fmt.Errorf("nichols: %w", err)
+			}
+			title, ok := st.GetOptional(2, value.String("")).(value.String)
+			if !ok {
+				return nil, fmt.Errorf("nichols requires a string as second argument")
+			}
+			grid, ok := st.GetOptional(3, value.Bool(true)).(value.Bool)
+			if !ok {
+				return nil, fmt.Errorf("nichols requires a boolean as third argument")
+			}
+			wMax, ok := st.GetOptional(4, value.Float(0)).ToFloat()
+			if !ok {
+				return nil, fmt.Errorf("nichols requires a float as fourth argument")
+			}
+			contentList := lin.Nichols(style.Value, string(title), 0, wMax)
+			if grid {
+				contentList = append([]graph.ChartContent{NicholsGrid{Style: graph.Gray.SetStrokeWidth(0.5)}}, contentList...)
+			}
+			return value.NewListConvert(func(i graph.ChartContent) (value.Value, error) {
+				return grParser.NewChartContentValue(i, nicholsInitializer), nil
+			}, contentList), nil
+		}).SetMethodDescription("color", "title", "grid", "wMax", "Creates a Nichols chart content, which shows the amplitude "+
+			"of the open loop in dB versus its phase. If grid is true (default), the contours of constant magnitude and phase "+
+			"of the closed loop are added. The value wMax gives the maximum value for ω.").VarArgsMethod(0, 4),
 		"nyquistPos": value.MethodAtType(3, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
 			sMax, ok := st.GetOptional(1, value.Float(0)).ToFloat()
 			if !ok {
@@ -497,78 +738,1085 @@ func linMethods() value.MethodMap {
 				"gMargin": value.Float(margin),
 			}), err
 		}).SetMethodDescription("Returns the frequency ωₘ and the gain margin kₘ with kₘG(jωₘ)=-1. The gain margin kₘ is given in dB."),
-		"simStep": value.MethodAtType(2, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
-			if tMax, ok := st.Get(1).ToFloat(); ok {
-				dt := 0.0
-				if adt, ok := st.GetOptional(2, value.Float(0)).ToFloat(); ok {
-					dt = adt
-				} else {
-					return nil, fmt.Errorf("simStep requires a float as second argument")
+		"pMargins": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			margins, err := lin.PMargins()
+			if err != nil {
+				return nil, err
+			}
+			return value.NewListConvert(func(m Margin) (value.Value, error) {
+				return value.NewMap(value.RealMap{
+					"w0":      value.Float(m.W),
+					"pMargin": value.Float(m.Margin),
+				}), nil
+			}, margins), nil
+		}).SetMethodDescription("Returns a list of all crossover frequencies ω₀ with |G(jω₀)|=1 and the phase margins given in degrees."),
+		"gMargins": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			margins, err := lin.GMargins()
+			if err != nil {
+				return nil, err
+			}
+			return value.NewListConvert(func(m Margin) (value.Value, error) {
+				return value.NewMap(value.RealMap{
+					"w180":    value.Float(m.W),
+					"gMargin": value.Float(m.Margin),
+				}), nil
+			}, margins), nil
+		}).SetMethodDescription("Returns a list of the frequencies ωₘ at which the phase is -180° and the gain margins given in dB. " +
+			"Margins which are larger than 20dB and also more than 20dB larger than the smallest margin are omitted, " +
+			"so that a dead time does not create an endless list."),
+		"modulusMargin": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			m, err := lin.ModulusMargin()
+			if err != nil {
+				return nil, err
+			}
+			return value.NewMap(value.RealMap{
+				"w":             value.Float(m.W),
+				"modulusMargin": value.Float(m.Margin),
+				"Ms":            value.Float(1 / m.Margin),
+			}), nil
+		}).SetMethodDescription("Returns the minimal distance 'modulusMargin' of the nyquist curve to the critical point -1, " +
+			"the frequency 'w' at which it is reached and the maximum 'Ms' of the sensitivity."),
+		"delayMargin": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			m, err := lin.DelayMargin()
+			if err != nil {
+				return nil, err
+			}
+			return value.NewMap(value.RealMap{
+				"w0":          value.Float(m.W),
+				"delayMargin": value.Float(m.Margin),
+			}), nil
+		}).SetMethodDescription("Returns the smallest additional dead time 'delayMargin' which makes the closed loop unstable " +
+			"and the crossover frequency 'w0' at which it is determined."),
+		"sensitivity": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return lin.Sensitivity()
+		}).SetMethodDescription("Returns the sensitivity function S=1/(1+G)."),
+		"compSensitivity": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return lin.CompSensitivity()
+		}).SetMethodDescription("Returns the complementary sensitivity function T=G/(1+G). This is the closed loop transfer function."),
+		"dcGain": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.Float(lin.DCGain()), nil
+		}).SetMethodDescription("Returns the static gain G(0). If there is a pole at s=0, the gain is infinite."),
+		"bandwidth": value.MethodAtType(1, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			dB, ok := st.GetOptional(1, value.Float(3)).ToFloat()
+			if !ok {
+				return nil, fmt.Errorf("bandwidth requires a float as argument")
+			}
+			wb, err := lin.Bandwidth(dB)
+			if err != nil {
+				return nil, err
+			}
+			return value.NewMap(value.RealMap{
+				"wb": value.Float(wb),
+				"dB": value.Float(-math.Abs(dB)),
+			}), nil
+		}).SetMethodDescription("dB", "Returns the frequency 'wb' at which the amplitude drops by the given value in dB below the dc gain. "+
+			"The value defaults to 3dB.").VarArgsMethod(0, 1),
+		"resonance": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			wr, mr, err := lin.Resonance()
+			if err != nil {
+				return nil, err
+			}
+			return value.NewMap(value.RealMap{
+				"wr":   value.Float(wr),
+				"Mr":   value.Float(mr),
+				"MrdB": value.Float(20 * math.Log10(mr)),
+			}), nil
+		}).SetMethodDescription("Returns the resonance frequency 'wr' and the peak amplitude 'Mr' which is also given in dB as 'MrdB'. " +
+			"If there is no resonance, wr is zero. Poles on the imaginary axis are rejected."),
+		"naturalFrequencies": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			nf, err := lin.NaturalFrequencies()
+			if err != nil {
+				return nil, err
+			}
+			return value.NewListConvert(func(n NaturalFrequency) (value.Value, error) {
+				return value.NewMap(value.RealMap{
+					"pole": Complex(n.Pole),
+					"wn":   value.Float(n.Wn),
+					"zeta": value.Float(n.Zeta),
+				}), nil
+			}, nf), nil
+		}).SetMethodDescription("Returns a list containing the natural frequency 'wn' and the damping ratio 'zeta' of every complex pole pair."),
+		"hankelSingularValues": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			hsv, err := lin.HankelSingularValues()
+			if err != nil {
+				return nil, err
+			}
+			return floatList(hsv), nil
+		}).SetMethodDescription("Returns the hankel singular values of a stable system in descending order. " +
+			"A system with dead time is rejected, the pade approximation can be used instead."),
+		"reduceOrder": value.MethodAtType(2, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			if n, ok := st.Get(1).(value.Int); ok {
+				method, err := getReduction(st, 2)
+				if err != nil {
+					return nil, err
 				}
-				return lin.Simulate(tMax, dt, func(t float64) (float64, error) {
-					if t < 0 {
-						return 0, nil
+				return lin.ReduceOrder(int(n), method)
+			}
+			return nil, fmt.Errorf("reduceOrder requires an int as argument")
+		}).SetMethodDescription("n", "method", "Reduces the order of a stable system to n using the balanced realization. "+
+			"The method can be 'truncate' (default) or 'residualize'. The truncation matches the system at high frequencies, "+
+			"the residualization keeps the static gain.").VarArgsMethod(1, 2),
+		"simStep":     createSimStepMethod[*Linear]("It does not close the loop! If the closed control loop is to be simulated, the instruction is G.loop().simStep(10). ", false),
+		"sim":         createSimMethod[*Linear]("It does not close the loop! If the closed control loop is to be simulated, the instruction is G.loop().sim(t->sin(t), 10). ", false),
+		"simStepInfo": createSimStepMethod[*Linear]("", true),
+		"simInfo":     createSimMethod[*Linear]("", true),
+		"c2d": value.MethodAtType(2, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			if T, ok := st.Get(1).ToFloat(); ok {
+				method, err := getDiscretization(st, 2)
+				if err != nil {
+					return nil, err
+				}
+				return lin.C2D(T, method)
+			}
+			return nil, fmt.Errorf("c2d requires a float as first argument")
+		}).SetMethodDescription("T", "method", "Converts the system to a discrete time system with the sample time T. "+
+			"The method can be 'zoh' (zero order hold, default), 'tustin' or 'matched'.").VarArgsMethod(1, 2),
+		"residues": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			pf, err := lin.Residues()
+			if err != nil {
+				return nil, err
+			}
+			return pf.toMap(), nil
+		}).SetMethodDescription("Returns the partial fraction expansion of the transfer function. " +
+			"The returned map contains the lists 'residues', 'poles' and 'power', the polynomial 'direct' and the dead time 'delay'. " +
+			"The i-th term of the expansion is residues[i]/(s-poles[i])^power[i]."),
+		"stepAnalytic": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			pf, err := lin.StepAnalytic()
+			if err != nil {
+				return nil, err
+			}
+			return pf.toTimeResponse(), nil
+		}).SetMethodDescription("Calculates the exact step response by a partial fraction expansion. " +
+			"Returns a map containing the function 'f' of time, the LaTeX string 'latex' and the 'formula' which is rendered as MathML."),
+		"impulseAnalytic": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			pf, err := lin.ImpulseAnalytic()
+			if err != nil {
+				return nil, err
+			}
+			return pf.toTimeResponse(), nil
+		}).SetMethodDescription("Calculates the exact impulse response by a partial fraction expansion. " +
+			"Returns a map containing the function 'f' of time, the LaTeX string 'latex' and the 'formula' which is rendered as MathML. " +
+			"A dirac impulse is contained in the formula but not in the function."),
+		"stepInfo": value.MethodAtType(1, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			band, ok := st.GetOptional(1, value.Float(0.02)).ToFloat()
+			if !ok {
+				return nil, errors.New("stepInfo requires a float as band")
+			}
+			si, err := lin.StepInfo(band)
+			if err != nil {
+				return nil, err
+			}
+			return si.toMap(band), nil
+		}).SetMethodDescription("band", "Calculates the characteristic values of the step response. "+stepInfoDescription).VarArgsMethod(0, 1),
+		"toLaTeX": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			var b bytes.Buffer
+			lin.ToLaTeX(&b)
+			return value.String(b.String()), nil
+		}).SetMethodDescription("Returns a LaTeX representation of the linear system."),
+		"toUnicode": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.String(lin.ToUnicode()), nil
+		}).SetMethodDescription("Returns a unicode string representation of the linear system."),
+	}
+}
+
+type bodeCreator interface {
+	CreateBodeContent(style *graph.Style, title string, steps int, latency float64) []value.Value
+}
+
+func createBodeMethod[T value.Value, B bodeCreator](convert func(T) (B, error)) funcGen.Function[value.Value] {
+	return value.MethodAtType(4, func(lin T, st funcGen.Stack[value.Value]) (value.Value, error) {
+		if style, err := grParser.GetStyle(st, 1, graph.Black); err == nil {
+			if title, ok := st.GetOptional(2, value.String("")).(value.String); ok {
+				if latency, ok := st.GetOptional(3, value.Float(0)).ToFloat(); ok {
+					if steps, ok := st.GetOptional(4, value.Int(0)).(value.Int); ok {
+						l, err := convert(lin)
+						if err != nil {
+							return nil, err
+						}
+						contentList := l.CreateBodeContent(style.Value, string(title), int(steps), latency)
+						return value.NewList(contentList...), nil
 					}
-					return 1, nil
-				})
-			}
-			return nil, fmt.Errorf("sim requires a float")
-		}).SetMethodDescription("tMax", "dt", "Simulates the transfer function with the step function as input signal. "+
-			"It does not close the loop! If the closed control loop is to be simulated, the instruction is G.loop().simStep(10). "+
-			"The value tMax gives the maximum time for the simulation. The value dt is the step with which defaults to 1e-5.").VarArgsMethod(1, 2),
-		"sim": value.MethodAtType(3, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+				}
+			}
+		}
+		return nil, fmt.Errorf("bode requires a color, a string and an int as arguments")
+	}).SetMethodDescription("color", "title", "latency", "steps", "Creates a bode chart content.").VarArgsMethod(0, 4)
+}
+
+func matrixMethods() value.MethodMap {
+	return value.MethodMap{
+		"rows": value.MethodAtType(0, func(m Matrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.Int(m.Rows()), nil
+		}).SetMethodDescription("Returns the number of rows."),
+		"cols": value.MethodAtType(0, func(m Matrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.Int(m.Cols()), nil
+		}).SetMethodDescription("Returns the number of columns."),
+		"transpose": value.MethodAtType(0, func(m Matrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return m.Transpose(), nil
+		}).SetMethodDescription("Returns the transposed matrix."),
+		"inverse": value.MethodAtType(0, func(m Matrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return m.Inverse()
+		}).SetMethodDescription("Returns the inverse of the matrix."),
+		"det": value.MethodAtType(0, func(m Matrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			d, err := m.Det()
+			return value.Float(d), err
+		}).SetMethodDescription("Returns the determinant of the matrix."),
+		"rank": value.MethodAtType(0, func(m Matrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.Int(m.Rank()), nil
+		}).SetMethodDescription("Returns the rank of the matrix."),
+		"charPoly": value.MethodAtType(0, func(m Matrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return m.CharPoly()
+		}).SetMethodDescription("Returns the characteristic polynomial det(sI-A) of the matrix."),
+		"eigenvalues": value.MethodAtType(0, func(m Matrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			ev, err := m.Eigenvalues()
+			if err != nil {
+				return nil, err
+			}
+			return rootsAsValueList(ev), nil
+		}).SetMethodDescription("Returns the eigenvalues of the matrix."),
+		"string": value.MethodAtType(0, func(m Matrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.String(m.String()), nil
+		}).SetMethodDescription("Returns a string representation of the matrix."),
+	}
+}
+
+func stateSpaceMethods() value.MethodMap {
+	return value.MethodMap{
+		"tf": value.MethodAtType(0, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			if sys.isSISO() {
+				return sys.Linear()
+			}
+			return sys.TransferMatrix()
+		}).SetMethodDescription("Returns the transfer function of the system. If the system has several inputs " +
+			"or outputs, a transfer matrix is returned."),
+		"poles": value.MethodAtType(0, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			poles, err := sys.Poles()
+			if err != nil {
+				return nil, err
+			}
+			return rootsAsValueList(poles), nil
+		}).SetMethodDescription("Returns the poles of the system which are the eigenvalues of the matrix A."),
+		"order": value.MethodAtType(0, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.Int(sys.Order()), nil
+		}).SetMethodDescription("Returns the number of states of the system."),
+		"series": value.MethodAtType(1, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			o, err := getStateSpace(st, 1)
+			if err != nil {
+				return nil, err
+			}
+			return sys.Series(o)
+		}).SetMethodDescription("sys", "Connects the given system in series. The output of this system is the input of the given system."),
+		"parallel": value.MethodAtType(1, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			o, err := getStateSpace(st, 1)
+			if err != nil {
+				return nil, err
+			}
+			return sys.Parallel(o)
+		}).SetMethodDescription("sys", "Connects the given system in parallel. The outputs of both systems are added."),
+		"feedback": value.MethodAtType(2, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			o, err := getStateSpace(st, 1)
+			if err != nil {
+				return nil, err
+			}
+			if sign, ok := st.GetOptional(2, value.Float(-1)).ToFloat(); ok {
+				return sys.Feedback(o, sign)
+			}
+			return nil, fmt.Errorf("feedback requires a float as second argument")
+		}).SetMethodDescription("sys", "sign", "Closes the loop with the given system in the feedback path. "+
+			"By default a negative feedback is used. If sign is positive, a positive feedback is used. "+
+			"A sign of zero is rejected.").VarArgsMethod(1, 2),
+		"bode":        createBodeMethod(func(sys *StateSpace) (*Linear, error) { return sys.Linear() }),
+		"simStep":     createSimStepMethod[*StateSpace]("", false),
+		"sim":         createSimMethod[*StateSpace]("", false),
+		"simStepInfo": createSimStepMethod[*StateSpace]("", true),
+		"simInfo":     createSimMethod[*StateSpace]("", true),
+		"controllability": value.MethodAtType(0, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return sys.Controllability(), nil
+		}).SetMethodDescription("Returns the controllability matrix [B, AB, ..., Aⁿ⁻¹B]."),
+		"observability": value.MethodAtType(0, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return sys.Observability(), nil
+		}).SetMethodDescription("Returns the observability matrix [C; CA; ...; CAⁿ⁻¹]."),
+		"isControllable": value.MethodAtType(0, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.Bool(sys.IsControllable()), nil
+		}).SetMethodDescription("Returns true if the controllability matrix has full rank."),
+		"isObservable": value.MethodAtType(0, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.Bool(sys.IsObservable()), nil
+		}).SetMethodDescription("Returns true if the observability matrix has full rank."),
+		"transform": value.MethodAtType(1, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			t, err := ToMatrix(st, st.Get(1))
+			if err != nil {
+				return nil, err
+			}
+			return sys.Transform(t)
+		}).SetMethodDescription("T", "Transforms the system to the new state x̃=T⁻¹x."),
+		"kalmanDecomposition": value.MethodAtType(0, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			kd, err := sys.KalmanDecomposition()
+			if err != nil {
+				return nil, err
+			}
+			return value.NewMap(value.RealMap{
+				"sys": kd.System,
+				"T":   kd.T,
+				"dims": value.NewListConvert(func(i int) (value.Value, error) {
+					return value.Int(i), nil
+				}, kd.Dims[:]),
+			}), nil
+		}).SetMethodDescription("Calculates the Kalman decomposition. Returns a map containing the transformed system 'sys', " +
+			"the transformation matrix 'T' and the list 'dims' containing the number of states which are controllable and observable, " +
+			"controllable and not observable, not controllable and observable and neither controllable nor observable."),
+		"controllableForm": value.MethodAtType(0, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return sys.ControllableForm()
+		}).SetMethodDescription("Returns the controllable canonical form of the system."),
+		"observableForm": value.MethodAtType(0, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return sys.ObservableForm()
+		}).SetMethodDescription("Returns the observable canonical form of the system."),
+		"modalForm": value.MethodAtType(0, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return sys.ModalForm()
+		}).SetMethodDescription("Returns the modal canonical form of the system. Complex poles lead to 2x2 blocks, repeated poles to jordan blocks."),
+		"gramians": value.MethodAtType(0, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			wc, wo, err := sys.Gramians()
+			if err != nil {
+				return nil, err
+			}
+			return value.NewMap(value.RealMap{"Wc": wc, "Wo": wo}), nil
+		}).SetMethodDescription("Returns a map containing the controllability gramian 'Wc' and the observability gramian 'Wo' of a stable system."),
+		"hankelSingularValues": value.MethodAtType(0, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			hsv, err := sys.HankelSingularValues()
+			if err != nil {
+				return nil, err
+			}
+			return floatList(hsv), nil
+		}).SetMethodDescription("Returns the hankel singular values of a stable system in descending order."),
+		"balance": value.MethodAtType(0, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return sys.Balance()
+		}).SetMethodDescription("Returns the balanced realization of a stable and minimal system. " +
+			"Both gramians of the balanced system are equal to the diagonal matrix of the hankel singular values."),
+		"reduceOrder": value.MethodAtType(2, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			if n, ok := st.Get(1).(value.Int); ok {
+				method, err := getReduction(st, 2)
+				if err != nil {
+					return nil, err
+				}
+				return sys.Reduce(int(n), method)
+			}
+			return nil, fmt.Errorf("reduceOrder requires an int as argument")
+		}).SetMethodDescription("n", "method", "Reduces the order of a stable system to n using the balanced realization. "+
+			"The method can be 'truncate' (default) or 'residualize'.").VarArgsMethod(1, 2),
+		"string": value.MethodAtType(0, func(sys *StateSpace, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.String(sys.String()), nil
+		}).SetMethodDescription("Returns a string representation of the system."),
+	}
+}
+
+func getReduction(st funcGen.Stack[value.Value], index int) (ReductionMethod, error) {
+	name, ok := st.GetOptional(index, value.String("truncate")).(value.String)
+	if !ok {
+		return Truncation, fmt.Errorf("the method needs to be a string")
+	}
+	return ReductionByName(string(name))
+}
+
+func floatList(v Vector) *value.List {
+	return value.NewListConvert(func(f float64) (value.Value, error) {
+		return value.Float(f), nil
+	}, v)
+}
+
+func getDiscretization(st funcGen.Stack[value.Value], index int) (DiscretizationMethod, error) {
+	name, ok := st.GetOptional(index, value.String("zoh")).(value.String)
+	if !ok {
+		return ZOH, fmt.Errorf("the method needs to be a string")
+	}
+	return DiscretizationByName(string(name))
+}
+
+func discreteMethods() value.MethodMap {
+	return value.MethodMap{
+		"numerator": value.MethodAtType(0, func(d *Discrete, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return d.Numerator, nil
+		}).SetMethodDescription("Returns the numerator of the transfer function in z."),
+		"denominator": value.MethodAtType(0, func(d *Discrete, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return d.Denominator, nil
+		}).SetMethodDescription("Returns the denominator of the transfer function in z."),
+		"sampleTime": value.MethodAtType(0, func(d *Discrete, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.Float(d.T), nil
+		}).SetMethodDescription("Returns the sample time."),
+		"poles": value.MethodAtType(0, func(d *Discrete, st funcGen.Stack[value.Value]) (value.Value, error) {
+			poles, err := d.Poles()
+			if err != nil {
+				return nil, err
+			}
+			return rootsAsValueList(poles), nil
+		}).SetMethodDescription("Returns the poles of the transfer function."),
+		"zeros": value.MethodAtType(0, func(d *Discrete, st funcGen.Stack[value.Value]) (value.Value, error) {
+			zeros, err := d.Zeros()
+			if err != nil {
+				return nil, err
+			}
+			return rootsAsValueList(zeros), nil
+		}).SetMethodDescription("Returns the zeros of the transfer function."),
+		"isStable": value.MethodAtType(0, func(d *Discrete, st funcGen.Stack[value.Value]) (value.Value, error) {
+			stable, err := d.IsStable()
+			return value.Bool(stable), err
+		}).SetMethodDescription("Returns true if all poles are inside the unit circle."),
+		"jury": value.MethodAtType(0, func(d *Discrete, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return juryValue(d.Denominator)
+		}).SetMethodDescription("Checks the stability of the system by the Jury stability criterion. " +
+			"Returns a map containing the boolean 'stable' and the Jury 'table'."),
+		"loop": value.MethodAtType(0, func(d *Discrete, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return d.Loop(), nil
+		}).SetMethodDescription("Closes the loop with a negative unity feedback."),
+		"d2c": value.MethodAtType(1, func(d *Discrete, st funcGen.Stack[value.Value]) (value.Value, error) {
+			method, err := getDiscretization(st, 1)
+			if err != nil {
+				return nil, err
+			}
+			return d.D2C(method)
+		}).SetMethodDescription("method", "Converts the system to a continuous time system. "+
+			"The method can be 'zoh' (zero order hold, default), 'tustin' or 'matched'.").VarArgsMethod(0, 1),
+		"bode": createBodeMethod(func(d *Discrete) (*Discrete, error) { return d, nil }),
+		"pzMap": value.MethodAtType(0, func(d *Discrete, st funcGen.Stack[value.Value]) (value.Value, error) {
+			contentList, err := d.PZMap()
+			if err != nil {
+				return nil, err
+			}
+			return value.NewListConvert(func(i graph.ChartContent) (value.Value, error) {
+				return grParser.NewChartContentValue(i, setImReLabels), nil
+			}, contentList), nil
+		}).SetMethodDescription("Creates a chart content showing the poles and zeros together with the unit circle."),
+		"simStep": value.MethodAtType(1, func(d *Discrete, st funcGen.Stack[value.Value]) (value.Value, error) {
+			if tMax, ok := st.Get(1).ToFloat(); ok {
+				return d.Simulate(tMax, func(float64) (float64, error) { return 1, nil })
+			}
+			return nil, fmt.Errorf("simStep requires a float")
+		}).SetMethodDescription("tMax", "Calculates the step response by evaluating the difference equation. "+
+			"One point per sample is returned."),
+		"sim": value.MethodAtType(2, func(d *Discrete, st funcGen.Stack[value.Value]) (value.Value, error) {
 			if cl, ok := st.Get(1).(value.Closure); ok {
-				stack := funcGen.NewEmptyStack[value.Value]()
-				u := func(t float64) (float64, error) {
-					r, err := cl.Eval(stack, value.Float(t))
+				if tMax, ok := st.Get(2).ToFloat(); ok {
+					stack := funcGen.NewEmptyStack[value.Value]()
+					return d.Simulate(tMax, func(t float64) (float64, error) {
+						r, err := cl.Eval(stack, value.Float(t))
+						if err != nil {
+							return 0, err
+						}
+						if c, ok := r.ToFloat(); ok {
+							return c, nil
+						}
+						return 0, fmt.Errorf("u(t) needs to return a float")
+					})
+				}
+			}
+			return nil, fmt.Errorf("sim requires a function and a float")
+		}).SetMethodDescription("u(t)", "tMax", "Calculates the response to the input signal u(t) by evaluating the difference equation. "+
+			"The input signal is sampled at the multiples of the sample time. One point per sample is returned."),
+		"toLaTeX": value.MethodAtType(0, func(d *Discrete, st funcGen.Stack[value.Value]) (value.Value, error) {
+			var b bytes.Buffer
+			d.ToLaTeX(&b)
+			return value.String(b.String()), nil
+		}).SetMethodDescription("Returns a LaTeX representation of the transfer function."),
+		"string": value.MethodAtType(0, func(d *Discrete, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.String(d.String()), nil
+		}).SetMethodDescription("Returns a string representation of the system."),
+	}
+}
+
+// symbolicValue is implemented by the symbolic polynomials and the symbolic linear systems
+type symbolicValue interface {
+	value.Value
+	String() string
+	ToLaTeX(w *bytes.Buffer)
+	ToUnicode() string
+}
+
+func symMethods() value.MethodMap {
+	return value.MethodMap{
+		"subst": value.MethodAtType(2, func(sv symbolicValue, st funcGen.Stack[value.Value]) (value.Value, error) {
+			values, err := getSubstitution(st)
+			if err != nil {
+				return nil, err
+			}
+			lin, err := toSymLinear(sv)
+			if err != nil {
+				return nil, err
+			}
+			sl, err := lin.Subst(values)
+			if err != nil {
+				return nil, err
+			}
+			if l, ok := sl.Linear(); ok {
+				if sl.IsPolynomial() {
+					return l.Numerator, nil
+				}
+				return l, nil
+			}
+			return symLinValue(sl), nil
+		}).SetMethodDescription("name", "value", "Replaces the symbol by the given value. Instead of name and value a map "+
+			"containing the values of several symbols can be given. If no symbol is left, a polynomial or a linear system is returned.").VarArgsMethod(1, 2),
+		"symbols": value.MethodAtType(0, func(sv symbolicValue, st funcGen.Stack[value.Value]) (value.Value, error) {
+			lin, err := toSymLinear(sv)
+			if err != nil {
+				return nil, err
+			}
+			return symbolList(lin.Symbols()), nil
+		}).SetMethodDescription("Returns the names of the symbols contained in the expression."),
+		"degree": value.MethodAtType(0, func(sv symbolicValue, st funcGen.Stack[value.Value]) (value.Value, error) {
+			pol, ok := sv.(SymPolynomial)
+			if !ok {
+				return nil, errors.New("degree requires a symbolic polynomial")
+			}
+			return value.Int(pol.Degree()), nil
+		}).SetMethodDescription("Returns the degree of the polynomial in s. Only available for symbolic polynomials."),
+		"routh": value.MethodAtType(0, func(sv symbolicValue, st funcGen.Stack[value.Value]) (value.Value, error) {
+			pol, ok := sv.(SymPolynomial)
+			if !ok {
+				return nil, errors.New("routh requires a symbolic polynomial")
+			}
+			rt, err := pol.Routh()
+			if err != nil {
+				return nil, err
+			}
+			latex := rt.LaTeX()
+			m := value.RealMap{
+				"firstColumn": value.NewListConvert(func(l *SymLinear) (value.Value, error) {
+					return symLinValue(l), nil
+				}, rt.FirstColumn()),
+				"latex":   value.String(latex),
+				"formula": value.String("$$$" + latex + "$"),
+			}
+			if cpp, _, err := symCharPolyFunc(pol, pol.Symbols()); err == nil {
+				if ranges, err := RouthRange(cpp); err == nil {
+					m["ranges"] = value.NewListConvert(func(r [2]float64) (value.Value, error) {
+						return value.NewList(value.Float(r[0]), value.Float(r[1])), nil
+					}, ranges)
+				}
+			}
+			return value.NewMap(m), nil
+		}).SetMethodDescription("Creates the symbolic Routh array of the polynomial. Only available for symbolic polynomials. " +
+			"Returns a map containing the 'firstColumn' whose elements all need to be positive for a stable system " +
+			"and the table as 'formula'. If the coefficients depend linearly on a single symbol, " +
+			"the map also contains the 'ranges' of this symbol for which the polynomial is stable."),
+		"numerator": value.MethodAtType(0, func(sv symbolicValue, st funcGen.Stack[value.Value]) (value.Value, error) {
+			lin, err := toSymLinear(sv)
+			if err != nil {
+				return nil, err
+			}
+			return symPolyValue(lin.Numerator), nil
+		}).SetMethodDescription("Returns the numerator."),
+		"denominator": value.MethodAtType(0, func(sv symbolicValue, st funcGen.Stack[value.Value]) (value.Value, error) {
+			lin, err := toSymLinear(sv)
+			if err != nil {
+				return nil, err
+			}
+			return symPolyValue(lin.Denominator), nil
+		}).SetMethodDescription("Returns the denominator which is the characteristic polynomial of the system."),
+		"loop": value.MethodAtType(0, func(sv symbolicValue, st funcGen.Stack[value.Value]) (value.Value, error) {
+			lin, err := toSymLinear(sv)
+			if err != nil {
+				return nil, err
+			}
+			return symLinValue(lin.Loop()), nil
+		}).SetMethodDescription("Closes the loop. Calculates G/(G+1)."),
+		"toLaTeX": value.MethodAtType(0, func(sv symbolicValue, st funcGen.Stack[value.Value]) (value.Value, error) {
+			var b bytes.Buffer
+			sv.ToLaTeX(&b)
+			return value.String(b.String()), nil
+		}).SetMethodDescription("Returns a LaTeX representation of the expression."),
+		"string": value.MethodAtType(0, func(sv symbolicValue, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.String(sv.String()), nil
+		}).SetMethodDescription("Returns a string representation of the expression."),
+		"toUnicode": value.MethodAtType(0, func(sv symbolicValue, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.String(sv.ToUnicode()), nil
+		}).SetMethodDescription("Returns a unicode string representation of the expression."),
+	}
+}
+
+// symPolyValue returns a polynomial if the symbolic polynomial contains no symbols
+func symPolyValue(p SymPolynomial) value.Value {
+	if poly, ok := p.Polynomial(); ok {
+		return poly
+	}
+	return p
+}
+
+// symLinValue returns the numerator if the denominator is one
+func symLinValue(l *SymLinear) value.Value {
+	if l.IsPolynomial() {
+		return l.Numerator
+	}
+	return l
+}
+
+func symbolList(names []string) *value.List {
+	return value.NewListConvert(func(n string) (value.Value, error) {
+		return value.String(n), nil
+	}, names)
+}
+
+// getSubstitution reads the values of the symbols given either by
+// a name and a value or by a map
+func getSubstitution(st funcGen.Stack[value.Value]) (map[string]float64, error) {
+	values := map[string]float64{}
+	if m, ok := st.Get(1).(value.Map); ok {
+		var err error
+		m.Iter(func(key string, v value.Value) bool {
+			f, ok := v.ToFloat()
+			if !ok {
+				err = fmt.Errorf("the value of '%s' needs to be a number", key)
+				return false
+			}
+			values[key] = f
+			return true
+		})
+		return values, err
+	}
+	name, ok := st.Get(1).(value.String)
+	if !ok || st.Size() < 3 {
+		return nil, errors.New("subst requires a name and a value or a map")
+	}
+	f, ok := st.Get(2).ToFloat()
+	if !ok {
+		return nil, fmt.Errorf("the value of '%s' needs to be a number", name)
+	}
+	values[string(name)] = f
+	return values, nil
+}
+
+func juryValue(p Polynomial) (value.Value, error) {
+	stable, table, err := p.Jury()
+	if err != nil {
+		return nil, err
+	}
+	return value.NewMap(value.RealMap{
+		"stable": value.Bool(stable),
+		"table": value.NewListConvert(func(row []float64) (value.Value, error) {
+			return value.NewListConvert(func(f float64) (value.Value, error) {
+				return value.Float(f), nil
+			}, row), nil
+		}, table),
+	}), nil
+}
+
+func transferMatrixMethods() value.MethodMap {
+	return value.MethodMap{
+		"inputs": value.MethodAtType(0, func(t TransferMatrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.Int(t.Inputs()), nil
+		}).SetMethodDescription("Returns the number of inputs which is the number of columns."),
+		"outputs": value.MethodAtType(0, func(t TransferMatrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.Int(t.Outputs()), nil
+		}).SetMethodDescription("Returns the number of outputs which is the number of rows."),
+		"transpose": value.MethodAtType(0, func(t TransferMatrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return t.Transpose(), nil
+		}).SetMethodDescription("Returns the transposed transfer matrix."),
+		"feedback": value.MethodAtType(2, func(t TransferMatrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			h, err := toTransferMatrix(st, st.Get(1))
+			if err != nil {
+				return nil, err
+			}
+			if sign, ok := st.GetOptional(2, value.Float(-1)).ToFloat(); ok {
+				return t.Feedback(h, sign)
+			}
+			return nil, fmt.Errorf("feedback requires a float as second argument")
+		}).SetMethodDescription("H", "sign", "Closes the loop with the system H in the feedback path. "+
+			"By default a negative feedback is used. If sign is positive, a positive feedback is used. "+
+			"A sign of zero is rejected.").VarArgsMethod(1, 2),
+		"loop": value.MethodAtType(0, func(t TransferMatrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return t.Loop()
+		}).SetMethodDescription("Closes the loop with a unity negative feedback. Calculates (I+G)⁻¹G."),
+		"sim": value.MethodAtType(4, func(t TransferMatrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			u, err := getInputVector(st, st.Get(1), t.Inputs())
+			if err != nil {
+				return nil, err
+			}
+			if tMax, ok := st.Get(2).ToFloat(); ok {
+				if dt, ok := st.GetOptional(3, value.Float(0)).ToFloat(); ok {
+					solver, err := getSolver(st, 4)
 					if err != nil {
-						return 0, err
+						return nil, err
 					}
-					if c, ok := r.ToFloat(); ok {
-						return c, nil
-					} else {
-						return 0, fmt.Errorf("u(t) needs to return a float")
+					lists, _, err := t.SimulateVector(solver, tMax, dt, u)
+					if err != nil {
+						return nil, err
 					}
+					return value.NewListConvert(func(l *value.List) (value.Value, error) {
+						return l, nil
+					}, lists), nil
 				}
-				if tMax, ok := st.Get(2).ToFloat(); ok {
-					dt := 0.0
-					if adt, ok := st.GetOptional(3, value.Float(0)).ToFloat(); ok {
-						dt = adt
-					} else {
-						return nil, fmt.Errorf("sim requires a float as third argument")
+			}
+			return nil, fmt.Errorf("sim requires the input signals and a float")
+		}).SetMethodDescription("u", "tMax", "dt", "solver", "Simulates the system. The input u is either a list containing "+
+			"one function of time for each input or a function of time returning a list of input values. "+
+			"A list of point lists is returned, one for each output. "+simSolverDescription).VarArgsMethod(2, 4),
+		"sigma": value.MethodAtType(3, func(t TransferMatrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			if style, err := grParser.GetStyle(st, 1, graph.Black); err == nil {
+				if title, ok := st.GetOptional(2, value.String("")).(value.String); ok {
+					if steps, ok := st.GetOptional(3, value.Int(0)).(value.Int); ok {
+						return value.NewList(t.CreateSigmaContent(style.Value, string(title), int(steps))...), nil
 					}
-					return lin.Simulate(tMax, dt, u)
 				}
 			}
-			return nil, fmt.Errorf("sim requires a function and a float")
-		}).SetMethodDescription("u(t)", "tMax", "dt", "Simulates the transfer function with the input signal u(t) as input signal. "+
-			"It does not close the loop! If the closed control loop is to be simulated, the instruction is G.loop().sim(t->sin(t), 10). "+
-			"The value tMax gives the maximum time for the simulation. The value dt is the step with which defaults to 1e-5.").VarArgsMethod(2, 3),
-		"toLaTeX": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return nil, fmt.Errorf("sigma requires a color, a string and an int as arguments")
+		}).SetMethodDescription("color", "title", "steps", "Creates a chart content showing the singular values of G(jω) "+
+			"versus the frequency. The largest singular value is drawn as a solid line, the others are dashed.").VarArgsMethod(0, 3),
+		"dcGain": value.MethodAtType(0, func(t TransferMatrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return t.DCGain()
+		}).SetMethodDescription("Returns the matrix of static gains G(0)."),
+		"rga": value.MethodAtType(0, func(t TransferMatrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return t.RGA()
+		}).SetMethodDescription("Returns the relative gain array of the static gains. Pairings with elements close to one " +
+			"should be preferred, negative elements indicate pairings which should be avoided."),
+		"det": value.MethodAtType(0, func(t TransferMatrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return t.Det()
+		}).SetMethodDescription("Returns the determinant of a square transfer matrix."),
+		"zeros": value.MethodAtType(0, func(t TransferMatrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			z, err := t.Zeros()
+			if err != nil {
+				return nil, err
+			}
+			return rootsAsValueList(z), nil
+		}).SetMethodDescription("Returns the transmission zeros of a square system. These are the invariant zeros " +
+			"of a minimal state space realization."),
+		"ss": value.MethodAtType(0, func(t TransferMatrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return t.StateSpace()
+		}).SetMethodDescription("Returns a state space realization. Each element is realized separately, " +
+			"so the realization is not minimal if elements share poles."),
+		"toLaTeX": value.MethodAtType(0, func(t TransferMatrix, st funcGen.Stack[value.Value]) (value.Value, error) {
 			var b bytes.Buffer
-			lin.ToLaTeX(&b)
+			t.ToLaTeX(&b)
 			return value.String(b.String()), nil
-		}).SetMethodDescription("Returns a LaTeX representation of the linear system."),
-		"toUnicode": value.MethodAtType(0, func(lin *Linear, st funcGen.Stack[value.Value]) (value.Value, error) {
-			return value.String(lin.ToUnicode()), nil
-		}).SetMethodDescription("Returns a unicode string representation of the linear system."),
+		}).SetMethodDescription("Returns a LaTeX representation of the transfer matrix."),
+		"string": value.MethodAtType(0, func(t TransferMatrix, st funcGen.Stack[value.Value]) (value.Value, error) {
+			return value.String(t.String()), nil
+		}).SetMethodDescription("Returns a string representation of the transfer matrix."),
+	}
+}
+
+// toTransferMatrix converts a value to a transfer matrix. Accepted are transfer
+// matrices, state space systems, matrices, lists of rows and single systems.
+func toTransferMatrix(st funcGen.Stack[value.Value], v value.Value) (TransferMatrix, error) {
+	switch t := v.(type) {
+	case TransferMatrix:
+		return t, nil
+	case *StateSpace:
+		return t.TransferMatrix()
+	case Matrix:
+		return TransferMatrixFromMatrix(t), nil
+	}
+	if l, ok := toLinear(v); ok {
+		return TransferMatrix{{l}}, nil
+	}
+	list, ok := v.ToList()
+	if !ok {
+		return nil, errors.New("a transfer matrix requires a list of rows")
+	}
+	rows, err := list.ToSlice(st)
+	if err != nil {
+		return nil, err
+	}
+	t := make(TransferMatrix, len(rows))
+	for i, r := range rows {
+		rl, ok := r.ToList()
+		if !ok {
+			return nil, errors.New("the rows of a transfer matrix need to be lists")
+		}
+		rv, err := rl.ToSlice(st)
+		if err != nil {
+			return nil, err
+		}
+		t[i] = make([]*Linear, len(rv))
+		for j, e := range rv {
+			if l, ok := toLinear(e); ok {
+				t[i][j] = l
+			} else {
+				return nil, errors.New("the elements of a transfer matrix need to be linear systems")
+			}
+		}
+	}
+	return NewTransferMatrix(t)
+}
+
+// getInputVector returns the input signal of a system with n inputs. It is
+// either a list of functions or a function returning a list.
+func getInputVector(st funcGen.Stack[value.Value], v value.Value, n int) (func(t float64, uv Vector) error, error) {
+	stack := funcGen.NewEmptyStack[value.Value]()
+	toFloat := func(v value.Value) (float64, error) {
+		if f, ok := v.ToFloat(); ok {
+			return f, nil
+		}
+		return 0, errors.New("the input signals need to be floats")
+	}
+	if cl, ok := v.(value.Closure); ok {
+		return func(t float64, uv Vector) error {
+			r, err := cl.Eval(stack, value.Float(t))
+			if err != nil {
+				return err
+			}
+			list, ok := r.ToList()
+			if !ok {
+				return errors.New("u(t) needs to return a list")
+			}
+			items, err := list.ToSlice(stack)
+			if err != nil {
+				return err
+			}
+			if len(items) != n {
+				return fmt.Errorf("u(t) needs to return %d values", n)
+			}
+			for i, item := range items {
+				uv[i], err = toFloat(item)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}, nil
+	}
+	list, ok := v.ToList()
+	if !ok {
+		return nil, errors.New("the input needs to be a list of functions")
+	}
+	items, err := list.ToSlice(st)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) != n {
+		return nil, fmt.Errorf("the system requires %d input signals", n)
+	}
+	return func(t float64, uv Vector) error {
+		for i, item := range items {
+			if cl, ok := item.(value.Closure); ok {
+				r, err := cl.Eval(stack, value.Float(t))
+				if err != nil {
+					return err
+				}
+				uv[i], err = toFloat(r)
+				if err != nil {
+					return err
+				}
+			} else {
+				uv[i], err = toFloat(item)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}, nil
+}
+
+func getStateSpace(st funcGen.Stack[value.Value], i int) (*StateSpace, error) {
+	if sys, ok := st.Get(i).(*StateSpace); ok {
+		return sys, nil
+	}
+	if t, ok := st.Get(i).(TransferMatrix); ok {
+		return t.StateSpace()
+	}
+	if lin, ok := getLinear(st, i); ok {
+		return lin.ControllableForm()
+	}
+	return nil, errors.New("a state space system or a linear system is required")
+}
+
+// addStateFeedback adds the closed loop system with the state feedback gain k to the map
+func addStateFeedback(m value.RealMap, sys *StateSpace, k Matrix) error {
+	closed, err := sys.StateFeedback(k)
+	if err != nil {
+		return err
+	}
+	m["ss"] = closed
+	if closed.isSISO() {
+		lin, err := closed.Linear()
+		if err != nil {
+			return err
+		}
+		m["sys"] = lin
+		if v := 1 / lin.Eval(0); !math.IsInf(v, 0) && !math.IsNaN(v) {
+			m["prefilter"] = value.Float(v)
+		}
+	}
+	return nil
+}
+
+// getFloatList returns the list of floats at the given stack position or the
+// default values if there is no such argument.
+func getFloatList(st funcGen.Stack[value.Value], i int, def []float64) ([]float64, error) {
+	if st.Size() <= i {
+		return def, nil
+	}
+	list, ok := st.Get(i).ToList()
+	if !ok {
+		return nil, errors.New("a list of floats is required")
+	}
+	items, err := list.ToSlice(st)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float64, len(items))
+	for j, item := range items {
+		f, ok := item.ToFloat()
+		if !ok {
+			return nil, errors.New("a list of floats is required")
+		}
+		values[j] = f
+	}
+	return values, nil
+}
+
+// getPolynomial returns the polynomial at the given stack position. A number
+// is converted to a constant polynomial.
+func getPolynomial(st funcGen.Stack[value.Value], i int) (Polynomial, error) {
+	v := st.Get(i)
+	if p, ok := v.(Polynomial); ok {
+		return p, nil
+	}
+	if f, ok := v.ToFloat(); ok {
+		return Polynomial{f}, nil
+	}
+	return nil, errors.New("a polynomial is required")
+}
+
+// getCharPoly returns the characteristic polynomial given either as a polynomial
+// or as a list of poles. The conjugate of a complex pole is added if not contained in the list.
+func getCharPoly(st funcGen.Stack[value.Value], i int) (Polynomial, error) {
+	v := st.Get(i)
+	if p, ok := v.(Polynomial); ok {
+		return p, nil
+	}
+	list, ok := v.ToList()
+	if !ok {
+		return nil, errors.New("the poles need to be given as a list or as a polynomial")
+	}
+	poles, err := list.ToSlice(st)
+	if err != nil {
+		return nil, err
+	}
+	var r []complex128
+	for _, pv := range poles {
+		var c complex128
+		if f, ok := pv.ToFloat(); ok {
+			c = complex(f, 0)
+		} else if cv, ok := pv.(Complex); ok {
+			c = complex128(cv)
+		} else {
+			return nil, errors.New("the poles need to be float or complex values")
+		}
+		if imag(c) < 0 {
+			found := false
+			for _, o := range r {
+				if Equals(o, cmplx.Conj(c)) {
+					found = true
+					break
+				}
+			}
+			if found {
+				continue
+			}
+			c = cmplx.Conj(c)
+		}
+		r = append(r, c)
+	}
+	return NewRoots(r...).Polynomial(), nil
+}
+
+type simulator interface {
+	value.Value
+	SimulateSolver(solver Solver, tMax, dt float64, u func(float64) (float64, error)) (*value.List, int, error)
+}
+
+const simSolverDescription = "The value dt is the step width which defaults to 1e-5. " +
+	"The solver can be 'euler' (default), 'rk4' or 'rk45'. If 'rk4' is selected, dt defaults to tMax/1000 and is limited to tMax/10. " +
+	"If 'rk45' is selected, the adaptive Dormand-Prince method is used and dt is the maximum step width " +
+	"which defaults to tMax/100. In this case the returned list contains the initial point and one point per " +
+	"integration step, so its size is the number of steps taken plus one."
+
+const simInfoDescription = "Returns a map containing the list of points 'points' and the number of " +
+	"integration steps 'steps'. "
+
+func getSolver(st funcGen.Stack[value.Value], index int) (Solver, error) {
+	name, ok := st.GetOptional(index, value.String("euler")).(value.String)
+	if !ok {
+		return Euler, fmt.Errorf("the solver needs to be a string")
 	}
+	return SolverByName(string(name))
 }
 
-func createBodeMethod[T value.Value](convert func(T) *Linear) funcGen.Function[value.Value] {
-	return value.MethodAtType(4, func(lin T, st funcGen.Stack[value.Value]) (value.Value, error) {
-		if style, err := grParser.GetStyle(st, 1, graph.Black); err == nil {
-			if title, ok := st.GetOptional(2, value.String("")).(value.String); ok {
-				if latency, ok := st.GetOptional(3, value.Float(0)).ToFloat(); ok {
-					if steps, ok := st.GetOptional(4, value.Int(0)).(value.Int); ok {
-						contentList := convert(lin).CreateBodeContent(style.Value, string(title), int(steps), latency)
-						return value.NewList(contentList...), nil
-					}
+// simulateWithSolver returns the list of points. If info is set, a map containing
+// the points and the number of steps is returned.
+func simulateWithSolver[T simulator](sys T, st funcGen.Stack[value.Value], solverIndex int, tMax, dt float64, info bool, u func(float64) (float64, error)) (value.Value, error) {
+	solver, err := getSolver(st, solverIndex)
+	if err != nil {
+		return nil, err
+	}
+	list, steps, err := sys.SimulateSolver(solver, tMax, dt, u)
+	if err != nil {
+		return nil, err
+	}
+	if info {
+		return value.NewMap(value.RealMap{
+			"points": list,
+			"steps":  value.Int(steps),
+		}), nil
+	}
+	return list, nil
+}
+
+func createSimStepMethod[T simulator](hint string, info bool) funcGen.Function[value.Value] {
+	desc := "Simulates the system with the step function as input signal. "
+	if info {
+		desc += simInfoDescription
+	}
+	return value.MethodAtType(3, func(sys T, st funcGen.Stack[value.Value]) (value.Value, error) {
+		if tMax, ok := st.Get(1).ToFloat(); ok {
+			dt := 0.0
+			if adt, ok := st.GetOptional(2, value.Float(0)).ToFloat(); ok {
+				dt = adt
+			} else {
+				return nil, fmt.Errorf("simStep requires a float as second argument")
+			}
+			return simulateWithSolver(sys, st, 3, tMax, dt, info, func(t float64) (float64, error) {
+				if t < 0 {
+					return 0, nil
+				}
+				return 1, nil
+			})
+		}
+		return nil, fmt.Errorf("sim requires a float")
+	}).SetMethodDescription("tMax", "dt", "solver", desc+
+		hint+"The value tMax gives the maximum time for the simulation. "+simSolverDescription).VarArgsMethod(1, 3)
+}
+
+func createSimMethod[T simulator](hint string, info bool) funcGen.Function[value.Value] {
+	desc := "Simulates the system with the input signal u(t) as input signal. "
+	if info {
+		desc += simInfoDescription
+	}
+	return value.MethodAtType(4, func(sys T, st funcGen.Stack[value.Value]) (value.Value, error) {
+		if cl, ok := st.Get(1).(value.Closure); ok {
+			stack := funcGen.NewEmptyStack[value.Value]()
+			u := func(t float64) (float64, error) {
+				r, err := cl.Eval(stack, value.Float(t))
+				if err != nil {
+					return 0, err
+				}
+				if c, ok := r.ToFloat(); ok {
+					return c, nil
+				} else {
+					return 0, fmt.Errorf("u(t) needs to return a float")
+				}
+			}
+			if tMax, ok := st.Get(2).ToFloat(); ok {
+				dt := 0.0
+				if adt, ok := st.GetOptional(3, value.Float(0)).ToFloat(); ok {
+					dt = adt
+				} else {
+					return nil, fmt.Errorf("sim requires a float as third argument")
 				}
+				return simulateWithSolver(sys, st, 4, tMax, dt, info, u)
 			}
 		}
-		return nil, fmt.Errorf("bode requires a color, a string and an int as arguments")
-	}).SetMethodDescription("color", "title", "latency", "steps", "Creates a bode chart content.").VarArgsMethod(0, 4)
+		return nil, fmt.Errorf("sim requires a function and a float")
+	}).SetMethodDescription("u(t)", "tMax", "dt", "solver", desc+
+		hint+"The value tMax gives the maximum time for the simulation. "+simSolverDescription).VarArgsMethod(2, 4)
 }
 
 func floatMethods() value.MethodMap {
@@ -577,7 +1825,7 @@ func floatMethods() value.MethodMap {
 			return value.String(addPrefix(float64(f))), nil
 		}).SetMethodDescription("Returns a string with the float value and the unit prefix attached. " +
 			"So 1.5e-6 becomes '1.5μ', 2200 becomes '2.2k', etc."),
-		"bode": createBodeMethod(func(f value.Float) *Linear { return NewConst(float64(f)) }),
+		"bode": createBodeMethod(func(f value.Float) (*Linear, error) { return NewConst(float64(f)), nil }),
 		"imag": value.MethodAtType(0, func(f value.Float, st funcGen.Stack[value.Value]) (value.Value, error) {
 			return value.Float(0), nil
 		}).SetMethodDescription("Returns always zero. Exists just for convenience."),
@@ -619,7 +1867,7 @@ func addPrefix(f float64) string {
 
 func intMethods() value.MethodMap {
 	return value.MethodMap{
-		"bode": createBodeMethod(func(i value.Int) *Linear { return NewConst(float64(i)) }),
+		"bode": createBodeMethod(func(i value.Int) (*Linear, error) { return NewConst(float64(i)), nil }),
 		"imag": value.MethodAtType(0, func(i value.Int, st funcGen.Stack[value.Value]) (value.Value, error) {
 			return value.Int(0), nil
 		}).SetMethodDescription("Returns always zero. Exists just for convenience."),
@@ -699,8 +1947,93 @@ func (d directAccess) getY(st funcGen.Stack[value.Value], i int) (float64, error
 	return y, err
 }
 
+const stepInfoDescription = "Returns a map containing the 'riseTime' (10% to 90%), the 'peakTime', the 'peak' value, " +
+	"where the 'peakTime' is NaN if the response rises without a maximum, " +
+	"the 'overshoot' in percent, the 'settlingTime' of the error band, the 'steadyState' value and the 'steadyStateError' " +
+	"of a unit step. The relative width of the error band defaults to 0.02. The list 'hints' contains " +
+	"chart contents which mark these values in a step response plot."
+
+func (si *StepInfo) toMap(band float64) value.Value {
+	hints := []value.Value{
+		grParser.NewChartContentValue(graph.Hint{
+			Text:  fmt.Sprintf("t_{r}=%.3g", si.RiseTime),
+			Style: graph.Black,
+			Pos:   graph.Point{X: si.RiseEnd, Y: 0.9 * si.SteadyState},
+		}, nil),
+		grParser.NewChartContentValue(graph.Hint{
+			Text:  fmt.Sprintf("t_{s}=%.3g", si.SettlingTime),
+			Style: graph.Black,
+			Pos:   graph.Point{X: si.SettlingTime, Y: si.SteadyState},
+		}, nil),
+	}
+	if si.Overshoot > 0 && !math.IsNaN(si.PeakTime) {
+		hints = append(hints, grParser.NewChartContentValue(graph.Hint{
+			Text:  fmt.Sprintf("%.3g%%", si.Overshoot),
+			Style: graph.Black,
+			Pos:   graph.Point{X: si.PeakTime, Y: si.Peak},
+		}, nil))
+	}
+	return value.NewMap(value.RealMap{
+		"riseTime":         value.Float(si.RiseTime),
+		"peakTime":         value.Float(si.PeakTime),
+		"peak":             value.Float(si.Peak),
+		"overshoot":        value.Float(si.Overshoot),
+		"settlingTime":     value.Float(si.SettlingTime),
+		"steadyState":      value.Float(si.SteadyState),
+		"steadyStateError": value.Float(si.SteadyStateError),
+		"band":             value.Float(band),
+		"hints":            value.NewList(hints...),
+	})
+}
+
 func listMethods() value.MethodMap {
 	return value.MethodMap{
+		"stepInfo": value.MethodAtType(3, func(list *value.List, st funcGen.Stack[value.Value]) (value.Value, error) {
+			items, err := list.ToSlice(st)
+			if err != nil {
+				return nil, err
+			}
+			band, ok := st.GetOptional(1, value.Float(0.02)).ToFloat()
+			if !ok {
+				return nil, errors.New("stepInfo requires a float as band")
+			}
+			var data dataInterface
+			switch st.Size() {
+			case 1, 2:
+				data = directAccess{items: items}
+			case 4:
+				tc, tok := st.Get(2).(value.Closure)
+				yc, yok := st.Get(3).(value.Closure)
+				if !tok || !yok {
+					return nil, errors.New("stepInfo: last two arguments needs to be functions")
+				}
+				data = closureAccess{items: items, tc: tc, yc: yc}
+			default:
+				return nil, errors.New("stepInfo requires zero, one or three arguments")
+			}
+			t := make([]float64, len(items))
+			y := make([]float64, len(items))
+			for i := range items {
+				t[i], err = data.getT(st, i)
+				if err != nil {
+					return nil, err
+				}
+				y[i], err = data.getY(st, i)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if len(y) == 0 {
+				return nil, errors.New("stepInfo requires a non empty list")
+			}
+			si, err := CalcStepInfo(t, y, y[len(y)-1], band)
+			if err != nil {
+				return nil, err
+			}
+			return si.toMap(band), nil
+		}).SetMethodDescription("band", "t_func(entry) float", "y_func(entry) float",
+			"Calculates the characteristic values of a step response, e.g. the result of simStep. "+
+				"The last value of the list is taken as the steady state value. "+stepInfoDescription).VarArgsMethod(0, 3),
 		"errorBandEntrance": value.MethodAtType(4, func(list *value.List, st funcGen.Stack[value.Value]) (value.Value, error) {
 			items, err := list.ToSlice(st)
 			if err != nil {
@@ -777,8 +2110,137 @@ func listMethods() value.MethodMap {
 	}
 }
 
+// getIdentData reads the measured data used by the system identification. If the data
+// contains no input values, the returned input is nil.
+func getIdentData(st funcGen.Stack[value.Value], list *value.List) ([]float64, []float64, []float64, error) {
+	items, err := list.ToSlice(st)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var t, u, y []float64
+	for _, item := range items {
+		switch item := item.(type) {
+		case *value.List:
+			s, err := item.ToSlice(st)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if len(s) != 2 && len(s) != 3 {
+				return nil, nil, nil, errors.New("the data needs to contain pairs [t, y] or triples [t, u, y]")
+			}
+			f := make([]float64, len(s))
+			for i, v := range s {
+				var ok bool
+				f[i], ok = v.ToFloat()
+				if !ok {
+					return nil, nil, nil, errors.New("the data needs to contain floats")
+				}
+			}
+			t = append(t, f[0])
+			y = append(y, f[len(f)-1])
+			if len(f) == 3 {
+				u = append(u, f[1])
+			}
+		case grParser.ToPoint:
+			p := item.ToPoint()
+			t = append(t, p.X)
+			y = append(y, p.Y)
+		default:
+			return nil, nil, nil, errors.New("the data needs to contain pairs [t, y] or triples [t, u, y]")
+		}
+	}
+	if u != nil && len(u) != len(t) {
+		return nil, nil, nil, errors.New("either all or no samples need to contain an input value")
+	}
+	return t, u, y, nil
+}
+
+// getFrequencyData reads a measured frequency response
+func getFrequencyData(st funcGen.Stack[value.Value], v value.Value) ([]FrequencyPoint, error) {
+	list, ok := v.ToList()
+	if !ok {
+		return nil, errors.New("the frequency response needs to be a list")
+	}
+	items, err := list.ToSlice(st)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]FrequencyPoint, len(items))
+	for i, item := range items {
+		vec, err := ToVector(st, item)
+		if err != nil || len(vec) != 3 {
+			return nil, errors.New("the frequency response needs to contain triples [ω, magnitude, phase]")
+		}
+		data[i] = FrequencyPoint{W: vec[0], Magnitude: vec[1], Phase: vec[2]}
+	}
+	return data, nil
+}
+
+func getIdentModel(st funcGen.Stack[value.Value], v value.Value) (IdentModel, error) {
+	if name, ok := v.(value.String); ok {
+		return IdentModelByName(string(name))
+	}
+	if list, ok := v.ToList(); ok {
+		s, err := list.ToSlice(st)
+		if err != nil {
+			return IdentModel{}, err
+		}
+		if len(s) == 2 {
+			if m, ok := s[0].(value.Int); ok {
+				if n, ok := s[1].(value.Int); ok {
+					return NewTFModel(int(m), int(n))
+				}
+			}
+		}
+	}
+	return IdentModel{}, errors.New("the structure needs to be a string or a list of two ints")
+}
+
+// getCharPolyFunc returns a function which creates the characteristic polynomial
+// for a given parameter. The value needs to be a function returning a polynomial or
+// a linear system, or a symbolic polynomial or linear system with a single symbol.
+// In the latter case the name of the symbol is returned.
+func getCharPolyFunc(v value.Value) (func(k float64) (Polynomial, error), string, error) {
+	switch f := v.(type) {
+	case value.Closure:
+		stack := funcGen.NewEmptyStack[value.Value]()
+		return func(k float64) (Polynomial, error) {
+			poly, err := f.Eval(stack, value.Float(k))
+			if err != nil {
+				return Polynomial{}, fmt.Errorf("error creating polynomial: %w", err)
+			}
+			if p, ok := poly.(Polynomial); ok {
+				return p, nil
+			}
+			if l, ok := poly.(*Linear); ok {
+				return l.Denominator, nil
+			}
+			return Polynomial{}, fmt.Errorf("the function needs to return a polynomial or a linear system")
+		}, "", nil
+	case SymPolynomial:
+		return symCharPolyFunc(f, f.Symbols())
+	case *SymLinear:
+		return symCharPolyFunc(f.Denominator, f.Symbols())
+	}
+	return nil, "", errors.New("a function or a symbolic polynomial or linear system is required")
+}
+
+func symCharPolyFunc(p SymPolynomial, symbols []string) (func(k float64) (Polynomial, error), string, error) {
+	if len(symbols) != 1 {
+		return nil, "", fmt.Errorf("exactly one symbol is required, found %d", len(symbols))
+	}
+	name := symbols[0]
+	return func(k float64) (Polynomial, error) {
+		poly, _ := p.Subst(map[string]float64{name: k}).Polynomial()
+		return poly, nil
+	}, name, nil
+}
+
 func getLinear(st funcGen.Stack[value.Value], i int) (*Linear, bool) {
-	v := st.Get(i)
+	return toLinear(st.Get(i))
+}
+
+func toLinear(v value.Value) (*Linear, bool) {
 	if l, ok := v.(*Linear); ok {
 		return l, true
 	}
@@ -1242,6 +2704,11 @@ var Parser = value.New().
 		BlockFactoryValueType = fg.RegisterType("block", "A Simulink like simulation block. Blocks are connected by the names of the input and output signals. See the non linear simulation example for details on it's usage.")
 		TwoPortValueType = fg.RegisterType("twoPort", "A classical two-port described by a 2x2 matrix.")
 		GuiElementsType = fg.RegisterType("gui", "The interface to gui elements able to modify the output.")
+		MatrixValueType = fg.RegisterType("matrix", "A real matrix. Can be created from a list of rows.")
+		StateSpaceValueType = fg.RegisterType("stateSpace", "A linear system in the state space representation x'=Ax+Bu, y=Cx+Du.")
+		DiscreteValueType = fg.RegisterType("discreteSystem", "A discrete time linear system. The system is represented by its transfer function in z and the sample time T.")
+		SymbolicValueType = fg.RegisterType("symbolic", "A polynomial or a rational function in s whose coefficients are polynomials in named symbols. Created by sym(name).")
+		TransferMatrixValueType = fg.RegisterType("transferMatrix", "A system with multiple inputs and outputs given by a matrix of transfer functions. Created by tf(rows).")
 
 		createExp(fg)
 		createMul(fg)
@@ -1249,6 +2716,8 @@ var Parser = value.New().
 		createSub(fg)
 		createAdd(fg)
 		createNeg(fg)
+		createSymOperations(fg)
+		createTransferMatrixOperations(fg)
 
 		ParserFunctionGenerator = fg
 
@@ -1264,6 +2733,11 @@ var Parser = value.New().
 	RegisterMethods(ComplexValueType, cmplxMethods()).
 	RegisterMethods(TwoPortValueType, twoPortMethods()).
 	RegisterMethods(GuiElementsType, guiMethods()).
+	RegisterMethods(MatrixValueType, matrixMethods()).
+	RegisterMethods(StateSpaceValueType, stateSpaceMethods()).
+	RegisterMethods(DiscreteValueType, discreteMethods()).
+	RegisterMethods(SymbolicValueType, symMethods()).
+	RegisterMethods(TransferMatrixValueType, transferMatrixMethods()).
 	Modify(grParser.Setup).
 	RegisterMethods(grParser.Chart3dType, chart3dMethods()).
 	AddConstant("j", Complex(complex(0, 1))).
@@ -1369,6 +2843,19 @@ var Parser = value.New().
 		Args:   1,
 		IsPure: true,
 	}.SetDescription("arg", "Creates a linear system. Can be used to cast a float, int or polynomial to a linear system.")).
+	AddStaticFunction("deadTime", funcGen.Function[value.Value]{
+		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			if delay, ok := stack.Get(0).ToFloat(); ok {
+				if delay < 0 {
+					return nil, fmt.Errorf("the dead time must not be negative")
+				}
+				return &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1}, Delay: delay}, nil
+			}
+			return nil, fmt.Errorf("deadTime requires a float value")
+		},
+		Args:   1,
+		IsPure: true,
+	}.SetDescription("T", "Creates the dead time element e^(-sT). It can be multiplied with other linear systems.")).
 	AddStaticFunction("dirac", funcGen.Function[value.Value]{
 		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
 			switch stack.Size() {
@@ -1414,69 +2901,327 @@ var Parser = value.New().
 		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
 			return grParser.NewChartContentValue(Polar{}, nil), nil
 		},
-		Args:   0,
+		Args:   0,
+		IsPure: true,
+	}.SetDescription("Returns a polar grid to be added to a chart.")).
+	AddStaticFunction("mCircles", funcGen.Function[value.Value]{
+		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			values, err := getFloatList(stack, 0, []float64{-6, -3, -1, 0, 1, 3, 6})
+			if err != nil {
+				return nil, fmt.Errorf("mCircles: %w", err)
+			}
+			return grParser.NewChartContentValue(MCircles{Values: values}, setImReLabels), nil
+		},
+		Args:   1,
+		IsPure: true,
+	}.SetDescription("list of dB", "Returns the circles of constant closed loop magnitude to be added to a nyquist plot. "+
+		"The magnitudes are given in dB.").VarArgs(0, 1)).
+	AddStaticFunction("nCircles", funcGen.Function[value.Value]{
+		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			values, err := getFloatList(stack, 0, []float64{-90, -60, -45, -30, -20, -10})
+			if err != nil {
+				return nil, fmt.Errorf("nCircles: %w", err)
+			}
+			nc, err := NewNCircles(values)
+			if err != nil {
+				return nil, err
+			}
+			return grParser.NewChartContentValue(nc, setImReLabels), nil
+		},
+		Args:   1,
+		IsPure: true,
+	}.SetDescription("list of degrees", "Returns the curves of constant closed loop phase to be added to a nyquist plot. "+
+		"The phases are given in degrees.").VarArgs(0, 1)).
+	AddStaticFunction("routhRange", funcGen.Function[value.Value]{
+		Func: func(st funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			cpp, _, err := getCharPolyFunc(st.Get(0))
+			if err != nil {
+				return nil, fmt.Errorf("routhRange: %w", err)
+			}
+			ranges, err := RouthRange(cpp)
+			if err != nil {
+				return nil, err
+			}
+			return value.NewListConvert(func(r [2]float64) (value.Value, error) {
+				return value.NewList(value.Float(r[0]), value.Float(r[1])), nil
+			}, ranges), nil
+		},
+		Args:   1,
+		IsPure: true,
+	}.SetDescription("func(k) value", "Calculates the ranges of the parameter k for which the system is stable. "+
+		"The function needs to return the characteristic polynomial or the closed loop system, "+
+		"whose coefficients depend linearly on k. Instead of the function a symbolic polynomial or closed loop "+
+		"system containing a single symbol can be given. Returns a list of ranges [kMin, kMax].")).
+	AddStaticFunction("rootLocus", funcGen.Function[value.Value]{
+		Func: func(st funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			cpp, symbol, err := getCharPolyFunc(st.Get(0))
+			if err != nil {
+				return nil, fmt.Errorf("rootLocus: %w", err)
+			}
+			if kMin, ok := st.Get(1).ToFloat(); ok {
+				if kMax, ok := st.Get(2).ToFloat(); ok {
+					var parName string
+					if parNameVal, ok := st.GetOptional(3, value.String(symbol)).(value.String); ok {
+						parName = string(parNameVal)
+					} else {
+						return nil, fmt.Errorf("rootLocus requires a string as fourth argument")
+					}
+
+					contentList, err := RootLocus(cpp, kMin, kMax, parName)
+					if err != nil {
+						return nil, fmt.Errorf("rootLocus failed: %w", err)
+					}
+					return value.NewListConvert(func(i graph.ChartContent) (value.Value, error) {
+						return grParser.NewChartContentValue(i, setImReLabels), nil
+					}, contentList), nil
+				}
+			}
+			return nil, fmt.Errorf("rootLocus requires a function and two floats")
+		},
+		Args:   4,
+		IsPure: true,
+	}.SetDescription("func(k) value", "kMin", "kMax", "parName", "Creates a root locus chart content. "+
+		"If the function returns a polynomial for the given k, the roots of that polynomial are calculated. "+
+		"If a linear system is returned, the poles are calculated. Instead of the function a symbolic "+
+		"polynomial or linear system containing a single symbol can be given.").VarArgs(3, 4)).
+	AddStaticFunction("diophantine", funcGen.Function[value.Value]{
+		Func: func(st funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			var p [3]Polynomial
+			for i := range p {
+				var err error
+				p[i], err = getPolynomial(st, i)
+				if err != nil {
+					return nil, fmt.Errorf("diophantine: %w", err)
+				}
+			}
+			x, y, err := Diophantine(p[0], p[1], p[2])
+			if err != nil {
+				return nil, err
+			}
+			return value.NewMap(value.RealMap{
+				"x": x,
+				"y": y,
+			}), nil
+		},
+		Args:   3,
+		IsPure: true,
+	}.SetDescription("a", "b", "c", "Solves the polynomial equation a*x+b*y=c and returns a map containing 'x' and 'y'. "+
+		"The solution with deg(y)<deg(a) is returned. If b/a is the plant, y/x is the controller which places "+
+		"the closed loop poles at the roots of c.")).
+	AddStaticFunction("feedback", funcGen.Function[value.Value]{
+		Func: func(st funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			sign, ok := st.GetOptional(2, value.Float(-1)).ToFloat()
+			if !ok {
+				return nil, errors.New("feedback requires a float as third argument")
+			}
+			switch g := st.Get(0).(type) {
+			case TransferMatrix:
+				h, err := toTransferMatrix(st, st.Get(1))
+				if err != nil {
+					return nil, err
+				}
+				return g.Feedback(h, sign)
+			case *StateSpace:
+				h, err := getStateSpace(st, 1)
+				if err != nil {
+					return nil, err
+				}
+				return g.Feedback(h, sign)
+			}
+			if g, ok := getLinear(st, 0); ok {
+				if h, ok := getLinear(st, 1); ok {
+					return g.Feedback(h, sign)
+				}
+			}
+			return nil, errors.New("feedback requires two linear systems")
+		},
+		Args:   3,
+		IsPure: true,
+	}.SetDescription("G", "H", "sign", "Closes the loop of the system G with the system H in the feedback path. "+
+		"By default a negative feedback G/(1+GH) is used. If sign is positive, a positive feedback is used.").VarArgs(2, 3)).
+	AddStaticFunction("controlLoop", funcGen.Function[value.Value]{
+		Func: func(st funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			var sys [3]*Linear
+			for i := range sys {
+				var ok bool
+				sys[i], ok = toLinear(st.GetOptional(i, value.Int(1)))
+				if !ok {
+					return nil, errors.New("controlLoop requires linear systems as arguments")
+				}
+			}
+			cl, err := NewControlLoop(sys[0], sys[1], sys[2])
+			if err != nil {
+				return nil, err
+			}
+			return cl.toMap(), nil
+		},
+		Args:   3,
+		IsPure: true,
+	}.SetDescription("G", "K", "H", "Creates the standard control loop with the plant G, the controller K and the "+
+		"sensor H, which is one by default. Returns a map containing the loop gain 'L'=KGH and the closed loop "+
+		"transfer functions 'S'=1/(1+L) from an output disturbance to y, 'T'=L/(1+L), 'GS'=G/(1+L) from an "+
+		"input disturbance to y, 'KS'=K/(1+L) from the reference to u, 'RY'=KG/(1+L) from the reference to y "+
+		"and 'NY'=-KG/(1+L) from the measurement noise to y.").VarArgs(2, 3)).
+	AddStaticFunction("sym", funcGen.Function[value.Value]{
+		Func: func(st funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			name, ok := st.Get(0).(value.String)
+			if !ok || name == "" {
+				return nil, errors.New("sym requires a name")
+			}
+			if name == "s" {
+				return nil, errors.New("the name 's' is reserved for the Laplace variable")
+			}
+			return SymPolynomial{NewSymbol(string(name))}, nil
+		},
+		Args:   1,
+		IsPure: true,
+	}.SetDescription("name", "Creates a symbolic parameter. Polynomials and transfer functions containing "+
+		"symbols keep them as coefficients and can be evaluated by subst.")).
+	AddStaticFunction("pid", funcGen.Function[value.Value]{
+		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			if kp, ok := stack.Get(0).ToFloat(); ok {
+				if ti, ok := stack.Get(1).ToFloat(); ok {
+					if td, ok := stack.GetOptional(2, value.Float(0)).ToFloat(); ok {
+						if tp, ok := stack.GetOptional(3, value.Float(0)).ToFloat(); ok {
+							return PID(kp, ti, td, tp)
+						}
+					}
+				}
+			}
+			return nil, fmt.Errorf("pid requires 3 float values")
+		},
+		Args:   4,
+		IsPure: true,
+	}.SetDescription("k_p", "T_I", "T_D", "T_P", "Creates a PID linear system. The fourth time T_P is the time "+
+		"constant that describes the parasitic PT1 term occurring in a real differentiation.").VarArgs(2, 4)).
+	AddStaticFunction("tunePid", funcGen.Function[value.Value]{
+		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			lin, ok := getLinear(stack, 0)
+			if !ok {
+				return nil, fmt.Errorf("tunePid requires a linear system as first argument")
+			}
+			methodName, ok := stack.GetOptional(1, value.String("zn")).(value.String)
+			if !ok {
+				return nil, fmt.Errorf("tunePid requires a string as second argument")
+			}
+			method, err := TuningMethodByName(string(methodName))
+			if err != nil {
+				return nil, err
+			}
+			ctName, ok := stack.GetOptional(2, value.String("PID")).(value.String)
+			if !ok {
+				return nil, fmt.Errorf("tunePid requires a string as third argument")
+			}
+			ct, err := ControllerTypeByName(string(ctName))
+			if err != nil {
+				return nil, err
+			}
+			tuning, err := lin.TunePID(method, ct)
+			if err != nil {
+				return nil, err
+			}
+			controller, err := tuning.Controller()
+			if err != nil {
+				return nil, err
+			}
+			m := value.RealMap{
+				"kp":  value.Float(tuning.Kp),
+				"Ti":  value.Float(tuning.Ti),
+				"Td":  value.Float(tuning.Td),
+				"pid": controller,
+			}
+			for k, v := range tuning.Values {
+				m[k] = value.Float(v)
+			}
+			if tuning.Tangent != nil {
+				m["construction"] = value.NewListConvert(func(c graph.ChartContent) (value.Value, error) {
+					return grParser.NewChartContentValue(c, nil), nil
+				}, tuning.Tangent.ChartContent())
+			}
+			return value.NewMap(m), nil
+		},
+		Args:   3,
+		IsPure: true,
+	}.SetDescription("G", "method", "type", "Tunes a controller for the plant G by a tuning rule. "+
+		"The method is one of 'zn' (Ziegler-Nichols, ultimate gain), 'chr' (Chien-Hrones-Reswick, aperiodic reference response), "+
+		"'simc' (Skogestad, half rule) or 'tsum' (T-sum rule of Kuhn) and defaults to 'zn'. The type of controller is 'P', 'PI' or 'PID'. "+
+		"Returns a map containing the parameters 'kp', 'Ti' and 'Td', the controller 'pid' and the characteristic values of the plant. "+
+		"If the rule is based on the step response, the list 'construction' contains the tangent construction "+
+		"to be added to a plot of the step response.").VarArgs(1, 3)).
+	AddStaticFunction("identify", funcGen.Function[value.Value]{
+		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			list, ok := stack.Get(0).ToList()
+			if !ok {
+				return nil, fmt.Errorf("identify requires a list as first argument")
+			}
+			t, u, y, err := getIdentData(stack, list)
+			if err != nil {
+				return nil, err
+			}
+			model, err := getIdentModel(stack, stack.GetOptional(1, value.String("PT1")))
+			if err != nil {
+				return nil, err
+			}
+			res, err := Identify(t, u, y, model)
+			if err != nil {
+				return nil, err
+			}
+			return value.NewMap(value.RealMap{
+				"model": res.Model,
+				"rms":   value.Float(res.RMS),
+				"r2":    value.Float(res.R2),
+			}), nil
+		},
+		Args:   2,
 		IsPure: true,
-	}.SetDescription("Returns a polar grid to be added to a chart.")).
-	AddStaticFunction("rootLocus", funcGen.Function[value.Value]{
-		Func: func(st funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
-			if cppClosure, ok := st.Get(0).(value.Closure); ok {
-				if kMin, ok := st.Get(1).ToFloat(); ok {
-					if kMax, ok := st.Get(2).ToFloat(); ok {
-						stack := funcGen.NewEmptyStack[value.Value]()
-						cpp := func(k float64) (Polynomial, error) {
-							poly, err := cppClosure.Eval(stack, value.Float(k))
-							if err != nil {
-								return Polynomial{}, fmt.Errorf("error creating polynomial: %w", err)
-							}
-							if p, ok := poly.(Polynomial); ok {
-								return p, nil
-							}
-							if l, ok := poly.(*Linear); ok {
-								return l.Denominator, nil
-							}
-							return Polynomial{}, fmt.Errorf("the function needs to return a polynomial or a linear system")
-						}
-						var parName string
-						if parNameVal, ok := st.GetOptional(3, value.String("")).(value.String); ok {
-							parName = string(parNameVal)
-						} else {
-							return nil, fmt.Errorf("rootLocus requires a string as fourth argument")
-						}
-
-						contentList, err := RootLocus(cpp, kMin, kMax, parName)
-						if err != nil {
-							return nil, fmt.Errorf("rootLocus failed: %w", err)
-						}
-						return value.NewListConvert(func(i graph.ChartContent) (value.Value, error) {
-							return grParser.NewChartContentValue(i, setImReLabels), nil
-						}, contentList), nil
-					}
-				}
+	}.SetDescription("data", "structure", "Fits a model to measured data. The data is a list of [t, y] pairs of a step response "+
+		"or a list of [t, u, y] triples if the input u is not a unit step. The system needs to be at rest at the first sample. "+
+		"The structure is 'PT1', 'PT2', 'PT1T' (PT1 with dead time) or a list [m, n] which gives the order m of the "+
+		"numerator and the order n of the denominator of a general transfer function. It defaults to 'PT1'. "+
+		"The sum of the squared errors is minimized. Returns a map containing the 'model', the root mean square "+
+		"'rms' of the errors and the coefficient of determination 'r2'.").VarArgs(1, 2)).
+	AddStaticFunction("fitFrequencyResponse", funcGen.Function[value.Value]{
+		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			data, err := getFrequencyData(stack, stack.Get(0))
+			if err != nil {
+				return nil, err
 			}
-			return nil, fmt.Errorf("rootLocus requires a function and two floats")
+			num, ok := stack.Get(1).(value.Int)
+			if !ok {
+				return nil, fmt.Errorf("the order of the numerator needs to be an int")
+			}
+			den, ok := stack.Get(2).(value.Int)
+			if !ok {
+				return nil, fmt.Errorf("the order of the denominator needs to be an int")
+			}
+			return FitFrequencyResponse(data, int(num), int(den))
 		},
-		Args:   4,
+		Args:   3,
 		IsPure: true,
-	}.SetDescription("func(k) value", "kMin", "kMax", "parName", "Creates a root locus chart content. "+
-		"If the function returns a polynomial for the given k, the roots of that polynomial are calculated. "+
-		"If a linear system is returned, the poles are calculated.").VarArgs(3, 4)).
-	AddStaticFunction("pid", funcGen.Function[value.Value]{
+	}.SetDescription("data", "nOrder", "dOrder", "Fits a transfer function to a measured frequency response. "+
+		"The data is a list of [ω, magnitude, phase] triples. The magnitude is the absolute value, not the value in dB, "+
+		"and the phase is given in degrees. The values nOrder and dOrder are the orders of the numerator "+
+		"and the denominator. Levy's method is used, refined by the iterations of Sanathanan and Koerner.")).
+	AddStaticFunction("frd", funcGen.Function[value.Value]{
 		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
-			if kp, ok := stack.Get(0).ToFloat(); ok {
-				if ti, ok := stack.Get(1).ToFloat(); ok {
-					if td, ok := stack.GetOptional(2, value.Float(0)).ToFloat(); ok {
-						if tp, ok := stack.GetOptional(3, value.Float(0)).ToFloat(); ok {
-							return PID(kp, ti, td, tp)
-						}
-					}
-				}
+			data, err := getFrequencyData(stack, stack.Get(0))
+			if err != nil {
+				return nil, err
 			}
-			return nil, fmt.Errorf("pid requires 3 float values")
+			style, err := grParser.GetStyle(stack, 1, graph.Black)
+			if err != nil {
+				return nil, err
+			}
+			title, ok := stack.GetOptional(2, value.String("")).(value.String)
+			if !ok {
+				return nil, fmt.Errorf("the title needs to be a string")
+			}
+			return value.NewList(CreateFrdContent(data, style.Value, string(title))...), nil
 		},
-		Args:   4,
+		Args:   3,
 		IsPure: true,
-	}.SetDescription("k_p", "T_I", "T_D", "T_P", "Creates a PID linear system. The fourth time T_P is the time "+
-		"constant that describes the parasitic PT1 term occurring in a real differentiation.").VarArgs(2, 4)).
+	}.SetDescription("data", "color", "title", "Creates a bode chart content which shows the measured frequency response "+
+		"as points. The data is a list of [ω, magnitude, phase] triples as used by fitFrequencyResponse.").VarArgs(1, 3)).
 	AddStaticFunction("nelderMead", funcGen.Function[value.Value]{
 		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
 			if fu, ok := stack.Get(0).(value.Closure); ok {
@@ -1596,6 +3341,239 @@ var Parser = value.New().
 	AddStaticFunction("tpH", createTwoPort(HParam)).
 	AddStaticFunction("tpC", createTwoPort(CParam)).
 	AddStaticFunction("tpA", createTwoPort(AParam)).
+	AddStaticFunction("matrix", funcGen.Function[value.Value]{
+		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			return ToMatrix(stack, stack.Get(0))
+		},
+		Args:   1,
+		IsPure: true,
+	}.SetDescription("rows", "Creates a matrix from a list of rows. Each row is a list of floats.")).
+	AddStaticFunction("ss", funcGen.Function[value.Value]{
+		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			switch stack.Size() {
+			case 1:
+				return getStateSpace(stack, 0)
+			case 4:
+				a, err := ToMatrix(stack, stack.Get(0))
+				if err != nil {
+					return nil, fmt.Errorf("A: %w", err)
+				}
+				b, err := getMatrixOrVector(stack, 1, ColumnVector)
+				if err != nil {
+					return nil, fmt.Errorf("B: %w", err)
+				}
+				c, err := getMatrixOrVector(stack, 2, RowVector)
+				if err != nil {
+					return nil, fmt.Errorf("C: %w", err)
+				}
+				d, err := ToMatrix(stack, stack.Get(3))
+				if err != nil {
+					return nil, fmt.Errorf("D: %w", err)
+				}
+				return NewStateSpace(a, b, c, d)
+			}
+			return nil, errors.New("ss requires one or four arguments")
+		},
+		Args:   -1,
+		IsPure: true,
+	}.SetDescription("A", "B", "C", "D", "Creates a state space system x'=Ax+Bu, y=Cx+Du. "+
+		"The matrices are given as lists of rows. If B is a simple list, it is used as a column vector, "+
+		"if C is a simple list, it is used as a row vector. "+
+		"If only one argument is given, it is a linear system which is converted to the controllable canonical form.")).
+	AddStaticFunction("place", funcGen.Function[value.Value]{
+		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			sys, err := getStateSpace(stack, 0)
+			if err != nil {
+				return nil, err
+			}
+			p, err := getCharPoly(stack, 1)
+			if err != nil {
+				return nil, err
+			}
+			k, err := sys.Place(p)
+			if err != nil {
+				return nil, err
+			}
+			m := value.RealMap{"K": k}
+			err = addStateFeedback(m, sys, k)
+			if err != nil {
+				return nil, err
+			}
+			return value.NewMap(m), nil
+		},
+		Args:   2,
+		IsPure: true,
+	}.SetDescription("sys", "poles", "Calculates the state feedback gain K using the formula of Ackermann. "+
+		"The poles are given as a list or by the characteristic polynomial. "+
+		"Returns a map containing the gain 'K', the closed loop 'ss' with the control law u=r-Kx, "+
+		"its transfer function 'sys' and the 'prefilter' which makes the static gain one. "+
+		"If a linear system is given, the state of the controllable canonical form is used.")).
+	AddStaticFunction("observer", funcGen.Function[value.Value]{
+		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			sys, err := getStateSpace(stack, 0)
+			if err != nil {
+				return nil, err
+			}
+			p, err := getCharPoly(stack, 1)
+			if err != nil {
+				return nil, err
+			}
+			l, err := sys.Observer(p)
+			if err != nil {
+				return nil, err
+			}
+			e, err := sys.ObserverError(l)
+			if err != nil {
+				return nil, err
+			}
+			m := value.RealMap{"L": l, "error": e}
+			if stack.Size() > 2 {
+				k, err := getMatrixOrVector(stack, 2, RowVector)
+				if err != nil {
+					return nil, fmt.Errorf("K: %w", err)
+				}
+				c, err := sys.ObserverController(k, l)
+				if err != nil {
+					return nil, err
+				}
+				m["ss"] = c
+				if c.isSISO() {
+					lin, err := c.Linear()
+					if err != nil {
+						return nil, err
+					}
+					m["controller"] = lin
+				}
+			}
+			return value.NewMap(m), nil
+		},
+		Args:   3,
+		IsPure: true,
+	}.SetDescription("sys", "poles", "K", "Calculates the gain L of the Luenberger observer using the formula of Ackermann. "+
+		"The poles are given as a list or by the characteristic polynomial. "+
+		"Returns a map containing the gain 'L' and the matrix 'error'=A-LC of the error dynamics. "+
+		"If the state feedback gain K is given, also the dynamic 'controller' consisting of observer and state feedback "+
+		"is returned. Its input is the control error, so the closed loop is given by (controller*G).loop().").VarArgs(2, 3)).
+	AddStaticFunction("lqr", funcGen.Function[value.Value]{
+		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			sys, err := getStateSpace(stack, 0)
+			if err != nil {
+				return nil, err
+			}
+			q, err := ToMatrix(stack, stack.Get(1))
+			if err != nil {
+				return nil, fmt.Errorf("Q: %w", err)
+			}
+			if q.Rows() == 1 && q.Cols() == 1 && sys.Order() > 1 {
+				// a scalar weights the output
+				q = mul(sys.C.Transpose(), sys.C, sys.Order()).MulFloat(q[0][0])
+			}
+			r, err := ToMatrix(stack, stack.Get(2))
+			if err != nil {
+				return nil, fmt.Errorf("R: %w", err)
+			}
+			k, x, err := sys.LQR(q, r)
+			if err != nil {
+				return nil, err
+			}
+			m := value.RealMap{"K": k, "X": x}
+			err = addStateFeedback(m, sys, k)
+			if err != nil {
+				return nil, err
+			}
+			return value.NewMap(m), nil
+		},
+		Args:   3,
+		IsPure: true,
+	}.SetDescription("sys", "Q", "R", "Calculates the optimal state feedback gain K which minimizes the cost function ∫xᵀQx+uᵀRu dt. "+
+		"If Q is a scalar q, the matrix q·CᵀC is used, which weights the output. "+
+		"Returns a map containing the gain 'K', the solution 'X' of the riccati equation, the closed loop 'ss' with "+
+		"the control law u=r-Kx, its transfer function 'sys' and the 'prefilter' which makes the static gain one. "+
+		"If a linear system is given, the state of the controllable canonical form is used.")).
+	AddStaticFunction("lyap", funcGen.Function[value.Value]{
+		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			a, err := ToMatrix(stack, stack.Get(0))
+			if err != nil {
+				return nil, fmt.Errorf("A: %w", err)
+			}
+			q, err := ToMatrix(stack, stack.Get(1))
+			if err != nil {
+				return nil, fmt.Errorf("Q: %w", err)
+			}
+			return Lyapunov(a, q)
+		},
+		Args:   2,
+		IsPure: true,
+	}.SetDescription("A", "Q", "Solves the lyapunov equation AX+XAᵀ+Q=0 for a stable matrix A.")).
+	AddStaticFunction("kalman", funcGen.Function[value.Value]{
+		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			sys, err := getStateSpace(stack, 0)
+			if err != nil {
+				return nil, err
+			}
+			qn, err := ToMatrix(stack, stack.Get(1))
+			if err != nil {
+				return nil, fmt.Errorf("Qn: %w", err)
+			}
+			rn, err := ToMatrix(stack, stack.Get(2))
+			if err != nil {
+				return nil, fmt.Errorf("Rn: %w", err)
+			}
+			l, p, err := sys.Kalman(qn, rn)
+			if err != nil {
+				return nil, err
+			}
+			e, err := sys.ObserverError(l)
+			if err != nil {
+				return nil, err
+			}
+			return value.NewMap(value.RealMap{"L": l, "P": p, "error": e}), nil
+		},
+		Args:   3,
+		IsPure: true,
+	}.SetDescription("sys", "Qn", "Rn", "Calculates the gain L of the stationary kalman filter. "+
+		"The process noise with the covariance Qn acts on the input of the system, the measurement noise "+
+		"has the covariance Rn. Returns a map containing the gain 'L', the covariance 'P' of the estimation "+
+		"error and the matrix 'error'=A-LC of the error dynamics.")).
+	AddStaticFunction("discrete", funcGen.Function[value.Value]{
+		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			if lin, ok := getLinear(stack, 0); ok {
+				if T, ok := stack.Get(1).ToFloat(); ok {
+					if lin.Delay != 0 {
+						return nil, errors.New("discrete does not support a dead time, a delay of k samples is given by the factor 1/s^k")
+					}
+					return NewDiscrete(lin.Numerator, lin.Denominator, T)
+				}
+			}
+			return nil, errors.New("discrete requires a linear system and a float")
+		},
+		Args:   2,
+		IsPure: true,
+	}.SetDescription("G", "T", "Creates a discrete time system with the sample time T. "+
+		"The variable s of the given transfer function is used as the variable z, "+
+		"so discrete((s+0.5)/(s-0.8), 0.1) creates the system (z+0.5)/(z-0.8). A dead time is rejected, "+
+		"a delay of k samples is given by the factor 1/s^k.")).
+	AddStaticFunction("tf", funcGen.Function[value.Value]{
+		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
+			if sys, ok := stack.Get(0).(*StateSpace); ok {
+				if sys.isSISO() {
+					return sys.Linear()
+				}
+				return sys.TransferMatrix()
+			}
+			if lin, ok := getLinear(stack, 0); ok {
+				return lin, nil
+			}
+			if _, ok := stack.Get(0).ToList(); ok {
+				return toTransferMatrix(stack, stack.Get(0))
+			}
+			return nil, errors.New("tf requires a state space system or a list of rows")
+		},
+		Args:   1,
+		IsPure: true,
+	}.SetDescription("sys", "Returns the transfer function of a state space system. If the system has several inputs "+
+		"or outputs, a transfer matrix is returned. If a list of rows containing linear systems is given, "+
+		"a transfer matrix is created.")).
 	AddStaticFunction("simulateBlocks", funcGen.Function[value.Value]{
 		Func: func(stack funcGen.Stack[value.Value], closureStore []value.Value) (value.Value, error) {
 			if def, ok := stack.Get(0).ToList(); ok {
@@ -1612,15 +3590,25 @@ var Parser = value.New().
 					} else {
 						return nil, fmt.Errorf("the fourth argument of simulate requires an int value")
 					}
+					solver, err := getSolver(stack, 4)
+					if err != nil {
+						return nil, err
+					}
 
-					return SimulateBlock(stack, def, tMax, dt, points)
+					return SimulateBlock(stack, def, solver, tMax, dt, points)
 				}
 			}
 			return nil, fmt.Errorf("simulate requires a list and a flost")
 		},
-		Args:   4,
+		Args:   5,
 		IsPure: true,
-	}.SetDescription("def", "tMax", "dt", "pointsExported", "Simulates the given model.").VarArgs(2, 4)).
+	}.SetDescription("def", "tMax", "dt", "pointsExported", "solver", "Simulates the given model. "+
+		"The solver can be 'euler' (default), 'rk4' or 'rk45'. If the adaptive solver 'rk45' is used, "+
+		"dt is the maximum step width and every accepted step is exported. "+
+		"Blocks whose output depends directly on their input, like gains or a PID controller, are evaluated "+
+		"without delay. A loop which consists only of such blocks is an algebraic loop and is rejected. "+
+		"To get the behavior of earlier versions, which delayed every block by one step, "+
+		"a blockDelay(dt) can be inserted into the loop.").VarArgs(2, 5)).
 	Modify(func(f *funcGen.FunctionGenerator[value.Value]) {
 		p := f.GetParser()
 		p.SetStringConverter(parser2.StringConverterFunc[value.Value](func(s string) value.Value {
@@ -1676,6 +3664,9 @@ func createNeg(fg *value.FunctionGenerator) {
 	m.Register(LinearValueType, func(a value.Value) (value.Value, error) {
 		return a.(*Linear).MulFloat(-1), nil
 	})
+	m.Register(MatrixValueType, func(a value.Value) (value.Value, error) {
+		return a.(Matrix).MulFloat(-1), nil
+	})
 }
 
 func createExp(fg *value.FunctionGenerator) {
@@ -1765,6 +3756,36 @@ func createMul(fg *value.FunctionGenerator) {
 	m.Register(value.IntTypeId, LinearValueType, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
 		return b.(*Linear).MulFloat(float64(a.(value.Int))), nil
 	})
+	m.Register(MatrixValueType, MatrixValueType, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+		return a.(Matrix).MulMatrix(b.(Matrix))
+	})
+	m.Register(DiscreteValueType, DiscreteValueType, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+		return a.(*Discrete).Mul(b.(*Discrete))
+	})
+	m.Register(DiscreteValueType, value.FloatTypeId, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+		return a.(*Discrete).MulFloat(float64(b.(value.Float))), nil
+	})
+	m.Register(value.FloatTypeId, DiscreteValueType, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+		return b.(*Discrete).MulFloat(float64(a.(value.Float))), nil
+	})
+	m.Register(DiscreteValueType, value.IntTypeId, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+		return a.(*Discrete).MulFloat(float64(b.(value.Int))), nil
+	})
+	m.Register(value.IntTypeId, DiscreteValueType, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+		return b.(*Discrete).MulFloat(float64(a.(value.Int))), nil
+	})
+	m.Register(MatrixValueType, value.FloatTypeId, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+		return a.(Matrix).MulFloat(float64(b.(value.Float))), nil
+	})
+	m.Register(MatrixValueType, value.IntTypeId, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+		return a.(Matrix).MulFloat(float64(b.(value.Int))), nil
+	})
+	m.Register(value.FloatTypeId, MatrixValueType, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+		return b.(Matrix).MulFloat(float64(a.(value.Float))), nil
+	})
+	m.Register(value.IntTypeId, MatrixValueType, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+		return b.(Matrix).MulFloat(float64(a.(value.Int))), nil
+	})
 }
 
 func createDiv(fg *value.FunctionGenerator) {
@@ -1896,6 +3917,12 @@ func createAdd(fg *value.FunctionGenerator) {
 	m.Register(value.IntTypeId, LinearValueType, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
 		return b.(*Linear).Add(NewConst(float64(a.(value.Int))))
 	})
+	m.Register(MatrixValueType, MatrixValueType, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+		return a.(Matrix).AddMatrix(b.(Matrix))
+	})
+	m.Register(DiscreteValueType, DiscreteValueType, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+		return a.(*Discrete).Add(b.(*Discrete))
+	})
 }
 
 func createSub(fg *value.FunctionGenerator) {
@@ -1961,6 +3988,145 @@ func createSub(fg *value.FunctionGenerator) {
 	m.Register(value.IntTypeId, LinearValueType, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
 		return b.(*Linear).MulFloat(-1).Add(NewConst(float64(a.(value.Int))))
 	})
+	m.Register(MatrixValueType, MatrixValueType, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+		return a.(Matrix).SubMatrix(b.(Matrix))
+	})
+}
+
+// toSymLinear converts the value to a symbolic rational function
+func toSymLinear(v value.Value) (*SymLinear, error) {
+	switch t := v.(type) {
+	case SymPolynomial:
+		return &SymLinear{Numerator: t, Denominator: SymPolynomial{NewSymConst(1)}}, nil
+	case *SymLinear:
+		return t, nil
+	case Polynomial:
+		return &SymLinear{Numerator: NewSymPolynomial(t), Denominator: SymPolynomial{NewSymConst(1)}}, nil
+	case *Linear:
+		return NewSymLinear(t)
+	}
+	if f, ok := v.ToFloat(); ok {
+		return &SymLinear{Numerator: SymPolynomial{NewSymConst(f)}, Denominator: SymPolynomial{NewSymConst(1)}}, nil
+	}
+	return nil, fmt.Errorf("value can not be combined with symbols")
+}
+
+func symOperation(op func(a, b *SymLinear) (*SymLinear, error)) funcGen.OperatorFunc[value.Value] {
+	return func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+		sa, err := toSymLinear(a)
+		if err != nil {
+			return nil, err
+		}
+		sb, err := toSymLinear(b)
+		if err != nil {
+			return nil, err
+		}
+		r, err := op(sa, sb)
+		if err != nil {
+			return nil, err
+		}
+		return symLinValue(r), nil
+	}
+}
+
+func createSymOperations(fg *value.FunctionGenerator) {
+	otherTypes := []value.Type{PolynomialValueType, LinearValueType, value.FloatTypeId, value.IntTypeId}
+	register := func(name string, op func(a, b *SymLinear) (*SymLinear, error)) {
+		m := fg.GetOpMatrix(name)
+		f := symOperation(op)
+		m.Register(SymbolicValueType, SymbolicValueType, f)
+		for _, b := range otherTypes {
+			m.Register(SymbolicValueType, b, f)
+			m.Register(b, SymbolicValueType, f)
+		}
+	}
+	register("+", func(a, b *SymLinear) (*SymLinear, error) {
+		return a.Add(b), nil
+	})
+	register("-", func(a, b *SymLinear) (*SymLinear, error) {
+		return a.Add(b.MulFloat(-1)), nil
+	})
+	register("*", func(a, b *SymLinear) (*SymLinear, error) {
+		return a.Mul(b), nil
+	})
+	register("/", func(a, b *SymLinear) (*SymLinear, error) {
+		return a.Div(b)
+	})
+
+	fg.GetOpMatrix("^").Register(SymbolicValueType, value.IntTypeId, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+		sa, err := toSymLinear(a)
+		if err != nil {
+			return nil, err
+		}
+		n := int(b.(value.Int))
+		if n < 0 {
+			inv, err := sa.Pow(-n).Inv()
+			if err != nil {
+				return nil, err
+			}
+			return symLinValue(inv), nil
+		}
+		return symLinValue(sa.Pow(n)), nil
+	})
+
+	fg.GetUnaryList("-").Register(SymbolicValueType, func(a value.Value) (value.Value, error) {
+		switch s := a.(type) {
+		case SymPolynomial:
+			return s.MulFloat(-1), nil
+		case *SymLinear:
+			return s.MulFloat(-1), nil
+		}
+		return nil, errors.New("not a symbolic value")
+	})
+}
+
+func createTransferMatrixOperations(fg *value.FunctionGenerator) {
+	scalarTypes := []value.Type{LinearValueType, PolynomialValueType, value.FloatTypeId, value.IntTypeId}
+	matrixOperation := func(op func(a, b TransferMatrix) (TransferMatrix, error)) funcGen.OperatorFunc[value.Value] {
+		return func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+			ta, err := toTransferMatrix(st, a)
+			if err != nil {
+				return nil, err
+			}
+			tb, err := toTransferMatrix(st, b)
+			if err != nil {
+				return nil, err
+			}
+			return op(ta, tb)
+		}
+	}
+	register := func(name string, op func(a, b TransferMatrix) (TransferMatrix, error)) {
+		m := fg.GetOpMatrix(name)
+		f := matrixOperation(op)
+		m.Register(TransferMatrixValueType, TransferMatrixValueType, f)
+		m.Register(TransferMatrixValueType, MatrixValueType, f)
+		m.Register(MatrixValueType, TransferMatrixValueType, f)
+	}
+	register("*", func(a, b TransferMatrix) (TransferMatrix, error) {
+		return a.Mul(b)
+	})
+	register("+", func(a, b TransferMatrix) (TransferMatrix, error) {
+		return a.Add(b)
+	})
+	register("-", func(a, b TransferMatrix) (TransferMatrix, error) {
+		return a.Add(b.MulLinear(NewConst(-1)))
+	})
+
+	mul := fg.GetOpMatrix("*")
+	for _, t := range scalarTypes {
+		mul.Register(TransferMatrixValueType, t, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+			l, _ := toLinear(b)
+			return a.(TransferMatrix).MulLinear(l), nil
+		})
+		mul.Register(t, TransferMatrixValueType, func(st funcGen.Stack[value.Value], a, b value.Value) (value.Value, error) {
+			l, _ := toLinear(a)
+			return b.(TransferMatrix).MulLinear(l), nil
+		})
+	}
+
+	fg.GetUnaryList("-").Register(TransferMatrixValueType, func(a value.Value) (value.Value, error) {
+		return a.(TransferMatrix).MulLinear(NewConst(-1)), nil
+	})
 }
 
 func NelderMead(fu value.Closure, initial *value.List, delta *value.List, iter int) (value.Value, error) {
@@ -2050,6 +4216,25 @@ func NelderMead(fu value.Closure, initial *value.List, delta *value.List, iter i
 	return value.NewMap(value.RealMap(m)), nil
 }
 
+// getMatrixOrVector reads a matrix from the stack. If a flat list of floats
+// is given, the vector function is used to create the matrix.
+func getMatrixOrVector(st funcGen.Stack[value.Value], i int, vector func(Vector) Matrix) (Matrix, error) {
+	v := st.Get(i)
+	if list, ok := v.(*value.List); ok {
+		first, err := list.First(st)
+		if err == nil {
+			if _, isFloat := first.ToFloat(); isFloat {
+				vec, err := ToVector(st, list)
+				if err != nil {
+					return nil, err
+				}
+				return vector(vec), nil
+			}
+		}
+	}
+	return ToMatrix(st, v)
+}
+
 func getComplex(stack funcGen.Stack[value.Value], i int) (complex128, error) {
 	var z complex128
 	v := stack.Get(i)
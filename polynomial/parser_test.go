@@ -105,6 +105,115 @@ func TestLinear(t *testing.T) {
 		{name: "bode-poly", exp: "let g=s+0.2;string(g.bode())", res: value.String("[BodeAmplitude(s+0.2), BodePhase(s+0.2)]")},
 		{name: "bode-float", exp: "let g=0.2;string(g.bode())", res: value.String("[BodeAmplitude(0.2), BodePhase(0.2)]")},
 		{name: "bode-int", exp: "let g=2;string(g.bode())", res: value.String("[BodeAmplitude(2), BodePhase(2)]")},
+
+		{name: "matrix1", exp: "let m=matrix([[1,2],[3,4]]); m.det()", res: value.Float(-2)},
+		{name: "matrix2", exp: "let m=matrix([[1,2],[3,4]])*matrix([[0,1],[1,0]]); m.det()", res: value.Float(2)},
+		{name: "matrix3", exp: "let m=matrix([[1,2],[3,4]]); string((m-m).rank())", res: value.String("0")},
+		{name: "ss1", exp: "let g=(s+2)/((s+1)*(s+3)); string(tf(ss(g)))", res: value.String("(s+2)/(s^2+4*s+3)")},
+		{name: "ss2", exp: "let sys=ss([[0,1],[-2,-3]],[0,1],[1,0],0); string(sys.tf())", res: value.String("1/(s^2+3*s+2)")},
+		{name: "ss3", exp: "let sys=ss(1/(s+1)); string(sys.feedback(1).tf())", res: value.String("1/(s+2)")},
+		{name: "ss4", exp: "let sys=ss(1/(s+1)); string(sys.series(2/(s+2)).tf())", res: value.String("2/(s^2+3*s+2)")},
+		{name: "simRK45", exp: "let g=1/(s+1); g.simStep(5,0,\"rk45\").last().y", res: value.Float(1 - math.Exp(-5))},
+		{name: "simRK45-2", exp: "let g=1/(s+1); g.sim(t->t, 5, 0.1, \"rk45\").last().y", res: value.Float(4 + math.Exp(-5))},
+		{name: "simStepInfo", exp: "let g=1/(s+1); let i=g.simStepInfo(5,0,\"rk45\"); i.points.size()-i.steps", res: value.Int(1)},
+		{name: "simInfo", exp: "let g=1/(s+1); g.simInfo(t->t, 5, 0.1, \"rk45\").points.last().y", res: value.Float(4 + math.Exp(-5))},
+		{name: "simInfoSS", exp: "ss(1/(s+1)).simStepInfo(5, 0, \"rk45\").steps>0", res: value.Bool(true)},
+		{name: "simEuler", exp: "let g=1/(s+1); g.simStep(5,1e-4,\"euler\").size()", res: value.Int(1001)},
+		{name: "ss5", exp: "let sys=ss([[0,1],[-2,-3]],[0,1],[1,0],0); string(sys.poles())", res: value.String("[-2, -1]")},
+		{name: "residues", exp: "let g=1/((s+1)*(s+2)); string(g.residues().residues)", res: value.String("[1, -1]")},
+		{name: "stepAnalytic", exp: "let g=13/(s^2+4*s+13); g.stepAnalytic().latex", res: value.String("1-1.202e^{-2t}\\sin(3t+0.9828)")},
+		{name: "stepAnalytic2", exp: "let g=1/(s+1); g.stepAnalytic().f(5)", res: value.Float(1 - math.Exp(-5))},
+		{name: "c2d", exp: "let g=1/(s+1); string(g.c2d(0.1,\"tustin\")*21)", res: value.String("(z+1)/(z-0.9047619047619048), T=0.1")},
+		{name: "discrete", exp: "let d=discrete(1/(s-0.5), 0.1); string(d.loop())", res: value.String("1/(z+0.5), T=0.1")},
+		{name: "discreteStep", exp: "let d=discrete(1/(s-0.5), 0.1); d.simStep(1).last().y", res: value.Float(1.998046875)},
+		{name: "jury", exp: "discrete(1/(s^2-s+0.5), 1).jury().stable", res: value.Bool(true)},
+		{name: "impulseAnalytic", exp: "let g=1/(s+1)^2; g.impulseAnalytic().latex", res: value.String("te^{-t}")},
+		{name: "deadTime", exp: "let g=1/(s+1)*deadTime(2); string(g)", res: value.String("1/(s+1)*exp(-2*s)")},
+		{name: "pMargins", exp: "let g=0.5/((s^2+0.02*s+1)*(s+1)); g.pMargins().size()", res: value.Int(2)},
+		{name: "gMargins", exp: "let g=deadTime(1)/s; round(g.gMargins()[1].w180*1000)", res: value.Int(7854)},
+		{name: "modulusMargin", exp: "let g=1/(s*(s+1)); round(g.modulusMargin().Ms*1000)", res: value.Int(1468)},
+		{name: "delayMargin", exp: "let g=deadTime(1)/s; round(g.delayMargin().delayMargin*1000)", res: value.Int(571)},
+		{name: "sensitivity", exp: "let g=1/(s*(s+1)); string(g.sensitivity())", res: value.String("(s^2+s)/(s^2+s+1)")},
+		{name: "compSensitivity", exp: "let g=1/(s*(s+1)); string(g.compSensitivity())", res: value.String("1/(s^2+s+1)")},
+		{name: "dcGain", exp: "(3/((s+1)*(s+2))).dcGain()", res: value.Float(1.5)},
+		{name: "dcGainInf", exp: "(3/(s*(s+2))).dcGain()", res: value.Float(math.Inf(1))},
+		{name: "bandwidth", exp: "round((1/(s+1)).bandwidth().wb*1000)", res: value.Int(998)},
+		{name: "resonance", exp: "let r=(1/(s^2+0.2*s+1)).resonance(); string([round(r.wr*1000), round(r.Mr*1000)])", res: value.String("[990, 5025]")},
+		{name: "naturalFrequencies", exp: "let n=(1/((s^2+s+4)*(s+1))).naturalFrequencies(); string([n.size(), round(n[0].wn*1000), round(n[0].zeta*1000)])", res: value.String("[1, 2000, 250]")},
+		{name: "tunePidZN", exp: "let t=tunePid(1/(s+1)^3); string([round(t.Ku*1000), round(t.kp*1000), round(t.Ti*1000), round(t.Td*1000)])", res: value.String("[8000, 4800, 1814, 453]")},
+		{name: "tunePidCHR", exp: "let t=tunePid(1/(s+1)^3, \"chr\", \"PI\"); string([round(t.Tu*100), round(t.Tg*100), t.construction.size()])", res: value.String("[81, 369, 5]")},
+		{name: "tunePidTSum", exp: "string(tunePid(2/((s+1)*(s+2)), \"tsum\", \"PI\").pid)", res: value.String("(0.375*s+0.5)/(0.75*s)")},
+		{name: "identify", exp: "let r=identify((2/(3*s+1)).simStep(20)); string(r.model.numerator().coef().map(c->round(c*100)/100))+string(r.model.denominator().coef().map(c->round(c*100)/100))", res: value.String("[2][1, 3]")},
+		{name: "identifyTriples", exp: "round(identify([0,0.5,1,1.5,2,2.5,3].map(t->[t,2,2*(1-exp(-t))]),\"PT1\").r2*1000)", res: value.Int(1000)},
+		{name: "fitFrequencyResponse", exp: "let g=2/(3*s+1); let d=[0.1,0.3,1,3,10].map(w->[w, g(cmplx(0,w)).abs(), g(cmplx(0,w)).phase()/pi*180]); let f=fitFrequencyResponse(d, 0, 1); string(f.denominator().coef().map(c->round(c*1000)/1000))+string(f.numerator().coef().map(c->round(c*1000)/1000))", res: value.String("[0.333, 1][0.667]")},
+		{name: "frd", exp: "frd([[1,1,0],[2,0.5,-45]], red, \"meas\").size()", res: value.Int(2)},
+		{name: "hankelSingularValues", exp: "(1/(s+1)).hankelSingularValues()[0]", res: value.Float(0.5)},
+		{name: "reduceOrder", exp: "let r=((s+1)/((s+1)*(s+2))).reduceOrder(1); round(r.dcGain()*1000)", res: value.Int(500)},
+		{name: "hankelSingularValuesHighOrder", exp: "(1/((0.1*s+1)^12*(s+1))).hankelSingularValues().size()", res: value.Int(13)},
+		{name: "reduceOrderHighOrder", exp: "let r=((s+3)/((s+1)*(0.01*s+1)^10)).reduceOrder(2,\"residualize\"); round(r.dcGain()*1000)", res: value.Int(3000)},
+		{name: "reduceOrderSS", exp: "ss(1000/((s+1)*(s+20)*(s+50))).reduceOrder(2, \"residualize\").order()", res: value.Int(2)},
+		{name: "lyap", exp: "lyap([[-1]], [[2]])", res: Matrix{{1}}},
+		{name: "rootInfo", exp: "let r=((s+1)^4*(s+3)).rootInfo(); string(r.map(i->i.multiplicity))", res: value.String("[4, 1]")},
+		{name: "deadTimeMargin", exp: "let g=deadTime(1)/s; g.pMargin().w0", res: value.Float(1)},
+		{name: "pade", exp: "string(deadTime(1).pade(1))", res: value.String("(-0.5*s+1)/(0.5*s+1)")},
+		{name: "stepAnalyticDelay", exp: "let g=1/(s+1)*deadTime(2); g.stepAnalytic().latex", res: value.String("\\left(1-e^{-(t-2)}\\right)\\sigma(t-2)")},
+		{name: "controllability", exp: "ss((s+1)/((s+1)*(s+2))).controllability().rank()", res: value.Int(2)},
+		{name: "observable", exp: "ss((s+1)/((s+1)*(s+2))).isObservable()", res: value.Bool(false)},
+		{name: "kalman", exp: "let sys=ss([[-1,0],[0,-2]],[1,0],[0,1],0); string(sys.kalmanDecomposition().dims)", res: value.String("[0, 1, 1, 0]")},
+		{name: "observableForm", exp: "let g=(s+2)/((s+1)*(s+3)); string(g.observableForm().tf())", res: value.String("(s+2)/(s^2+4*s+3)")},
+		{name: "modalForm", exp: "let g=1/((s+1)*(s+3)); g.modalForm().A[1][1]", res: value.Float(-3)},
+		{name: "place", exp: "string(place(1/(s^2+3*s+2), [-5, -6]).sys)", res: value.String("1/(s^2+11*s+30)")},
+		{name: "placeComplex", exp: "string(place(1/(s^2+3*s+2), [-2+2*j, -2-2*j]).sys)", res: value.String("1/(s^2+4*s+8)")},
+		{name: "prefilter", exp: "place(1/(s^2+3*s+2), poly(30,11,1)).prefilter", res: value.Float(30)},
+		{name: "observer", exp: "let o=observer(1/(s^2+3*s+2), [-10, -10]); o.error.charPoly()(0)", res: value.Float(100)},
+		{name: "routh", exp: "(s^3+2*s^2+3*s+10).routh().rhp", res: value.Int(2)},
+		{name: "routhEpsilon", exp: "(s^4+s^3+2*s^2+2*s+3).routh().latex", res: value.String("\\table[r|rrr]{s^{4}&1&2&3\\\\s^{3}&1&2&0\\\\s^{2}&\\varepsilon&3&0\\\\s^{1}&\\frac{-3}{\\varepsilon}&0&0\\\\s^{0}&3&0&0}")},
+		{name: "routhEpsilonConst", exp: "(s^5+2*s^4+2*s^3+4*s^2+11*s+10).routh().latex", res: value.String("\\table[r|rrr]{s^{5}&1&2&11\\\\s^{4}&2&4&10\\\\s^{3}&\\varepsilon&6&0\\\\s^{2}&\\frac{-12}{\\varepsilon}&10&0\\\\s^{1}&6&0&0\\\\s^{0}&10&0&0}")},
+		{name: "routhOrigin", exp: "let r=(s^2+s).routh(); string([r.origin, r.stable, r.table[2][0]])", res: value.String("[true, false, 0]")},
+		{name: "routhRange", exp: "let r=routhRange(k->(k/(s*(s+1)*(s+2))).loop()); string([r[0][0], round(r[0][1]*1000)])", res: value.String("[0, 6000]")},
+		{name: "polyDiv", exp: "let d=(s^3+2*s+1).div(s+1); string([d.quotient, d.remainder])", res: value.String("[s^2-s+3, -2]")},
+		{name: "polyGcd", exp: "let g=((s+1)*(s+2)).gcd((s+1)*(s+3)); string([g.degree(), round(g.coef()[0]*1000)])", res: value.String("[1, 1000]")},
+		{name: "resultant", exp: "string([(s+1).resultant(s+2), (s^2+3*s+2).resultant(s+2)])", res: value.String("[1, 0]")},
+		{name: "sylvester", exp: "(s^2+3*s+2).sylvester(4*s+5).det()", res: value.Float(-3)},
+		{name: "diophantine", exp: "let d=diophantine(s^2+s, 1, (s+2)^3); string((s^2+s)*d.x+d.y)", res: value.String("s^3+6*s^2+12*s+8")},
+		{name: "reduceTol", exp: "let G=(s+1.0001)/((s+1)*(s+2)); G.reduce(1e-3).denominator().degree()", res: value.Int(1)},
+		{name: "tfMatrix", exp: "let G=tf([[1/(s+1), 2/(s+3)],[1/(s+1), 1/(s+1)]]); string(G[0][1])", res: value.String("2/(s+3)")},
+		{name: "tfMatrixMul", exp: "let G=tf([[1/(s+1), 2]]); string((G*matrix([[1],[1]]))[0][0])", res: value.String("(2*s+3)/(s+1)")},
+		{name: "tfMatrixFeedback", exp: "let G=tf([[1/(s+1)]]); string(G.feedback(2)[0][0])", res: value.String("1/(s+3)")},
+		{name: "tfMatrixLoopDiag", exp: "string(tf([[1/(s+1), 0],[0, 2/(s+2)]]).loop())", res: value.String("[[1/(s+2), 0], [0, 2/(s+4)]]")},
+		{name: "tfMatrixLoop", exp: "let G=tf([[2/(10*s+1), 0.5/(5*s+1)],[0.8/(8*s+1), 1.5/(12*s+1)]]); let K=tf([[pid(2,5), 0],[0, pid(3,8)]]); (G*K).loop().dcGain()[0][1]", res: value.Float(0)},
+		{name: "tfMatrixSim", exp: "let G=tf([[2/(10*s+1), 0.5/(5*s+1)],[0.8/(8*s+1), 1.5/(12*s+1)]]); G.sim([t->1, t->0], 200, 0.1, \"rk4\")[1].last().y", res: value.Float(0.8)},
+		{name: "tfMatrixSimList", exp: "let G=tf([[2/(10*s+1), 0.5/(5*s+1)],[0.8/(8*s+1), 1.5/(12*s+1)]]); G.sim(t->[0, 1], 200, 0.1, \"rk4\")[0].last().y", res: value.Float(0.5)},
+		{name: "tfMatrixRga", exp: "let G=tf([[2/(10*s+1), 0.5/(5*s+1)],[0.8/(8*s+1), 1.5/(12*s+1)]]); G.rga()[0][0]", res: value.Float(1 / (1 - 0.4/3))},
+		{name: "tfMatrixZerosDiag", exp: "tf([[1/(s+1), 0],[0, (s+1)/(s+2)]]).zeros()[0]", res: value.Float(-1)},
+		{name: "tfMatrixZeros", exp: "tf([[1/(s+1), 2/(s+3)],[1/(s+1), 1/(s+1)]]).zeros()[0]", res: value.Float(1)},
+		{name: "tfMatrixSS", exp: "tf(ss(tf([[1/(s+1), 2/(s+3)],[1/(s+1), 1/(s+1)]]))).outputs()", res: value.Int(2)},
+		{name: "feedback", exp: "string(feedback(2/(s+1), 1/(0.1*s+1)))", res: value.String("(0.2*s+2)/(0.1*s^2+1.1*s+3)")},
+		{name: "feedbackMethod", exp: "string((2/(s+1)).feedback(1/(0.1*s+1), 1))", res: value.String("(0.2*s+2)/(0.1*s^2+1.1*s-1)")},
+		{name: "feedbackSS", exp: "feedback(ss(1/(s+1)), ss(2/(s+1))).order()", res: value.Int(2)},
+		{name: "feedbackTfMatrix", exp: "feedback(tf([[1/(s+1)]]), matrix([[2]])).dcGain()[0][0]", res: value.Float(1.0 / 3)},
+		{name: "controlLoop", exp: "let cl=controlLoop(1/((s+1)*(s+2)), pid(2,3)); cl.S(0)+cl.T(0)", res: value.Float(1)},
+		{name: "controlLoopSensor", exp: "let cl=controlLoop(1/((s+1)*(s+2)), pid(2,3), 1/(0.05*s+1)); cl.RY(0)", res: value.Float(1)},
+		{name: "controlLoopKS", exp: "let cl=controlLoop(1/((s+1)*(s+2)), pid(2,3), 1/(0.05*s+1)); cl.KS(0)", res: value.Float(2)},
+		{name: "sym", exp: "let k=sym(\"k\"); string((k/(s*(s+k))).loop())", res: value.String("k/(s^2+k*s+k)")},
+		{name: "symLaTeX", exp: "let k=sym(\"k\"); (k/(s*(s+k))).loop().denominator().toLaTeX()", res: value.String("s^{2}+k s+k")},
+		{name: "symSubst", exp: "let k=sym(\"k\"); string((k/(s*(s+k))).subst(\"k\", 2))", res: value.String("2/(s^2+2*s)")},
+		{name: "symSubstMap", exp: "let k=sym(\"k\"); let T=sym(\"T\"); string((k/(T*s+1)).subst({k:2}).symbols())", res: value.String("[T]")},
+		{name: "symRouth", exp: "let k=sym(\"k\"); let r=(s^3+3*s^2+2*s+k).routh(); string([r.ranges[0][0], round(r.ranges[0][1]*1000)])", res: value.String("[0, 6000]")},
+		{name: "symRouthOrigin", exp: "let k=sym(\"k\"); string((s^2+k*s).routh().firstColumn)", res: value.String("[1, k, 0]")},
+		{name: "symRouthRange", exp: "let k=sym(\"k\"); let r=routhRange((k/(s*(s+1)*(s+2))).loop()); string([r[0][0], round(r[0][1]*1000)])", res: value.String("[0, 6000]")},
+		{name: "stepInfo", exp: "round((1/(s^2+s+1)).stepInfo().overshoot*10)", res: value.Int(163)},
+		{name: "simRK4LargeStep", exp: "let p=(1/(s+1)).simStep(5,10,\"rk4\"); string([p.size(), p.last().x])", res: value.String("[11, 5]")},
+		{name: "stepInfoList", exp: "round((1/(s+1)).simStep(10,0,\"rk4\").stepInfo().riseTime*100)", res: value.Int(220)},
+		{name: "nyquistCriterion", exp: "let n=(6*(s+1)/((s-1)*(s-3))).nyquistCriterion(); string([n.P, n.N, n.Z, n.stable])", res: value.String("[2, -2, 0, true]")},
+		{name: "nyquistCriterion2", exp: "let n=(10/(s*(s+1)*(s+2))).nyquistCriterion(); string([n.P, n.N, n.Z, n.imagPoles])", res: value.String("[0, 2, 2, 1]")},
+		{name: "nichols", exp: "(1/(s*(s+1))).nichols(red, \"G\").size()", res: value.Int(3)},
+		{name: "nicholsNoGrid", exp: "(1/(s*(s+1))).nichols(red, \"G\", false).size()", res: value.Int(2)},
+		{name: "mCircles", exp: "string(mCircles([-3, 0, 3]))", res: value.String("M-Circles")},
+		{name: "nCircles", exp: "string(nCircles())", res: value.String("N-Circles")},
+		{name: "lqr", exp: "let k=lqr(1/s^2, [[1,0],[0,1]], 1).K; round(k[0][1]^2*1000)", res: value.Int(3000)},
+		{name: "lqrOutput", exp: "round(lqr(1/s^2, 1, 1).prefilter*1000)", res: value.Int(1000)},
+		{name: "kalman", exp: "let k=kalman(1/s^2, 1, 1).L; round(k[0][0]^2*1000)", res: value.Int(2000)},
 	}
 
 	for _, test := range tests {
@@ -396,6 +505,22 @@ func Test_toUniCode(t *testing.T) {
 	}
 }
 
+func TestSymSubstZero(t *testing.T) {
+	fu, _, err := Parser.Generate("let k=sym(\"k\"); (s/k).subst(\"k\", 0)")
+	assert.NoError(t, err)
+	_, err = fu(funcGen.NewEmptyStack[value.Value]())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "division by zero")
+}
+
+func TestDiscreteDeadTime(t *testing.T) {
+	fu, _, err := Parser.Generate("discrete(1/(s-0.5)*deadTime(0.2), 0.1)")
+	assert.NoError(t, err)
+	_, err = fu(funcGen.NewEmptyStack[value.Value]())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "dead time")
+}
+
 func TestErrorBandEntrance(t *testing.T) {
 	tests := []struct {
 		name string
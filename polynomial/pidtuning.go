@@ -0,0 +1,321 @@
+package polynomial
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hneemann/control/graph"
+	"math"
+	"sort"
+)
+
+// TuningMethod selects the rule used to tune a PID controller
+type TuningMethod int
+
+const (
+	// ZieglerNichols uses the ultimate gain and the period of the oscillation at the stability limit
+	ZieglerNichols TuningMethod = iota
+	// ChienHronesReswick uses the tangent at the inflection point of the step response
+	ChienHronesReswick
+	// SIMC is the Skogestad internal model control rule which uses the half rule to obtain a model
+	SIMC
+	// TSum is the T-sum rule of Kuhn
+	TSum
+)
+
+func (m TuningMethod) String() string {
+	switch m {
+	case ChienHronesReswick:
+		return "chr"
+	case SIMC:
+		return "simc"
+	case TSum:
+		return "tsum"
+	default:
+		return "zn"
+	}
+}
+
+// TuningMethodByName returns the tuning method with the given name
+func TuningMethodByName(name string) (TuningMethod, error) {
+	switch name {
+	case "zn":
+		return ZieglerNichols, nil
+	case "chr":
+		return ChienHronesReswick, nil
+	case "simc":
+		return SIMC, nil
+	case "tsum":
+		return TSum, nil
+	default:
+		return ZieglerNichols, fmt.Errorf("unknown method '%s', allowed are 'zn', 'chr', 'simc' and 'tsum'", name)
+	}
+}
+
+// ControllerType is the type of controller to tune
+type ControllerType int
+
+const (
+	ControllerP ControllerType = iota
+	ControllerPI
+	ControllerPID
+)
+
+// ControllerTypeByName returns the controller type with the given name
+func ControllerTypeByName(name string) (ControllerType, error) {
+	switch name {
+	case "P":
+		return ControllerP, nil
+	case "PI":
+		return ControllerPI, nil
+	case "PID":
+		return ControllerPID, nil
+	default:
+		return ControllerPID, fmt.Errorf("unknown controller '%s', allowed are 'P', 'PI' and 'PID'", name)
+	}
+}
+
+// PIDTuning contains the parameters of a tuned controller. A controller
+// without integral part has an infinite Ti.
+type PIDTuning struct {
+	Kp, Ti, Td float64
+	// Values contains the characteristic values of the plant used by the rule
+	Values map[string]float64
+	// Tangent is the tangent construction if the rule is based on the step response
+	Tangent *StepTangent
+}
+
+// Controller returns the transfer function of the tuned controller
+func (t *PIDTuning) Controller() (*Linear, error) {
+	if math.IsInf(t.Ti, 1) {
+		return &Linear{
+			Numerator:   Polynomial{t.Kp, t.Kp * t.Td}.Canonical(),
+			Denominator: Polynomial{1},
+		}, nil
+	}
+	return PID(t.Kp, t.Ti, t.Td, 0)
+}
+
+// StepTangent is the tangent at the inflection point of the step response
+type StepTangent struct {
+	// Ks is the static gain
+	Ks float64
+	// Tu is the delay time at which the tangent crosses zero
+	Tu float64
+	// Tg is the time the tangent needs to rise from zero to Ks
+	Tg float64
+	// Inflection is the inflection point of the step response
+	Inflection graph.Point
+}
+
+// StepTangent calculates the tangent at the inflection point of the step response
+func (l *Linear) StepTangent() (*StepTangent, error) {
+	t, y, err := l.sampleStep()
+	if err != nil {
+		return nil, err
+	}
+	ks := l.DCGain()
+	if ks == 0 {
+		return nil, errors.New("the static gain is zero")
+	}
+	sign := 1.0
+	if ks < 0 {
+		sign = -1
+	}
+	best := 1
+	bestSlope := 0.0
+	for i := 1; i < len(t)-1; i++ {
+		slope := sign * (y[i+1] - y[i-1]) / (t[i+1] - t[i-1])
+		if slope > bestSlope {
+			best = i
+			bestSlope = slope
+		}
+	}
+	if bestSlope <= 0 {
+		return nil, errors.New("the step response has no inflection point")
+	}
+	m := sign * bestSlope
+	return &StepTangent{
+		Ks:         ks,
+		Tu:         t[best] - y[best]/m,
+		Tg:         ks / m,
+		Inflection: graph.Point{X: t[best], Y: y[best]},
+	}, nil
+}
+
+// ChartContent returns the tangent construction which can be added to a plot of the step response
+func (st *StepTangent) ChartContent() []graph.ChartContent {
+	end := st.Tu + st.Tg
+	y := -0.1 * st.Ks
+	return []graph.ChartContent{
+		graph.Scatter{
+			Points:         graph.PointsFromSlice(graph.Point{X: st.Tu, Y: 0}, graph.Point{X: end, Y: st.Ks}),
+			ShapeLineStyle: graph.ShapeLineStyle{LineStyle: graph.Red},
+			Title:          "tangent",
+		},
+		graph.Scatter{
+			Points:         graph.PointsFromSlice(graph.Point{X: 0, Y: st.Ks}, graph.Point{X: end, Y: st.Ks}),
+			ShapeLineStyle: graph.ShapeLineStyle{LineStyle: graph.Gray.SetDash(4, 4)},
+		},
+		graph.Scatter{
+			Points:         graph.PointsFromPoint(st.Inflection),
+			ShapeLineStyle: graph.ShapeLineStyle{Shape: graph.NewCircleMarker(4), ShapeStyle: graph.Red},
+		},
+		graph.Arrow{From: graph.Point{X: 0, Y: y}, To: graph.Point{X: st.Tu, Y: y}, Style: graph.Black, Label: "Tu", Mode: 3},
+		graph.Arrow{From: graph.Point{X: st.Tu, Y: y}, To: graph.Point{X: end, Y: y}, Style: graph.Black, Label: "Tg", Mode: 3},
+	}
+}
+
+// TunePID tunes a controller for the plant by the given rule
+func (l *Linear) TunePID(method TuningMethod, ct ControllerType) (*PIDTuning, error) {
+	switch method {
+	case ChienHronesReswick:
+		return l.tuneCHR(ct)
+	case SIMC:
+		return l.tuneSIMC(ct)
+	case TSum:
+		return l.tuneTSum(ct)
+	default:
+		return l.tuneZieglerNichols(ct)
+	}
+}
+
+func (l *Linear) tuneZieglerNichols(ct ControllerType) (*PIDTuning, error) {
+	w180, gm, err := l.GMargin()
+	if err != nil {
+		return nil, fmt.Errorf("the ultimate gain could not be determined: %w", err)
+	}
+	ku := math.Pow(10, gm/20)
+	pu := 2 * math.Pi / w180
+	t := &PIDTuning{Values: map[string]float64{"Ku": ku, "Pu": pu}}
+	switch ct {
+	case ControllerP:
+		t.Kp, t.Ti = 0.5*ku, math.Inf(1)
+	case ControllerPI:
+		t.Kp, t.Ti = 0.45*ku, pu/1.2
+	default:
+		t.Kp, t.Ti, t.Td = 0.6*ku, 0.5*pu, 0.125*pu
+	}
+	return t, nil
+}
+
+// tuneCHR uses the rule of Chien, Hrones and Reswick for an aperiodic reference response
+func (l *Linear) tuneCHR(ct ControllerType) (*PIDTuning, error) {
+	st, err := l.StepTangent()
+	if err != nil {
+		return nil, err
+	}
+	if st.Tu <= 0 {
+		return nil, errors.New("the delay time of the step response needs to be greater than zero")
+	}
+	k := st.Tg / (st.Ks * st.Tu)
+	t := &PIDTuning{Values: map[string]float64{"Ks": st.Ks, "Tu": st.Tu, "Tg": st.Tg}, Tangent: st}
+	switch ct {
+	case ControllerP:
+		t.Kp, t.Ti = 0.3*k, math.Inf(1)
+	case ControllerPI:
+		t.Kp, t.Ti = 0.35*k, 1.2*st.Tg
+	default:
+		t.Kp, t.Ti, t.Td = 0.6*k, st.Tg, 0.5*st.Tu
+	}
+	return t, nil
+}
+
+// tuneSIMC uses the SIMC rule of Skogestad. The plant is approximated by a first or second
+// order system with dead time using the half rule. The closed loop time constant is
+// chosen equal to the dead time.
+func (l *Linear) tuneSIMC(ct ControllerType) (*PIDTuning, error) {
+	if l.Numerator.Degree() > 0 {
+		return nil, errors.New("the SIMC rule requires a plant without zeros")
+	}
+	poles, err := l.Poles()
+	if err != nil {
+		return nil, err
+	}
+	var tau []float64
+	for _, p := range poles.roots {
+		if math.Abs(imag(p)) > 1e-3*math.Abs(real(p)) || real(p) >= 0 {
+			return nil, errors.New("the SIMC rule requires a plant with real and stable poles")
+		}
+		tau = append(tau, -1/real(p))
+		if imag(p) != 0 {
+			// a multiple real pole is split into a pair of complex poles by rounding errors
+			tau = append(tau, -1/real(p))
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(tau)))
+	tauAt := func(i int) float64 {
+		if i < len(tau) {
+			return tau[i]
+		}
+		return 0
+	}
+
+	// half rule
+	keep := 1
+	if ct == ControllerPID {
+		keep = 2
+	}
+	theta := l.Delay + tauAt(keep)/2
+	for i := keep + 1; i < len(tau); i++ {
+		theta += tau[i]
+	}
+	tau1 := tauAt(0)
+	tau2 := tauAt(1)
+	if keep == 1 {
+		tau1 += tau2 / 2
+		tau2 = 0
+	} else {
+		tau2 += tauAt(2) / 2
+	}
+	if theta <= 0 {
+		return nil, errors.New("the SIMC rule requires a plant with a dead time or at least three poles")
+	}
+
+	k := l.DCGain()
+	tc := theta
+	t := &PIDTuning{Values: map[string]float64{"K": k, "tau1": tau1, "tau2": tau2, "theta": theta}}
+	kc := tau1 / (k * (tc + theta))
+	ti := math.Min(tau1, 4*(tc+theta))
+	switch ct {
+	case ControllerP:
+		t.Kp, t.Ti = kc, math.Inf(1)
+	case ControllerPI:
+		t.Kp, t.Ti = kc, ti
+	default:
+		// conversion of the series form to the parallel form
+		td := tau2
+		t.Kp = kc * (1 + td/ti)
+		t.Ti = ti + td
+		t.Td = ti * td / (ti + td)
+	}
+	return t, nil
+}
+
+// tuneTSum uses the T-sum rule of Kuhn for a normal response. The sum of time constants
+// is obtained from the linear coefficients of the transfer function.
+func (l *Linear) tuneTSum(ct ControllerType) (*PIDTuning, error) {
+	if l.Numerator[0] == 0 || l.Denominator[0] == 0 {
+		return nil, errors.New("the T-sum rule requires a plant with a finite static gain which is not zero")
+	}
+	coef := func(p Polynomial, i int) float64 {
+		if i < len(p) {
+			return p[i]
+		}
+		return 0
+	}
+	ks := l.DCGain()
+	ts := coef(l.Denominator, 1)/l.Denominator[0] - coef(l.Numerator, 1)/l.Numerator[0] + l.Delay
+	if ts <= 0 {
+		return nil, errors.New("the sum of time constants needs to be greater than zero")
+	}
+	t := &PIDTuning{Values: map[string]float64{"Ks": ks, "Tsum": ts}}
+	switch ct {
+	case ControllerP:
+		t.Kp, t.Ti = 1/ks, math.Inf(1)
+	case ControllerPI:
+		t.Kp, t.Ti = 0.5/ks, 0.5*ts
+	default:
+		t.Kp, t.Ti, t.Td = 1/ks, 0.66*ts, 0.167*ts
+	}
+	return t, nil
+}
@@ -0,0 +1,59 @@
+package polynomial
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestLinear_StepTangent(t *testing.T) {
+	// the step response of 1/(s+1)² has its inflection point at t=1
+	l := &Linear{Numerator: Polynomial{2}, Denominator: Polynomial{1, 2, 1}}
+	st, err := l.StepTangent()
+	assert.NoError(t, err)
+	assert.InDelta(t, 2, st.Ks, 1e-9)
+	assert.InDelta(t, 1, st.Inflection.X, 1e-3)
+	m := 2 / math.E
+	assert.InDelta(t, 2/m, st.Tg, 1e-4)
+	assert.InDelta(t, 1-2*(1-2/math.E)/m, st.Tu, 1e-4)
+}
+
+func TestLinear_TunePID(t *testing.T) {
+	l := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 3, 3, 1}}
+	tests := []struct {
+		method     TuningMethod
+		ct         ControllerType
+		kp, ti, td float64
+	}{
+		{ZieglerNichols, ControllerP, 4, math.Inf(1), 0},
+		{ZieglerNichols, ControllerPI, 3.6, 2 * math.Pi / math.Sqrt(3) / 1.2, 0},
+		{ZieglerNichols, ControllerPID, 4.8, math.Pi / math.Sqrt(3), math.Pi / math.Sqrt(3) / 4},
+		{SIMC, ControllerPI, 0.5, 1.5, 0},
+		{SIMC, ControllerPID, 2.5, 2.5, 0.6},
+		{TSum, ControllerP, 1, math.Inf(1), 0},
+		{TSum, ControllerPI, 0.5, 1.5, 0},
+		{TSum, ControllerPID, 1, 1.98, 0.501},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method.String(), func(t *testing.T) {
+			tuning, err := l.TunePID(tt.method, tt.ct)
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.kp, tuning.Kp, 1e-4)
+			if math.IsInf(tt.ti, 1) {
+				assert.True(t, math.IsInf(tuning.Ti, 1))
+			} else {
+				assert.InDelta(t, tt.ti, tuning.Ti, 1e-4)
+			}
+			assert.InDelta(t, tt.td, tuning.Td, 1e-4)
+
+			c, err := tuning.Controller()
+			assert.NoError(t, err)
+			assert.NotNil(t, c)
+		})
+	}
+
+	tuning, err := l.TunePID(ChienHronesReswick, ControllerPID)
+	assert.NoError(t, err)
+	assert.NotNil(t, tuning.Tangent)
+	assert.InDelta(t, 0.6*tuning.Tangent.Tg/tuning.Tangent.Tu, tuning.Kp, 1e-9)
+}
@@ -0,0 +1,130 @@
+package polynomial
+
+import (
+	"errors"
+	"fmt"
+)
+
+// EvalMatrix evaluates the polynomial at the square matrix m using the horner scheme
+func (p Polynomial) EvalMatrix(m Matrix) (Matrix, error) {
+	if !m.IsSquare() {
+		return nil, errors.New("matrix is not square")
+	}
+	n := m.Rows()
+	r := NewMatrix(n, n)
+	for i := len(p) - 1; i >= 0; i-- {
+		r = mul(r, m, n)
+		for j := 0; j < n; j++ {
+			r[j][j] += p[i]
+		}
+	}
+	return r, nil
+}
+
+// checkCharPoly checks if the given characteristic polynomial matches the order of the system
+func (s *StateSpace) checkCharPoly(p Polynomial) (Polynomial, error) {
+	p = p.Canonical()
+	if p.Degree() != s.Order() {
+		return nil, fmt.Errorf("%d poles are required, but %d are given", s.Order(), p.Degree())
+	}
+	p, _ = p.Normalize()
+	return p, nil
+}
+
+// Place calculates the state feedback gain K using the formula of Ackermann,
+// so that the matrix A-BK has the characteristic polynomial p.
+func (s *StateSpace) Place(p Polynomial) (Matrix, error) {
+	if s.Inputs() != 1 {
+		return nil, errors.New("pole placement requires a system with a single input")
+	}
+	p, err := s.checkCharPoly(p)
+	if err != nil {
+		return nil, err
+	}
+	n := s.Order()
+	pa, err := p.EvalMatrix(s.A)
+	if err != nil {
+		return nil, err
+	}
+	// K = [0 ... 0 1] Qc⁻¹ p(A), the last row of Qc⁻¹ is obtained by solving Qcᵀ x = eₙ
+	en := NewMatrix(n, 1)
+	en[n-1][0] = 1
+	x, err := s.Controllability().Transpose().Solve(en)
+	if err != nil {
+		return nil, errors.New("the system is not controllable")
+	}
+	return mul(x.Transpose(), pa, n), nil
+}
+
+// Observer calculates the gain L of the Luenberger observer using the formula of
+// Ackermann, so that the matrix A-LC has the characteristic polynomial p.
+func (s *StateSpace) Observer(p Polynomial) (Matrix, error) {
+	if s.Outputs() != 1 {
+		return nil, errors.New("the observer design requires a system with a single output")
+	}
+	p, err := s.checkCharPoly(p)
+	if err != nil {
+		return nil, err
+	}
+	n := s.Order()
+	pa, err := p.EvalMatrix(s.A)
+	if err != nil {
+		return nil, err
+	}
+	// L = p(A) Qo⁻¹ [0 ... 0 1]ᵀ
+	en := NewMatrix(n, 1)
+	en[n-1][0] = 1
+	x, err := s.Observability().Solve(en)
+	if err != nil {
+		return nil, errors.New("the system is not observable")
+	}
+	return mul(pa, x, 1), nil
+}
+
+// StateFeedback returns the closed loop system with the control law u=r-Kx
+func (s *StateSpace) StateFeedback(k Matrix) (*StateSpace, error) {
+	n := s.Order()
+	if k.Rows() != s.Inputs() || k.Cols() != n {
+		return nil, fmt.Errorf("the gain needs to be a %dx%d matrix", s.Inputs(), n)
+	}
+	a, err := s.A.SubMatrix(mul(s.B, k, n))
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.C.SubMatrix(mul(s.D, k, n))
+	if err != nil {
+		return nil, err
+	}
+	return NewStateSpace(a, s.B.Copy(), c, s.D.Copy())
+}
+
+// ObserverError returns the error dynamics e'=(A-LC)e of the observer with the gain L
+func (s *StateSpace) ObserverError(l Matrix) (Matrix, error) {
+	n := s.Order()
+	if l.Rows() != n || l.Cols() != s.Outputs() {
+		return nil, fmt.Errorf("the observer gain needs to be a %dx%d matrix", n, s.Outputs())
+	}
+	return s.A.SubMatrix(mul(l, s.C, n))
+}
+
+// ObserverController returns the dynamic controller which consists of the observer
+// with the gain L and the state feedback with the gain K. The input of the controller
+// is the control error e=-y, the output is the actuating variable u, so that the
+// controller can be placed in the forward path of the loop.
+func (s *StateSpace) ObserverController(k, l Matrix) (*StateSpace, error) {
+	n := s.Order()
+	if k.Rows() != s.Inputs() || k.Cols() != n {
+		return nil, fmt.Errorf("the gain needs to be a %dx%d matrix", s.Inputs(), n)
+	}
+	ae, err := s.ObserverError(l)
+	if err != nil {
+		return nil, err
+	}
+	// x̂' = (A-BK-LC+LDK)x̂ + Ly, u = -Kx̂
+	bk := mul(add(s.B, mul(l, s.D, s.Inputs()).MulFloat(-1)), k, n)
+	a, err := ae.SubMatrix(bk)
+	if err != nil {
+		return nil, err
+	}
+	return NewStateSpace(a, l.Copy(), k.Copy(), NewMatrix(s.Inputs(), s.Outputs()))
+}
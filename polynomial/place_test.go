@@ -0,0 +1,76 @@
+package polynomial
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStateSpace_Place(t *testing.T) {
+	lin := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{2, 3, 1}}
+	sys, err := lin.StateSpace()
+	assert.NoError(t, err)
+
+	desired := NewRoots(-5, -6).Polynomial()
+	k, err := sys.Place(desired)
+	assert.NoError(t, err)
+	assert.InDelta(t, 28, k[0][0], 1e-9)
+	assert.InDelta(t, 8, k[0][1], 1e-9)
+
+	closed, err := sys.StateFeedback(k)
+	assert.NoError(t, err)
+	cp, err := closed.A.CharPoly()
+	assert.NoError(t, err)
+	assertPolyEqual(t, desired, cp)
+
+	_, err = sys.Place(NewRoots(-5).Polynomial())
+	assert.Error(t, err)
+}
+
+func TestStateSpace_PlaceModal(t *testing.T) {
+	// a system which is not in the controllable canonical form
+	sys, err := NewStateSpace(Matrix{{-1, 0}, {1, -2}}, Matrix{{1}, {0}}, Matrix{{0, 1}}, Matrix{{0}})
+	assert.NoError(t, err)
+
+	desired := NewRoots(complex(-3, 2)).Polynomial()
+	k, err := sys.Place(desired)
+	assert.NoError(t, err)
+	closed, err := sys.StateFeedback(k)
+	assert.NoError(t, err)
+	cp, err := closed.A.CharPoly()
+	assert.NoError(t, err)
+	assertPolyEqual(t, desired, cp)
+
+	// the second state is not controllable
+	sys, err = NewStateSpace(Matrix{{-1, 0}, {0, -2}}, Matrix{{1}, {0}}, Matrix{{1, 1}}, Matrix{{0}})
+	assert.NoError(t, err)
+	_, err = sys.Place(desired)
+	assert.Error(t, err)
+}
+
+func TestStateSpace_Observer(t *testing.T) {
+	sys, err := NewStateSpace(Matrix{{-1, 0}, {1, -2}}, Matrix{{1}, {0}}, Matrix{{0, 1}}, Matrix{{0}})
+	assert.NoError(t, err)
+
+	desired := NewRoots(-10, -11).Polynomial()
+	l, err := sys.Observer(desired)
+	assert.NoError(t, err)
+	e, err := sys.ObserverError(l)
+	assert.NoError(t, err)
+	cp, err := e.CharPoly()
+	assert.NoError(t, err)
+	assertPolyEqual(t, desired, cp)
+
+	// separation principle: the closed loop has the poles of the state feedback and of the observer
+	pk := NewRoots(-3, -4).Polynomial()
+	k, err := sys.Place(pk)
+	assert.NoError(t, err)
+	c, err := sys.ObserverController(k, l)
+	assert.NoError(t, err)
+	closed, err := c.Series(sys)
+	assert.NoError(t, err)
+	closed, err = closed.Feedback(&StateSpace{A: Matrix{}, B: Matrix{}, C: Matrix{{}}, D: Matrix{{1}}}, -1)
+	assert.NoError(t, err)
+	cp, err = closed.A.CharPoly()
+	assert.NoError(t, err)
+	assertPolyEqual(t, desired.Mul(pk), cp)
+}
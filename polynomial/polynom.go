@@ -47,6 +47,11 @@ func (p Polynomial) EvalCplx(s complex128) complex128 {
 }
 
 func (p Polynomial) String() string {
+	return p.stringVar("s")
+}
+
+// stringVar creates the string representation using the given variable name
+func (p Polynomial) stringVar(v string) string {
 	if p.IsZero() {
 		return "0"
 	}
@@ -54,13 +59,13 @@ func (p Polynomial) String() string {
 	for i := range p {
 		n := len(p) - i - 1
 		c := p[n]
-		if math.Abs(c) > eps {
+		if math.Abs(c) > eps || (i == 0 && c != 0) {
 			neg := false
 			if c < 0 {
 				neg = true
 			}
 			c = math.Abs(c)
-			if neg || i > 0 {
+			if neg || result != "" {
 				if neg {
 					result += "-"
 				} else {
@@ -76,9 +81,9 @@ func (p Polynomial) String() string {
 			switch n {
 			case 0:
 			case 1:
-				result += "s"
+				result += v
 			default:
-				result += fmt.Sprintf("s^%d", n)
+				result += fmt.Sprintf("%s^%d", v, n)
 			}
 		}
 	}
@@ -134,6 +139,10 @@ func (p Polynomial) ToHtml(_ funcGen.Stack[value.Value], w *xmlWriter.XMLWriter)
 }
 
 func (p Polynomial) ToMathML(w *xmlWriter.XMLWriter) {
+	p.toMathMLVar(w, "s")
+}
+
+func (p Polynomial) toMathMLVar(w *xmlWriter.XMLWriter, v string) {
 	if p.IsZero() {
 		w.Open("mn").Write("0").Close()
 		return
@@ -157,10 +166,10 @@ func (p Polynomial) ToMathML(w *xmlWriter.XMLWriter) {
 			switch n {
 			case 0:
 			case 1:
-				w.Open("mi").Write("s").Close()
+				w.Open("mi").Write(v).Close()
 			default:
 				w.Open("msup")
-				w.Open("mi").Write("s").Close()
+				w.Open("mi").Write(v).Close()
 				w.Open("mn").Write(strconv.Itoa(n)).Close()
 				w.Close()
 			}
@@ -170,6 +179,10 @@ func (p Polynomial) ToMathML(w *xmlWriter.XMLWriter) {
 }
 
 func (p Polynomial) ToLaTeX(w *bytes.Buffer) {
+	p.toLaTeXVar(w, "s")
+}
+
+func (p Polynomial) toLaTeXVar(w *bytes.Buffer, v string) {
 	if p.IsZero() {
 		w.WriteString("0")
 		return
@@ -192,9 +205,9 @@ func (p Polynomial) ToLaTeX(w *bytes.Buffer) {
 			switch n {
 			case 0:
 			case 1:
-				w.WriteRune('s')
+				w.WriteString(v)
 			default:
-				w.WriteString(fmt.Sprintf("s^{%d}", n))
+				w.WriteString(fmt.Sprintf("%s^{%d}", v, n))
 			}
 		}
 	}
@@ -224,6 +237,9 @@ func (p Polynomial) Add(q Polynomial) Polynomial {
 	}
 }
 
+// Mul multiplies two polynomials. The leading coefficient of the product is not
+// affected by cancellation, so it is kept even if it is very small. Otherwise,
+// the degree of a power like (0.1s+1)^12 would be wrong.
 func (p Polynomial) Mul(q Polynomial) Polynomial {
 	result := make(Polynomial, p.Degree()+q.Degree()+1)
 	for i := range p {
@@ -231,7 +247,10 @@ func (p Polynomial) Mul(q Polynomial) Polynomial {
 			result[i+j] += p[i] * q[j]
 		}
 	}
-	return result.Canonical()
+	if result[len(result)-1] == 0 {
+		return result.Canonical()
+	}
+	return result
 }
 
 func (p Polynomial) MulFloat(f float64) Polynomial {
@@ -382,8 +401,9 @@ func NewRoots(roots ...complex128) Roots {
 // Roots calculates the roots of the polynomial p. It returns a Roots struct
 // containing the roots and the leading coefficient of the polynomial.
 // If there are complex roots, only the root with the positive imaginary part is returned.
+// Multiple roots are contained several times.
 func (p Polynomial) Roots() (Roots, error) {
-	return p.rootsNewton()
+	return p.rootsEigen()
 }
 
 func (p Polynomial) rootsNewton() (Roots, error) {
@@ -755,6 +775,7 @@ func (r Roots) NumComplex() int {
 	return n
 }
 
+// Equals checks if both contain the same roots. The order of the roots is not relevant.
 func (r Roots) Equals(b Roots) bool {
 	if math.Abs(r.factor-b.factor) > eps {
 		return false
@@ -762,12 +783,8 @@ func (r Roots) Equals(b Roots) bool {
 	if len(r.roots) != len(b.roots) {
 		return false
 	}
-	for i := range r.roots {
-		if !Equals(r.roots[i], b.roots[i]) {
-			return false
-		}
-	}
-	return true
+	_, rest, _ := r.reduce(b)
+	return len(rest.roots) == 0
 }
 
 func (r Roots) reduce(poles Roots) (Roots, Roots, bool) {
@@ -42,6 +42,7 @@ func TestPolynom_String(t *testing.T) {
 		{"small", Polynomial{-1, -2e-6, -3}, "-3*s^2-2e-06*s-1"},
 		{"one", Polynomial{-1, -2, 1}, "s^2-2*s-1"},
 		{"oneN", Polynomial{-1, -2, -1}, "-s^2-2*s-1"},
+		{"tinyLeading", Polynomial{1, 2, 1e-12}, "1e-12*s^2+2*s+1"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -83,6 +84,12 @@ func TestPolynom_Operation(t *testing.T) {
 	}
 }
 
+func TestPolynom_MulSmallLeading(t *testing.T) {
+	p := Polynomial{1, 0.1}.Pow(12)
+	assert.Equal(t, 12, p.Degree())
+	assert.InDelta(t, 1e-12, p[12], 1e-24)
+}
+
 func TestPolynom_Div(t *testing.T) {
 	tests := []struct {
 		name      string
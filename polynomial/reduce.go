@@ -0,0 +1,674 @@
+package polynomial
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// Lyapunov solves the continuous lyapunov equation
+//
+//	AX + XAᵀ + Q = 0
+//
+// for a stable matrix A. The equation is transformed to the discrete stein
+// equation X = AdXAdᵀ + Qd by a cayley transformation which is then solved by
+// the squared smith iteration.
+func Lyapunov(a, q Matrix) (Matrix, error) {
+	n := a.Rows()
+	if !a.IsSquare() {
+		return nil, errors.New("the matrix A needs to be square")
+	}
+	if q.Rows() != n || q.Cols() != n {
+		return nil, fmt.Errorf("the matrix Q needs to be a %dx%d matrix", n, n)
+	}
+	ak, f, h, err := cayley(a)
+	if err != nil {
+		return nil, err
+	}
+	x := mul(mul(f, q, n), f.Transpose(), n).MulFloat(2 * h)
+	for range lyapunovIterations {
+		if converged, err := smithConverged(ak); err != nil {
+			return nil, err
+		} else if converged {
+			symmetrize(x)
+			return x, nil
+		}
+		x = add(x, mul(mul(ak, x, n), ak.Transpose(), n))
+		ak = mul(ak, ak, n)
+	}
+	return nil, errors.New("the matrix A is not stable")
+}
+
+// lyapunovIterations is the maximum number of squarings of the smith iteration
+const lyapunovIterations = 64
+
+// cayley returns the cayley transformation Ad=(I-hA)⁻¹(I+hA) of the matrix A,
+// the matrix F=(I-hA)⁻¹ and the shift h. The inhomogeneity Q of the lyapunov
+// equation is transformed to Qd=2hFQFᵀ. The shift is chosen to minimize the
+// spectral radius of Ad.
+func cayley(a Matrix) (Matrix, Matrix, float64, error) {
+	n := a.Rows()
+	ai, err := a.Inverse()
+	if err != nil {
+		return nil, nil, 0, errors.New("the matrix A is not stable")
+	}
+	h := 1 / math.Sqrt(a.norm()*ai.norm())
+	f, err := add(Identity(n), a.MulFloat(-h)).Inverse()
+	if err != nil {
+		return nil, nil, 0, errors.New("the matrix A is not stable")
+	}
+	ad := mul(f, add(Identity(n), a.MulFloat(h)), n)
+	return ad, f, h, nil
+}
+
+// smithConverged returns true if the power Ad^(2^k) is small enough to stop the
+// smith iteration. An error is returned if it grows, which means that A is not stable.
+func smithConverged(ak Matrix) (bool, error) {
+	norm := ak.norm()
+	if math.IsNaN(norm) || norm > 1e100 {
+		return false, errors.New("the matrix A is not stable")
+	}
+	return norm < 1e-16, nil
+}
+
+// lyapunovFactor returns a factor L of the solution X=LLᵀ of the lyapunov equation
+//
+//	AX + XAᵀ + BBᵀ = 0
+//
+// for a stable matrix A. The factor is calculated directly by the squared smith
+// iteration, which is much more accurate than the cholesky factorization of X
+// if X is nearly singular. The columns of the factor are compressed by a QR
+// factorization, so that L has at most as many columns as A.
+func lyapunovFactor(a, b Matrix) (Matrix, error) {
+	n := a.Rows()
+	ak, f, h, err := cayley(a)
+	if err != nil {
+		return nil, err
+	}
+	z := mul(f, b, b.Cols()).MulFloat(math.Sqrt(2 * h))
+	for range lyapunovIterations {
+		if converged, err := smithConverged(ak); err != nil {
+			return nil, err
+		} else if converged {
+			return z, nil
+		}
+		zn, err := Stack([][]Matrix{{z, mul(ak, z, z.Cols())}})
+		if err != nil {
+			return nil, err
+		}
+		z = zn.Transpose().qrR().Transpose()
+		ak = mul(ak, ak, n)
+	}
+	return nil, errors.New("the matrix A is not stable")
+}
+
+func symmetrize(x Matrix) {
+	for i := range x {
+		for j := 0; j < i; j++ {
+			v := (x[i][j] + x[j][i]) / 2
+			x[i][j] = v
+			x[j][i] = v
+		}
+	}
+}
+
+// gramianFactors returns the factors Lc and Lo of the controllability
+// gramian Wc=LcLcᵀ and the observability gramian Wo=LoLoᵀ.
+func (s *StateSpace) gramianFactors() (Matrix, Matrix, error) {
+	if s.Order() == 0 {
+		return nil, nil, errors.New("the system has no states")
+	}
+	lc, err := lyapunovFactor(s.A, s.B)
+	if err != nil {
+		return nil, nil, err
+	}
+	lo, err := lyapunovFactor(s.A.Transpose(), s.C.Transpose())
+	if err != nil {
+		return nil, nil, err
+	}
+	return lc, lo, nil
+}
+
+// Gramians returns the controllability gramian Wc and the observability gramian Wo
+// of a stable system. They are the solutions of the lyapunov equations
+//
+//	AWc + WcAᵀ + BBᵀ = 0
+//	AᵀWo + WoA + CᵀC = 0
+func (s *StateSpace) Gramians() (Matrix, Matrix, error) {
+	lc, lo, err := s.gramianFactors()
+	if err != nil {
+		return nil, nil, err
+	}
+	n := s.Order()
+	return mul(lc, lc.Transpose(), n), mul(lo, lo.Transpose(), n), nil
+}
+
+// balancing contains the data of the balanced realization which is
+// obtained by the square root method from the SVD LoᵀLc=UΣVᵀ.
+type balancing struct {
+	// hsv are the hankel singular values in descending order
+	hsv Vector
+	// lc and lo are the factors of the gramians
+	lc, lo Matrix
+	// u and v contain the singular vectors as columns
+	u, v Matrix
+}
+
+func (s *StateSpace) balancing() (*balancing, error) {
+	lc, lo, err := s.gramianFactors()
+	if err != nil {
+		return nil, err
+	}
+	u, sv, v, err := mul(lo.Transpose(), lc, lc.Cols()).svd()
+	if err != nil {
+		return nil, err
+	}
+	// the factors may have less columns than states
+	hsv := make(Vector, s.Order())
+	copy(hsv, sv)
+	return &balancing{hsv: hsv, lc: lc, lo: lo, u: u, v: v}, nil
+}
+
+// transformation returns the first r columns of the balancing transformation
+// T=LcVΣ^(-1/2) and the first r rows of its inverse T⁻¹=Σ^(-1/2)UᵀLoᵀ.
+func (b *balancing) transformation(r int) (Matrix, Matrix, error) {
+	n := len(b.hsv)
+	t := NewMatrix(n, r)
+	ti := NewMatrix(r, n)
+	for j := 0; j < r; j++ {
+		if b.hsv[j] <= 0 {
+			return nil, nil, errors.New("the system is not observable")
+		}
+		f := 1 / math.Sqrt(b.hsv[j])
+		for i := 0; i < n; i++ {
+			lv := 0.0
+			for k := range b.v {
+				lv += b.lc[i][k] * b.v[k][j]
+			}
+			t[i][j] = lv * f
+			ul := 0.0
+			for k := range b.u {
+				ul += b.u[k][j] * b.lo[i][k]
+			}
+			ti[j][i] = ul * f
+		}
+	}
+	return t, ti, nil
+}
+
+// HankelSingularValues returns the hankel singular values of a stable system in
+// descending order. They are the square roots of the eigenvalues of WcWo.
+func (s *StateSpace) HankelSingularValues() (Vector, error) {
+	b, err := s.balancing()
+	if err != nil {
+		return nil, err
+	}
+	return b.hsv, nil
+}
+
+// Balance returns the balanced realization of a stable and minimal system.
+// Both gramians of the balanced system are equal to the diagonal matrix of the
+// hankel singular values.
+func (s *StateSpace) Balance() (*StateSpace, error) {
+	b, err := s.balancing()
+	if err != nil {
+		return nil, err
+	}
+	n := s.Order()
+	t, ti, err := b.transformation(n)
+	if err != nil {
+		return nil, err
+	}
+	return NewStateSpace(mul(mul(ti, s.A, n), t, n), mul(ti, s.B, s.Inputs()), mul(s.C, t, n), s.D.Copy())
+}
+
+// ReductionMethod selects how the weak states of the balanced realization are removed
+type ReductionMethod int
+
+const (
+	// Truncation removes the weak states. The reduced system matches the
+	// original system at high frequencies.
+	Truncation ReductionMethod = iota
+	// Residualization sets the derivatives of the weak states to zero. The
+	// reduced system keeps the static gain of the original system.
+	Residualization
+)
+
+func (m ReductionMethod) String() string {
+	if m == Residualization {
+		return "residualize"
+	}
+	return "truncate"
+}
+
+// ReductionByName returns the reduction method with the given name
+func ReductionByName(name string) (ReductionMethod, error) {
+	switch name {
+	case "truncate":
+		return Truncation, nil
+	case "residualize":
+		return Residualization, nil
+	default:
+		return Truncation, fmt.Errorf("unknown method '%s', allowed are 'truncate' and 'residualize'", name)
+	}
+}
+
+// Reduce returns a reduced system of order r which is obtained from the balanced
+// realization. The error bound of both methods is twice the sum of the removed
+// hankel singular values.
+func (s *StateSpace) Reduce(r int, method ReductionMethod) (*StateSpace, error) {
+	n := s.Order()
+	if r < 0 || r > n {
+		return nil, fmt.Errorf("the order needs to be in the range 0 to %d", n)
+	}
+	b, err := s.balancing()
+	if err != nil {
+		return nil, err
+	}
+
+	// states without any influence are removed first
+	minimal := 0
+	for minimal < n && b.hsv[minimal] > 1e-12*b.hsv[0] {
+		minimal++
+	}
+	r = min(r, minimal)
+	if method == Truncation {
+		minimal = r
+	}
+
+	t, ti, err := b.transformation(minimal)
+	if err != nil {
+		return nil, err
+	}
+	a := mul(mul(ti, s.A, n), t, minimal)
+	bb := mul(ti, s.B, s.Inputs())
+	c := mul(s.C, t, minimal)
+	d := s.D.Copy()
+	if minimal == r {
+		return NewStateSpace(a, bb, c, d)
+	}
+
+	// the weak states are in steady state: x₂ = -A₂₂⁻¹(A₂₁x₁ + B₂u)
+	part := func(m Matrix, r0, r1, c0, c1 int) Matrix {
+		p := NewMatrix(r1-r0, c1-c0)
+		for i := r0; i < r1; i++ {
+			copy(p[i-r0], m[i][c0:c1])
+		}
+		return p
+	}
+	in := s.Inputs()
+	out := s.Outputs()
+	a22 := part(a, r, minimal, r, minimal)
+	a21 := part(a, r, minimal, 0, r)
+	b2 := part(bb, r, minimal, 0, in)
+	rhs, err := Stack([][]Matrix{{a21, b2}})
+	if err != nil {
+		return nil, err
+	}
+	x2, err := a22.Solve(rhs)
+	if err != nil {
+		return nil, errors.New("the removed states can not be residualized")
+	}
+	x21 := part(x2, 0, minimal-r, 0, r)
+	x2b := part(x2, 0, minimal-r, r, r+in)
+	a12 := part(a, 0, r, r, minimal)
+	c2 := part(c, 0, out, r, minimal)
+	ar, _ := part(a, 0, r, 0, r).SubMatrix(mul(a12, x21, r))
+	br, _ := part(bb, 0, r, 0, in).SubMatrix(mul(a12, x2b, in))
+	cr, _ := part(c, 0, out, 0, r).SubMatrix(mul(c2, x21, r))
+	dr, _ := d.SubMatrix(mul(c2, x2b, in))
+	return NewStateSpace(ar, br, cr, dr)
+}
+
+// ReduceOrder returns a transfer function of order r which approximates the system.
+// The dead time is kept.
+func (l *Linear) ReduceOrder(r int, method ReductionMethod) (*Linear, error) {
+	ss, err := (&Linear{Numerator: l.Numerator, Denominator: l.Denominator}).cascadeForm()
+	if err != nil {
+		return nil, err
+	}
+	red, err := ss.Reduce(r, method)
+	if err != nil {
+		return nil, err
+	}
+	lin, err := red.Linear()
+	if err != nil {
+		return nil, err
+	}
+	lin.Delay = l.Delay
+	return lin, nil
+}
+
+// HankelSingularValues returns the hankel singular values of a stable system.
+// A system with dead time has infinitely many hankel singular values, so an
+// error is returned.
+func (l *Linear) HankelSingularValues() (Vector, error) {
+	ss, err := l.cascadeForm()
+	if err != nil {
+		return nil, err
+	}
+	return ss.HankelSingularValues()
+}
+
+// cascadeForm returns a realization of the system as a series connection of first
+// and second order sections, one for each real pole and each complex pole pair.
+// Every section has a static gain of one. Other than the controllable canonical
+// form, this realization is well conditioned also for systems of high order.
+func (l *Linear) cascadeForm() (*StateSpace, error) {
+	if l.Delay != 0 {
+		return nil, errDeadTime
+	}
+	if !l.IsCausal() {
+		return nil, errors.New("not a proper transfer function, numerator has higher order than denominator")
+	}
+	// The eigenvalues are used without refinement. In a cluster of poles, they are
+	// not accurate, but they are the exact poles of a system which is very close to
+	// the given one.
+	poles, err := l.Denominator.companionEigenvalues()
+	if err != nil {
+		return nil, err
+	}
+
+	// the sections are normalized to d(0)=1 if possible
+	var sections []Polynomial
+	for _, p := range poles {
+		switch {
+		case math.Abs(imag(p)) <= eps:
+			if cmplx.Abs(p) <= eps {
+				sections = append(sections, Polynomial{0, 1})
+			} else {
+				sections = append(sections, Polynomial{1, -1 / real(p)})
+			}
+		case imag(p) > 0:
+			a := real(p * cmplx.Conj(p))
+			sections = append(sections, Polynomial{1, -2 * real(p) / a, 1 / a})
+		}
+	}
+	n := l.Denominator.Degree()
+	lead := 1.0
+	order := 0
+	for _, sec := range sections {
+		order += len(sec) - 1
+		lead *= sec[len(sec)-1]
+	}
+	if order != n {
+		return nil, errors.New("the poles do not match the order of the system")
+	}
+
+	// r=N-dD is expanded to r=r₁d₂...dₖ + r₂d₃...dₖ + ... + rₖ with deg(rᵢ)<deg(dᵢ)
+	norm := l.Denominator[n]
+	d := 0.0
+	if l.Numerator.Degree() == n {
+		d = l.Numerator[n] / norm
+	}
+	r := make(Polynomial, n+1)
+	for i := range r {
+		if i < len(l.Numerator) {
+			r[i] = l.Numerator[i]
+		}
+		r[i] = (r[i] - d*l.Denominator[i]) * lead / norm
+	}
+	rem := make([]Polynomial, len(sections))
+	for i := len(sections) - 1; i >= 0; i-- {
+		r, rem[i] = divideSection(r, sections[i])
+	}
+
+	a := NewMatrix(n, n)
+	b := NewMatrix(n, 1)
+	c := NewMatrix(1, n)
+	o := 0
+	for i, sec := range sections {
+		// the input of the section is the output of the previous section or u
+		k := len(sec) - 1
+		in := func(row int, f float64) {
+			if o == 0 {
+				b[row][0] = f
+			} else {
+				a[row][o-len(sections[i-1])+1] = f
+			}
+		}
+		if k == 1 {
+			// d₁x' + d₀x = in
+			a[o][o] = -sec[0] / sec[1]
+			in(o, 1/sec[1])
+		} else {
+			// d₂x'' + d₁x' + d₀x = in
+			a[o][o+1] = 1
+			a[o+1][o] = -sec[0] / sec[2]
+			a[o+1][o+1] = -sec[1] / sec[2]
+			in(o+1, 1/sec[2])
+		}
+		for j, v := range rem[i] {
+			c[0][o+j] = v
+		}
+		o += k
+	}
+	return NewStateSpace(a, b, c, Matrix{{d}})
+}
+
+// divideSection divides the polynomial p by the section polynomial q and returns
+// the quotient and the remainder. Other than Div, small coefficients are kept.
+func divideSection(p, q Polynomial) (Polynomial, Polynomial) {
+	k := len(q) - 1
+	if len(p) <= k {
+		return Polynomial{}, p
+	}
+	rem := make(Polynomial, len(p))
+	copy(rem, p)
+	quot := make(Polynomial, len(p)-k)
+	for i := len(quot) - 1; i >= 0; i-- {
+		quot[i] = rem[i+k] / q[k]
+		for j := 0; j <= k; j++ {
+			rem[i+j] -= q[j] * quot[i]
+		}
+	}
+	return quot, rem[:k]
+}
+
+// qrR returns the upper triangular matrix R of the QR factorization
+// of the matrix m. The householder method is used.
+func (m Matrix) qrR() Matrix {
+	rows := m.Rows()
+	cols := m.Cols()
+	a := m.Copy()
+	k := min(rows, cols)
+	for j := 0; j < k; j++ {
+		norm := 0.0
+		for i := j; i < rows; i++ {
+			norm = math.Hypot(norm, a[i][j])
+		}
+		if norm == 0 {
+			continue
+		}
+		if a[j][j] > 0 {
+			norm = -norm
+		}
+		// v=x-norm*e₁ is stored in the column j
+		a[j][j] -= norm
+		vv := 0.0
+		for i := j; i < rows; i++ {
+			vv += a[i][j] * a[i][j]
+		}
+		for c := j + 1; c < cols; c++ {
+			f := 0.0
+			for i := j; i < rows; i++ {
+				f += a[i][j] * a[i][c]
+			}
+			f *= 2 / vv
+			for i := j; i < rows; i++ {
+				a[i][c] -= f * a[i][j]
+			}
+		}
+		a[j][j] = norm
+	}
+	r := NewMatrix(k, cols)
+	for i := 0; i < k; i++ {
+		copy(r[i][i:], a[i][i:])
+	}
+	return r
+}
+
+// svd calculates the singular value decomposition M=UΣVᵀ using the one-sided
+// jacobi method, which also determines small singular values with a high relative
+// accuracy. The singular values are returned in descending order, the singular
+// vectors are the columns of U and V.
+func (m Matrix) svd() (Matrix, Vector, Matrix, error) {
+	rows := m.Rows()
+	cols := m.Cols()
+	if rows < cols {
+		// the columns of a wide matrix can not become orthogonal
+		v, sv, u, err := m.Transpose().svd()
+		return u, sv, v, err
+	}
+	w := m.Copy()
+	v := Identity(cols)
+	converged := false
+	for range 100 {
+		rotated := false
+		for p := 0; p < cols; p++ {
+			for q := p + 1; q < cols; q++ {
+				alpha, beta, gamma := 0.0, 0.0, 0.0
+				for i := 0; i < rows; i++ {
+					alpha += w[i][p] * w[i][p]
+					beta += w[i][q] * w[i][q]
+					gamma += w[i][p] * w[i][q]
+				}
+				if gamma == 0 || math.Abs(gamma) <= 1e-15*math.Sqrt(alpha*beta) {
+					continue
+				}
+				rotated = true
+				zeta := (beta - alpha) / (2 * gamma)
+				t := 1 / (math.Abs(zeta) + math.Sqrt(1+zeta*zeta))
+				if zeta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(1+t*t)
+				s := c * t
+				for i := 0; i < rows; i++ {
+					wp := w[i][p]
+					wq := w[i][q]
+					w[i][p] = c*wp - s*wq
+					w[i][q] = s*wp + c*wq
+				}
+				for i := 0; i < cols; i++ {
+					vp := v[i][p]
+					vq := v[i][q]
+					v[i][p] = c*vp - s*vq
+					v[i][q] = s*vp + c*vq
+				}
+			}
+		}
+		if !rotated {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return nil, nil, nil, errors.New("the jacobi method does not converge")
+	}
+
+	sv := make(Vector, cols)
+	index := make([]int, cols)
+	for j := range sv {
+		norm := 0.0
+		for i := 0; i < rows; i++ {
+			norm = math.Hypot(norm, w[i][j])
+		}
+		sv[j] = norm
+		index[j] = j
+	}
+	sort.Slice(index, func(i, j int) bool {
+		return sv[index[i]] > sv[index[j]]
+	})
+	u := NewMatrix(rows, cols)
+	vs := NewMatrix(cols, cols)
+	svs := make(Vector, cols)
+	for j, k := range index {
+		svs[j] = sv[k]
+		for i := 0; i < rows; i++ {
+			if sv[k] > 0 {
+				u[i][j] = w[i][k] / sv[k]
+			}
+		}
+		for i := 0; i < cols; i++ {
+			vs[i][j] = v[i][k]
+		}
+	}
+	return u, svs, vs, nil
+}
+
+// symmetricEigen calculates the eigenvalues and eigenvectors of a symmetric matrix
+// using the cyclic jacobi method. The eigenvalues are returned in descending order,
+// the eigenvectors are the columns of the returned matrix.
+func (m Matrix) symmetricEigen() (Vector, Matrix, error) {
+	n := m.Rows()
+	a := m.Copy()
+	v := Identity(n)
+	converged := false
+	for range 100 {
+		off := 0.0
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				off += a[i][j] * a[i][j]
+			}
+		}
+		if off <= 1e-30*a.norm()*a.norm() {
+			converged = true
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if a[p][q] == 0 {
+					continue
+				}
+				theta := (a[q][q] - a[p][p]) / (2 * a[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					akp := a[k][p]
+					akq := a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk := a[p][k]
+					aqk := a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+				for k := 0; k < n; k++ {
+					vkp := v[k][p]
+					vkq := v[k][q]
+					v[k][p] = c*vkp - s*vkq
+					v[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+	if !converged {
+		return nil, nil, errors.New("the jacobi method does not converge")
+	}
+
+	index := make([]int, n)
+	for i := range index {
+		index[i] = i
+	}
+	sort.Slice(index, func(i, j int) bool {
+		return a[index[i]][index[i]] > a[index[j]][index[j]]
+	})
+	ev := make(Vector, n)
+	vs := NewMatrix(n, n)
+	for j, i := range index {
+		ev[j] = a[i][i]
+		for k := 0; k < n; k++ {
+			vs[k][j] = v[k][i]
+		}
+	}
+	return ev, vs, nil
+}
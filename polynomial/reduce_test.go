@@ -0,0 +1,144 @@
+package polynomial
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/cmplx"
+	"testing"
+)
+
+func TestLyapunov(t *testing.T) {
+	a := Matrix{{-1, 2, 0}, {-2, -1, 1}, {0, 0, -3}}
+	q := Matrix{{2, 1, 0}, {1, 3, 0}, {0, 0, 1}}
+	x, err := Lyapunov(a, q)
+	assert.NoError(t, err)
+	r := add(add(mul(a, x, 3), mul(x, a.Transpose(), 3)), q)
+	assert.InDelta(t, 0, r.norm(), 1e-10)
+
+	_, err = Lyapunov(Matrix{{1, 0}, {0, -1}}, Identity(2))
+	assert.Error(t, err)
+}
+
+func TestSymmetricEigen(t *testing.T) {
+	m := Matrix{{2, 1, 0}, {1, 2, 0}, {0, 0, 5}}
+	ev, v, err := m.symmetricEigen()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{5, 3, 1}, ev, 1e-12)
+	for j := range ev {
+		for i := range m {
+			mv := 0.0
+			for k := range m {
+				mv += m[i][k] * v[k][j]
+			}
+			assert.InDelta(t, ev[j]*v[i][j], mv, 1e-12)
+		}
+	}
+}
+
+func TestHankelSingularValues(t *testing.T) {
+	hsv, err := (&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}).HankelSingularValues()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0.5}, hsv, 1e-10)
+
+	_, err = (&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{-1, 1}}).HankelSingularValues()
+	assert.Error(t, err)
+
+	_, err = (&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}, Delay: 1}).HankelSingularValues()
+	assert.Error(t, err)
+}
+
+func TestHankelSingularValuesHighOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		lin  *Linear
+		hsv  []float64
+	}{
+		{"pt15", &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}.Pow(15)}, []float64{
+			8.5242440201e-01, 5.3307409190e-01, 2.5144685193e-01, 9.2947745490e-02, 2.7820535210e-02,
+			6.8690635173e-03, 1.4087420927e-03, 2.3972678374e-04, 3.3596667029e-05, 3.8225474315e-06,
+			3.4500170382e-07, 2.3808861061e-08, 1.1818034295e-09, 3.7612444547e-11, 5.7706622358e-13}},
+		{"pt13", &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 0.1}.Pow(12).Mul(Polynomial{1, 1})}, []float64{
+			7.3415103410e-01, 3.1546157142e-01, 1.0532458937e-01, 2.9944786375e-02, 7.1404946871e-03,
+			1.4115307677e-03, 2.2889300207e-04, 2.9995348217e-05, 3.1039948795e-06, 2.4456685663e-07,
+			1.3806117083e-08, 4.9793950238e-10, 8.6292193829e-12}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hsv, err := tt.lin.HankelSingularValues()
+			assert.NoError(t, err)
+			assert.Len(t, hsv, len(tt.hsv))
+			for i, h := range tt.hsv {
+				assert.InEpsilon(t, h, hsv[i], 1e-6, i)
+			}
+		})
+	}
+}
+
+func TestBalance(t *testing.T) {
+	lin := &Linear{Numerator: Polynomial{4, 1}, Denominator: Polynomial{6, 11, 6, 1}}
+	ss, err := lin.StateSpace()
+	assert.NoError(t, err)
+	bal, err := ss.Balance()
+	assert.NoError(t, err)
+	hsv, err := ss.HankelSingularValues()
+	assert.NoError(t, err)
+	wc, wo, err := bal.Gramians()
+	assert.NoError(t, err)
+	for i := range hsv {
+		for j := range hsv {
+			exp := 0.0
+			if i == j {
+				exp = hsv[i]
+			}
+			assert.InDelta(t, exp, wc[i][j], 1e-9)
+			assert.InDelta(t, exp, wo[i][j], 1e-9)
+		}
+	}
+}
+
+func TestLinear_ReduceOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		lin    *Linear
+		r      int
+		method ReductionMethod
+	}{
+		{"truncate", &Linear{Numerator: Polynomial{1.1, 1}, Denominator: Polynomial{2, 3, 1}}, 1, Truncation},
+		{"residualize", &Linear{Numerator: Polynomial{1.1, 1}, Denominator: Polynomial{2, 3, 1}}, 1, Residualization},
+		{"fast poles", &Linear{Numerator: Polynomial{1000}, Denominator: Polynomial{1, 1}.Mul(Polynomial{20, 1}).Mul(Polynomial{50, 1})}, 1, Truncation},
+		{"fast poles res", &Linear{Numerator: Polynomial{1000}, Denominator: Polynomial{1, 1}.Mul(Polynomial{20, 1}).Mul(Polynomial{50, 1})}, 1, Residualization},
+		{"order 2", &Linear{Numerator: Polynomial{100}, Denominator: Polynomial{1, 0.2, 1}.Mul(Polynomial{10, 1}).Mul(Polynomial{100, 1})}, 2, Truncation},
+		{"order 11", &Linear{Numerator: Polynomial{3, 1}, Denominator: Polynomial{1, 1}.Mul(Polynomial{1, 0.01}.Pow(10))}, 2, Residualization},
+		{"order 13", &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 0.1}.Pow(12).Mul(Polynomial{1, 1})}, 2, Truncation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hsv, err := tt.lin.HankelSingularValues()
+			assert.NoError(t, err)
+			bound := 0.0
+			for _, h := range hsv[tt.r:] {
+				bound += 2 * h
+			}
+
+			red, err := tt.lin.ReduceOrder(tt.r, tt.method)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.r, red.Denominator.Degree())
+			for _, w := range []float64{0, 0.1, 0.5, 1, 2, 5, 10, 100} {
+				e := cmplx.Abs(tt.lin.EvalCplx(complex(0, w)) - red.EvalCplx(complex(0, w)))
+				assert.LessOrEqual(t, e, bound*1.0001, w)
+			}
+			if tt.method == Residualization {
+				assert.InDelta(t, tt.lin.DCGain(), red.DCGain(), 1e-9)
+			}
+		})
+	}
+}
+
+func TestLinear_ReduceOrderNotMinimal(t *testing.T) {
+	lin := &Linear{Numerator: Polynomial{1, 1}, Denominator: Polynomial{2, 3, 1}}
+	for _, m := range []ReductionMethod{Truncation, Residualization} {
+		red, err := lin.ReduceOrder(1, m)
+		assert.NoError(t, err)
+		assert.InDelta(t, 1, red.Numerator[0]/red.Denominator[1], 1e-9)
+		assert.InDelta(t, 2, red.Denominator[0]/red.Denominator[1], 1e-9)
+	}
+}
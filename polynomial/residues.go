@@ -0,0 +1,362 @@
+package polynomial
+
+import (
+	"bytes"
+	"errors"
+	"github.com/hneemann/parser2/value/export"
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// PartialFraction is the term Residue/(s-Pole)^Power
+type PartialFraction struct {
+	Pole    complex128
+	Power   int
+	Residue complex128
+}
+
+// PartialFractions is the partial fraction expansion of a linear system.
+// Complex poles are contained together with their conjugate.
+// The dead time Delay shifts the time function.
+type PartialFractions struct {
+	Terms  []PartialFraction
+	Direct Polynomial
+	Delay  float64
+}
+
+// poleGroup is a pole together with its multiplicity
+type poleGroup struct {
+	pole complex128
+	mult int
+}
+
+// poleTolerance is used to detect repeated poles
+const poleTolerance = 1e-5
+
+// groupPoles returns the distinct poles of the given roots including the
+// conjugate complex poles, sorted by descending real part.
+func groupPoles(r Roots) []poleGroup {
+	var all []complex128
+	for _, root := range r.roots {
+		all = append(all, root)
+		if imag(root) != 0 {
+			all = append(all, cmplx.Conj(root))
+		}
+	}
+	var groups []poleGroup
+	for _, root := range all {
+		found := false
+		for i := range groups {
+			if cmplx.Abs(groups[i].pole-root) < poleTolerance*(1+cmplx.Abs(root)) {
+				m := float64(groups[i].mult)
+				groups[i].pole = (groups[i].pole*complex(m, 0) + root) / complex(m+1, 0)
+				groups[i].mult++
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, poleGroup{pole: root, mult: 1})
+		}
+	}
+	for i, g := range groups {
+		if math.Abs(imag(g.pole)) < poleTolerance*(1+cmplx.Abs(g.pole)) {
+			groups[i].pole = complex(real(g.pole), 0)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if real(groups[i].pole) != real(groups[j].pole) {
+			return real(groups[i].pole) > real(groups[j].pole)
+		}
+		return imag(groups[i].pole) > imag(groups[j].pole)
+	})
+	return groups
+}
+
+// taylor returns the first n coefficients of the taylor series of p at the point z
+func taylor(p Polynomial, z complex128, n int) []complex128 {
+	c := make([]complex128, len(p))
+	for i, v := range p {
+		c[i] = complex(v, 0)
+	}
+	res := make([]complex128, n)
+	for j := 0; j < n && len(c) > 0; j++ {
+		// synthetic division by (s-z), the remainder is the value at z
+		for i := len(c) - 2; i >= 0; i-- {
+			c[i] += c[i+1] * z
+		}
+		res[j] = c[0]
+		c = c[1:]
+	}
+	return res
+}
+
+// Residues calculates the partial fraction expansion of the linear system.
+// Repeated poles lead to terms with a higher power.
+func (l *Linear) Residues() (*PartialFractions, error) {
+	den := l.Denominator.Canonical()
+	if den.IsZero() {
+		return nil, errors.New("denominator is zero")
+	}
+	direct, rem, err := l.Numerator.Canonical().Div(den)
+	if err != nil {
+		return nil, err
+	}
+	poles, err := den.Roots()
+	if err != nil {
+		return nil, err
+	}
+	groups := groupPoles(poles)
+	lead := complex(den[len(den)-1], 0)
+
+	var terms []PartialFraction
+	for i, g := range groups {
+		if imag(g.pole) < 0 {
+			// the residues are the conjugates of the residues of the conjugate pole
+			conj := cmplx.Conj(g.pole)
+			for _, t := range terms {
+				if cmplx.Abs(t.Pole-conj) < poleTolerance*(1+cmplx.Abs(conj)) {
+					terms = append(terms, PartialFraction{Pole: g.pole, Power: t.Power, Residue: cmplx.Conj(t.Residue)})
+				}
+			}
+			continue
+		}
+
+		m := g.mult
+		a := taylor(rem, g.pole, m)
+
+		// remaining part of the denominator in powers of z=s-p
+		d := make([]complex128, m)
+		d[0] = lead
+		for j, o := range groups {
+			if j == i {
+				continue
+			}
+			for range o.mult {
+				// multiply by (z+p-q)
+				c := g.pole - o.pole
+				for k := m - 1; k > 0; k-- {
+					d[k] = d[k]*c + d[k-1]
+				}
+				d[0] *= c
+			}
+		}
+
+		// series division
+		c := make([]complex128, m)
+		for j := range c {
+			s := a[j]
+			for k := 1; k <= j; k++ {
+				s -= d[k] * c[j-k]
+			}
+			c[j] = s / d[0]
+		}
+		for j := m - 1; j >= 0; j-- {
+			r := c[j]
+			if imag(g.pole) == 0 {
+				r = complex(real(r), 0)
+			}
+			terms = append(terms, PartialFraction{Pole: g.pole, Power: m - j, Residue: r})
+		}
+	}
+	return &PartialFractions{Terms: terms, Direct: direct.Canonical(), Delay: l.Delay}, nil
+}
+
+// Eval evaluates the inverse laplace transform at the time t.
+// The dirac impulses of the direct term are ignored.
+func (pf *PartialFractions) Eval(t float64) float64 {
+	t -= pf.Delay
+	if t < 0 {
+		return 0
+	}
+	var sum complex128
+	for _, term := range pf.Terms {
+		f := term.Residue * cmplx.Exp(term.Pole*complex(t, 0))
+		if term.Power > 1 {
+			f *= complex(math.Pow(t, float64(term.Power-1))/factorial(term.Power-1), 0)
+		}
+		sum += f
+	}
+	return real(sum)
+}
+
+func factorial(n int) float64 {
+	f := 1.0
+	for i := 2; i <= n; i++ {
+		f *= float64(i)
+	}
+	return f
+}
+
+// timeTerm is a single real valued summand of the time function
+//
+//	amp t^pow e^{sigma t} sin(omega t + phase)
+//
+// If omega is zero, there is no sin factor.
+type timeTerm struct {
+	amp, sigma, omega, phase float64
+	pow                      int
+}
+
+func (pf *PartialFractions) timeTerms() []timeTerm {
+	var tt []timeTerm
+	maxAmp := 0.0
+	for _, term := range pf.Terms {
+		p := term.Pole
+		if imag(p) < 0 {
+			continue
+		}
+		k := factorial(term.Power - 1)
+		var t timeTerm
+		if imag(p) == 0 {
+			t = timeTerm{amp: real(term.Residue) / k, sigma: real(p), pow: term.Power - 1}
+		} else {
+			// 2 Re(r e^{jωt}) = 2|r| cos(ωt+φ) = 2|r| sin(ωt+φ+π/2)
+			amp := 2 * cmplx.Abs(term.Residue) / k
+			phase := cmplx.Phase(term.Residue) + math.Pi/2
+			// keep the phase in the range -π/2..π/2
+			if phase > math.Pi/2 {
+				phase -= math.Pi
+				amp = -amp
+			}
+			t = timeTerm{amp: amp, sigma: real(p), omega: imag(p), phase: phase, pow: term.Power - 1}
+		}
+		maxAmp = math.Max(maxAmp, math.Abs(t.amp))
+		tt = append(tt, t)
+	}
+	var res []timeTerm
+	for _, t := range tt {
+		if math.Abs(t.amp) > 1e-9*maxAmp {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+const laTeXPrec = 4
+
+func laTeXFloat(f float64) string {
+	return export.NewFormattedFloat(f, laTeXPrec).LaTeX()
+}
+
+// laTeXMulT writes f*t
+func laTeXMulT(w *bytes.Buffer, f float64, t string) {
+	switch f {
+	case 1:
+	case -1:
+		w.WriteString("-")
+	default:
+		w.WriteString(laTeXFloat(f))
+	}
+	w.WriteString(t)
+}
+
+// timeVar returns the time variable which is shifted by the dead time
+func (pf *PartialFractions) timeVar() string {
+	if pf.Delay == 0 {
+		return "t"
+	}
+	return "(t-" + laTeXFloat(pf.Delay) + ")"
+}
+
+// ToLaTeX writes the inverse laplace transform as a LaTeX formula.
+// A dead time is written as the shifted step function \sigma(t-T).
+func (pf *PartialFractions) ToLaTeX(w *bytes.Buffer) {
+	tv := pf.timeVar()
+	if pf.Delay != 0 {
+		w.WriteString("\\left(")
+	}
+	first := true
+	for i, d := range pf.Direct {
+		if d == 0 {
+			continue
+		}
+		if d < 0 {
+			w.WriteString("-")
+		} else if !first {
+			w.WriteString("+")
+		}
+		if math.Abs(d) != 1 {
+			w.WriteString(laTeXFloat(math.Abs(d)))
+		}
+		w.WriteString("\\delta")
+		for range i {
+			w.WriteString("'")
+		}
+		if pf.Delay == 0 {
+			w.WriteString("(t)")
+		} else {
+			w.WriteString(tv)
+		}
+		first = false
+	}
+	for _, t := range pf.timeTerms() {
+		if t.amp < 0 {
+			w.WriteString("-")
+		} else if !first {
+			w.WriteString("+")
+		}
+		first = false
+		hasFactor := t.pow > 0 || math.Abs(t.sigma) > eps || t.omega != 0
+		amp := math.Abs(t.amp)
+		if amp != 1 || !hasFactor {
+			w.WriteString(laTeXFloat(amp))
+		}
+		if t.pow > 0 {
+			w.WriteString(tv)
+			if t.pow > 1 {
+				w.WriteString("^{")
+				w.WriteString(laTeXFloat(float64(t.pow)))
+				w.WriteString("}")
+			}
+		}
+		if math.Abs(t.sigma) > eps {
+			w.WriteString("e^{")
+			laTeXMulT(w, t.sigma, tv)
+			w.WriteString("}")
+		}
+		if t.omega != 0 {
+			w.WriteString("\\sin(")
+			laTeXMulT(w, t.omega, tv)
+			if math.Abs(t.phase) > eps {
+				if t.phase > 0 {
+					w.WriteString("+")
+				} else {
+					w.WriteString("-")
+				}
+				w.WriteString(laTeXFloat(math.Abs(t.phase)))
+			}
+			w.WriteString(")")
+		}
+	}
+	if first {
+		w.WriteString("0")
+	}
+	if pf.Delay != 0 {
+		w.WriteString("\\right)\\sigma")
+		w.WriteString(tv)
+	}
+}
+
+func (pf *PartialFractions) LaTeX() string {
+	var b bytes.Buffer
+	pf.ToLaTeX(&b)
+	return b.String()
+}
+
+// ImpulseAnalytic returns the partial fraction expansion of the impulse response.
+func (l *Linear) ImpulseAnalytic() (*PartialFractions, error) {
+	if !l.IsCausal() {
+		return nil, errors.New("the system is not causal")
+	}
+	return l.Residues()
+}
+
+// StepAnalytic returns the partial fraction expansion of the step response.
+func (l *Linear) StepAnalytic() (*PartialFractions, error) {
+	if !l.IsCausal() {
+		return nil, errors.New("the system is not causal")
+	}
+	return (&Linear{Numerator: l.Numerator, Denominator: l.Denominator.Mul(Polynomial{0, 1}), Delay: l.Delay}).Residues()
+}
@@ -0,0 +1,70 @@
+package polynomial
+
+import (
+	"github.com/hneemann/control/graph"
+	"github.com/hneemann/parser2/funcGen"
+	"github.com/hneemann/parser2/value"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestLinear_Residues(t *testing.T) {
+	tests := []struct {
+		name string
+		lin  *Linear
+		want []PartialFraction
+	}{
+		{"simple", &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{2, 3, 1}}, []PartialFraction{
+			{Pole: -1, Power: 1, Residue: 1},
+			{Pole: -2, Power: 1, Residue: -1},
+		}},
+		{"repeated", &Linear{Numerator: Polynomial{0, 1}, Denominator: Polynomial{1, 2, 1}}, []PartialFraction{
+			{Pole: -1, Power: 1, Residue: 1},
+			{Pole: -1, Power: 2, Residue: -1},
+		}},
+		{"complex", &Linear{Numerator: Polynomial{3}, Denominator: Polynomial{13, 4, 1}}, []PartialFraction{
+			{Pole: complex(-2, 3), Power: 1, Residue: complex(0, -0.5)},
+			{Pole: complex(-2, -3), Power: 1, Residue: complex(0, 0.5)},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pf, err := tt.lin.Residues()
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.want), len(pf.Terms))
+			for i, w := range tt.want {
+				assert.Equal(t, w.Power, pf.Terms[i].Power)
+				assert.InDelta(t, 0, cmplxAbs(w.Pole-pf.Terms[i].Pole), 1e-6)
+				assert.InDelta(t, 0, cmplxAbs(w.Residue-pf.Terms[i].Residue), 1e-6)
+			}
+		})
+	}
+}
+
+func cmplxAbs(c complex128) float64 {
+	return math.Hypot(real(c), imag(c))
+}
+
+func TestLinear_StepAnalytic(t *testing.T) {
+	systems := []*Linear{
+		{Numerator: Polynomial{13}, Denominator: Polynomial{13, 4, 1}},
+		{Numerator: Polynomial{1, 1}, Denominator: Polynomial{13, 4, 1}.Mul(Polynomial{13, 4, 1})},
+		{Numerator: Polynomial{2, 1}, Denominator: Polynomial{1, 3, 3, 1}},
+		{Numerator: Polynomial{1, 0, 1}, Denominator: Polynomial{2, 3, 1}},
+	}
+	for _, lin := range systems {
+		t.Run(lin.String(), func(t *testing.T) {
+			pf, err := lin.StepAnalytic()
+			assert.NoError(t, err)
+			sim, _, err := lin.SimulateSolver(RK45, 5, 0.01, func(float64) (float64, error) { return 1, nil })
+			assert.NoError(t, err)
+			points, err := sim.ToSlice(funcGen.NewEmptyStack[value.Value]())
+			assert.NoError(t, err)
+			for _, p := range points {
+				v := p.(graph.Vector3d)
+				assert.InDelta(t, v.Y, pf.Eval(v.X), 1e-4)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package polynomial
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// CARE solves the continuous algebraic riccati equation
+//
+//	AᵀX + XA - XBR⁻¹BᵀX + Q = 0
+//
+// and returns the stabilizing solution X. The matrix sign function of
+// the hamiltonian matrix is used to find its stable invariant subspace.
+func CARE(a, b, q, r Matrix) (Matrix, error) {
+	n := a.Rows()
+	m := b.Cols()
+	if !a.IsSquare() {
+		return nil, errors.New("the matrix A needs to be square")
+	}
+	if b.Rows() != n {
+		return nil, fmt.Errorf("the matrix B needs to have %d rows", n)
+	}
+	if q.Rows() != n || q.Cols() != n {
+		return nil, fmt.Errorf("the matrix Q needs to be a %dx%d matrix", n, n)
+	}
+	if r.Rows() != m || r.Cols() != m {
+		return nil, fmt.Errorf("the matrix R needs to be a %dx%d matrix", m, m)
+	}
+	rb, err := r.Solve(b.Transpose())
+	if err != nil {
+		return nil, errors.New("the matrix R is singular")
+	}
+	g := mul(b, rb, n)
+
+	h, err := Stack([][]Matrix{
+		{a, g.MulFloat(-1)},
+		{q.MulFloat(-1), a.Transpose().MulFloat(-1)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	w, err := h.sign()
+	if err != nil {
+		return nil, errors.New("the riccati equation has no stabilizing solution")
+	}
+
+	// the stable subspace [I;X] is the null space of W+I:
+	// [W12; W22+I] X = -[W11+I; W21]
+	lhs := NewMatrix(2*n, n)
+	rhs := NewMatrix(2*n, n)
+	for i := 0; i < 2*n; i++ {
+		for j := 0; j < n; j++ {
+			lhs[i][j] = w[i][n+j]
+			rhs[i][j] = -w[i][j]
+		}
+		if i < n {
+			rhs[i][i] -= 1
+		} else {
+			lhs[i][i-n] += 1
+		}
+	}
+	lt := lhs.Transpose()
+	x, err := mul(lt, lhs, n).Solve(mul(lt, rhs, n))
+	if err != nil {
+		return nil, errors.New("the riccati equation has no stabilizing solution")
+	}
+
+	// the solution is symmetric
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			v := (x[i][j] + x[j][i]) / 2
+			x[i][j] = v
+			x[j][i] = v
+		}
+	}
+	return x, nil
+}
+
+// sign calculates the matrix sign function using the scaled newton iteration
+func (m Matrix) sign() (Matrix, error) {
+	n := m.Rows()
+	z := m.Copy()
+	for range 100 {
+		det, err := z.Det()
+		if err != nil {
+			return nil, err
+		}
+		if det == 0 {
+			return nil, errors.New("matrix is singular")
+		}
+		c := math.Pow(math.Abs(det), -1/float64(n))
+		zc := z.MulFloat(c)
+		zi, err := zc.Inverse()
+		if err != nil {
+			return nil, err
+		}
+		next := add(zc, zi).MulFloat(0.5)
+		diff := add(next, z.MulFloat(-1)).norm()
+		z = next
+		if diff < 1e-12*z.norm() {
+			return z, nil
+		}
+	}
+	return nil, errors.New("matrix sign function does not converge")
+}
+
+// LQR calculates the optimal state feedback gain K which minimizes the cost
+// function ∫xᵀQx+uᵀRu dt. Returns the gain K and the solution X of the riccati equation.
+func (s *StateSpace) LQR(q, r Matrix) (Matrix, Matrix, error) {
+	x, err := CARE(s.A, s.B, q, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	// K = R⁻¹BᵀX
+	k, err := r.Solve(mul(s.B.Transpose(), x, s.Order()))
+	if err != nil {
+		return nil, nil, err
+	}
+	return k, x, nil
+}
+
+// Kalman calculates the gain L of the stationary kalman filter for the system
+//
+//	x' = Ax + Bu + Bw
+//	y  = Cx + Du + v
+//
+// with the process noise w and the measurement noise v having the covariances qn and rn.
+// Returns the gain L and the covariance P of the estimation error.
+func (s *StateSpace) Kalman(qn, rn Matrix) (Matrix, Matrix, error) {
+	n := s.Order()
+	if qn.Rows() != s.Inputs() || qn.Cols() != s.Inputs() {
+		return nil, nil, fmt.Errorf("the process noise covariance needs to be a %dx%d matrix", s.Inputs(), s.Inputs())
+	}
+	// the filter riccati equation is the dual of the control riccati equation
+	gq := mul(mul(s.B, qn, s.Inputs()), s.B.Transpose(), n)
+	p, err := CARE(s.A.Transpose(), s.C.Transpose(), gq, rn)
+	if err != nil {
+		return nil, nil, err
+	}
+	// L = PCᵀRn⁻¹
+	lt, err := rn.Solve(mul(s.C, p, n))
+	if err != nil {
+		return nil, nil, err
+	}
+	return lt.Transpose(), p, nil
+}
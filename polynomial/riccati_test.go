@@ -0,0 +1,76 @@
+package polynomial
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+// careResidual returns AᵀX + XA - XBR⁻¹BᵀX + Q
+func careResidual(t *testing.T, a, b, q, r, x Matrix) Matrix {
+	n := a.Rows()
+	rb, err := r.Solve(b.Transpose())
+	assert.NoError(t, err)
+	xbrbx := mul(mul(x, mul(b, rb, n), n), x, n)
+	return add(add(add(mul(a.Transpose(), x, n), mul(x, a, n)), xbrbx.MulFloat(-1)), q)
+}
+
+func assertStable(t *testing.T, a Matrix) {
+	ev, err := a.Eigenvalues()
+	assert.NoError(t, err)
+	for _, e := range ev.roots {
+		assert.Less(t, real(e), 0.0, "eigenvalue %v", e)
+	}
+}
+
+func TestCARE(t *testing.T) {
+	// double integrator
+	a := Matrix{{0, 1}, {0, 0}}
+	b := Matrix{{0}, {1}}
+	x, err := CARE(a, b, Identity(2), Matrix{{1}})
+	assert.NoError(t, err)
+	s3 := math.Sqrt(3)
+	assert.InDelta(t, s3, x[0][0], 1e-9)
+	assert.InDelta(t, 1, x[0][1], 1e-9)
+	assert.InDelta(t, 1, x[1][0], 1e-9)
+	assert.InDelta(t, s3, x[1][1], 1e-9)
+
+	// unstable system
+	a = Matrix{{1, 2}, {3, 4}}
+	b = Matrix{{1}, {1}}
+	q := Matrix{{2, 0}, {0, 1}}
+	r := Matrix{{0.5}}
+	x, err = CARE(a, b, q, r)
+	assert.NoError(t, err)
+	res := careResidual(t, a, b, q, r, x)
+	assert.InDelta(t, 0, res.maxAbs(), 1e-8)
+}
+
+func TestStateSpace_LQR(t *testing.T) {
+	sys, err := NewStateSpace(Matrix{{0, 1}, {0, 0}}, Matrix{{0}, {1}}, Matrix{{1, 0}}, Matrix{{0}})
+	assert.NoError(t, err)
+	k, _, err := sys.LQR(Identity(2), Matrix{{1}})
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, k[0][0], 1e-9)
+	assert.InDelta(t, math.Sqrt(3), k[0][1], 1e-9)
+
+	closed, err := sys.StateFeedback(k)
+	assert.NoError(t, err)
+	assertStable(t, closed.A)
+}
+
+func TestStateSpace_Kalman(t *testing.T) {
+	sys, err := NewStateSpace(Matrix{{1, 2}, {3, 4}}, Matrix{{1}, {1}}, Matrix{{1, 0}}, Matrix{{0}})
+	assert.NoError(t, err)
+	l, p, err := sys.Kalman(Matrix{{2}}, Matrix{{0.1}})
+	assert.NoError(t, err)
+
+	// the filter riccati equation is the dual one
+	gq := mul(sys.B.MulFloat(2), sys.B.Transpose(), 2)
+	res := careResidual(t, sys.A.Transpose(), sys.C.Transpose(), gq, Matrix{{0.1}}, p)
+	assert.InDelta(t, 0, res.maxAbs(), 1e-8)
+
+	e, err := sys.ObserverError(l)
+	assert.NoError(t, err)
+	assertStable(t, e)
+}
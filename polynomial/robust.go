@@ -0,0 +1,251 @@
+package polynomial
+
+import (
+	"errors"
+	"github.com/hneemann/parser2/value"
+	"math"
+	"math/cmplx"
+)
+
+// Sensitivity returns the sensitivity function S=1/(1+L)=D/(N+D) of the open loop L
+func (l *Linear) Sensitivity() (*Linear, error) {
+	if l.Delay != 0 {
+		return nil, errors.New("the sensitivity of a system with dead time is not a rational function, use pade to approximate the dead time")
+	}
+	return &Linear{
+		Numerator:   l.Denominator,
+		zeros:       l.poles,
+		Denominator: l.Numerator.Add(l.Denominator),
+	}, nil
+}
+
+// CompSensitivity returns the complementary sensitivity function T=L/(1+L) of the open loop L.
+// It is the closed loop transfer function.
+func (l *Linear) CompSensitivity() (*Linear, error) {
+	return l.Loop()
+}
+
+// Margin is a stability margin at the frequency W
+type Margin struct {
+	W      float64
+	Margin float64
+}
+
+// frequencyRange returns the frequency range in which the crossovers are searched
+func (l *Linear) frequencyRange() (float64, float64, error) {
+	poles, err := l.Poles()
+	if err != nil {
+		return 0, 0, err
+	}
+	zeros, err := l.Zeros()
+	if err != nil {
+		return 0, 0, err
+	}
+	lo := 1.0
+	hi := 1.0
+	for _, r := range append(append([]complex128{}, poles.roots...), zeros.roots...) {
+		a := cmplx.Abs(r)
+		if a > eps {
+			lo = math.Min(lo, a)
+			hi = math.Max(hi, a)
+		}
+	}
+	hi *= 1e3
+	if l.Delay != 0 {
+		hi = math.Max(hi, l.findNyquistMax())
+	}
+	return lo * 1e-3, hi, nil
+}
+
+// frequencySteps is the number of frequencies per decade used to scan the frequency response
+const frequencySteps = 500
+
+// nextFrequency returns the next frequency to sample. The step size is limited
+// if there is a dead time, because it rotates the phase.
+func (l *Linear) nextFrequency(w float64) float64 {
+	w1 := w * math.Pow(10, 1.0/frequencySteps)
+	if l.Delay != 0 {
+		w1 = math.Min(w1, w+0.1/l.Delay)
+	}
+	return w1
+}
+
+// scanFrequencies calls the function found for every interval [w0,w1] at which the
+// function f changes its sign.
+func (l *Linear) scanFrequencies(f func(w float64) float64, found func(w0, w1 float64) error) error {
+	lo, hi, err := l.frequencyRange()
+	if err != nil {
+		return err
+	}
+	w0 := lo
+	y0 := f(w0)
+	for w0 < hi {
+		w1 := l.nextFrequency(w0)
+		y1 := f(w1)
+		if !math.IsNaN(y0) && !math.IsNaN(y1) && (y0 < 0) != (y1 < 0) {
+			err := found(w0, w1)
+			if err != nil {
+				return err
+			}
+		}
+		w0 = w1
+		y0 = y1
+	}
+	return nil
+}
+
+// PMargins returns all gain crossover frequencies with |L(jω)|=1 and the
+// phase margins in degrees at these frequencies.
+func (l *Linear) PMargins() ([]Margin, error) {
+	abs := func(w float64) float64 {
+		return cmplx.Abs(l.EvalCplx(complex(0, w))) - 1
+	}
+	var margins []Margin
+	err := l.scanFrequencies(abs, func(w0, w1 float64) error {
+		w, err := value.Bisection(func(w float64) (float64, error) {
+			return abs(w), nil
+		}, w0, w1, 1e-8)
+		if err != nil {
+			return err
+		}
+		margins = append(margins, Margin{W: w, Margin: l.phaseMargin(w)})
+		return nil
+	})
+	return margins, err
+}
+
+// gMarginRange is the range in dB above the smallest gain margin in which
+// the further gain margins are of interest
+const gMarginRange = 20
+
+// GMargins returns the phase crossover frequencies with a phase of -180° and
+// the gain margins in dB at these frequencies. A dead time causes infinitely
+// many crossovers whose gain margins keep growing. Therefore, only the margins
+// below gMarginRange and the margins which exceed the smallest margin by less
+// than gMarginRange are returned.
+func (l *Linear) GMargins() ([]Margin, error) {
+	im := func(w float64) float64 {
+		c := l.EvalCplx(complex(0, w))
+		if real(c) >= 0 {
+			return math.NaN()
+		}
+		return imag(c)
+	}
+	var margins []Margin
+	err := l.scanFrequencies(im, func(w0, w1 float64) error {
+		w, err := value.Bisection(func(w float64) (float64, error) {
+			return imag(l.EvalCplx(complex(0, w))), nil
+		}, w0, w1, 1e-8)
+		if err != nil {
+			return err
+		}
+		c := l.EvalCplx(complex(0, w))
+		if real(c) < 0 && !cmplx.IsInf(c) {
+			margins = append(margins, Margin{W: w, Margin: 20 * math.Log10(-1/real(c))})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	smallest := math.Inf(1)
+	for _, m := range margins {
+		smallest = math.Min(smallest, m.Margin)
+	}
+	limit := math.Max(0, smallest) + gMarginRange
+	relevant := margins[:0]
+	for _, m := range margins {
+		if m.Margin <= limit {
+			relevant = append(relevant, m)
+		}
+	}
+	return relevant, nil
+}
+
+// findMinimum returns the frequency and the value of the minimum of f in the frequency range.
+// The frequency response is sampled and the minimum is refined by a golden section search.
+func (l *Linear) findMinimum(f func(w float64) float64) (float64, float64, error) {
+	lo, hi, err := l.frequencyRange()
+	if err != nil {
+		return 0, 0, err
+	}
+	a, b := lo, lo
+	best := f(lo)
+	w0 := lo
+	for w0 < hi {
+		w1 := l.nextFrequency(w0)
+		y := f(w1)
+		if y < best {
+			best = y
+			a = w0
+			b = l.nextFrequency(w1)
+		}
+		w0 = w1
+	}
+
+	// golden section search in the neighbourhood of the best sample
+	const g = 0.6180339887498949
+	for b-a > 1e-10*b {
+		x1 := b - g*(b-a)
+		x2 := a + g*(b-a)
+		if f(x1) < f(x2) {
+			b = x2
+		} else {
+			a = x1
+		}
+	}
+	w := (a + b) / 2
+	return w, f(w), nil
+}
+
+// ModulusMargin returns the frequency and the minimal distance of the nyquist curve
+// to the critical point -1. It is the inverse of the maximum of the sensitivity.
+func (l *Linear) ModulusMargin() (Margin, error) {
+	dist := func(w float64) float64 {
+		d := cmplx.Abs(1 + l.EvalCplx(complex(0, w)))
+		if math.IsNaN(d) {
+			return math.Inf(1)
+		}
+		return d
+	}
+	w, d, err := l.findMinimum(dist)
+	if err != nil {
+		return Margin{}, err
+	}
+	m := Margin{W: w, Margin: d}
+
+	if d0 := dist(0); d0 < m.Margin {
+		m = Margin{W: 0, Margin: d0}
+	}
+	if l.Numerator.Degree() <= l.Denominator.Degree() && l.Delay == 0 {
+		inf := 1.0
+		if l.Numerator.Degree() == l.Denominator.Degree() {
+			inf = math.Abs(1 + l.Numerator[len(l.Numerator)-1]/l.Denominator[len(l.Denominator)-1])
+		}
+		if inf < m.Margin {
+			m = Margin{W: math.Inf(1), Margin: inf}
+		}
+	}
+	return m, nil
+}
+
+// DelayMargin returns the smallest additional dead time which makes the closed loop
+// unstable. It is the smallest ratio of the phase margin and the crossover frequency.
+// A negative value indicates that the phase margin is already negative.
+func (l *Linear) DelayMargin() (Margin, error) {
+	pm, err := l.PMargins()
+	if err != nil {
+		return Margin{}, err
+	}
+	if len(pm) == 0 {
+		return Margin{}, errors.New("no crossover frequency")
+	}
+	dm := Margin{Margin: math.Inf(1)}
+	for _, m := range pm {
+		d := m.Margin / 180 * math.Pi / m.W
+		if d < dm.Margin {
+			dm = Margin{W: m.W, Margin: d}
+		}
+	}
+	return dm, nil
+}
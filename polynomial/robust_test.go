@@ -0,0 +1,91 @@
+package polynomial
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestLinear_Sensitivity(t *testing.T) {
+	l := &Linear{Numerator: Polynomial{3, 1}, Denominator: Polynomial{1, 2, 3, 2, 1}}
+	s, err := l.Sensitivity()
+	assert.NoError(t, err)
+	c, err := l.CompSensitivity()
+	assert.NoError(t, err)
+	for _, w := range []float64{0, 0.1, 1, 10} {
+		sum := s.EvalCplx(complex(0, w)) + c.EvalCplx(complex(0, w))
+		assert.InDelta(t, 1, real(sum), 1e-9)
+		assert.InDelta(t, 0, imag(sum), 1e-9)
+	}
+
+	_, err = (&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}, Delay: 1}).Sensitivity()
+	assert.Error(t, err)
+}
+
+func TestLinear_PMargins(t *testing.T) {
+	l := &Linear{Numerator: Polynomial{3}, Denominator: Polynomial{1, 2, 3, 1}}
+	pm, err := l.PMargins()
+	assert.NoError(t, err)
+	assert.Len(t, pm, 1)
+	w0, ph, err := l.PMargin()
+	assert.NoError(t, err)
+	assert.InDelta(t, w0, pm[0].W, 1e-6)
+	assert.InDelta(t, ph, pm[0].Margin, 1e-6)
+
+	// the resonance causes two crossovers although |G(0)|<1
+	l = &Linear{Numerator: Polynomial{0.5}, Denominator: Polynomial{1, 0.02, 1}.Mul(Polynomial{1, 1})}
+	_, _, err = l.PMargin()
+	assert.Error(t, err)
+	pm, err = l.PMargins()
+	assert.NoError(t, err)
+	assert.Len(t, pm, 2)
+	for _, m := range pm {
+		assert.InDelta(t, 1, cmplx.Abs(l.EvalCplx(complex(0, m.W))), 1e-6)
+	}
+}
+
+func TestLinear_GMargins(t *testing.T) {
+	// G=e^{-s}/s has a phase of -180° at ω=π/2+2πk
+	l := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{0, 1}, Delay: 1}
+	gm, err := l.GMargins()
+	assert.NoError(t, err)
+	// the margins are limited to 20dB above the smallest one
+	assert.Len(t, gm, 3)
+	for i, m := range gm {
+		w := math.Pi/2 + 2*math.Pi*float64(i)
+		assert.InDelta(t, w, m.W, 1e-6)
+		assert.InDelta(t, 20*math.Log10(w), m.Margin, 1e-6)
+	}
+
+	// G=2e^{-s}/(s+1) has a phase of -180° at ω=π-arctan(ω)
+	l = &Linear{Numerator: Polynomial{2}, Denominator: Polynomial{1, 1}, Delay: 1}
+	gm, err = l.GMargins()
+	assert.NoError(t, err)
+	assert.Len(t, gm, 4)
+	w := gm[0].W
+	assert.InDelta(t, math.Pi, w+math.Atan(w), 1e-6)
+	assert.InDelta(t, 20*math.Log10(math.Sqrt(1+w*w)/2), gm[0].Margin, 1e-6)
+	for _, m := range gm {
+		assert.LessOrEqual(t, m.Margin, gm[0].Margin+gMarginRange)
+	}
+}
+
+func TestLinear_ModulusMargin(t *testing.T) {
+	// S=s(s+1)/(s²+s+1) has its maximum at ω²=(1+√3)/2
+	l := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{0, 1, 1}}
+	m, err := l.ModulusMargin()
+	assert.NoError(t, err)
+	x := (1 + math.Sqrt(3)) / 2
+	assert.InDelta(t, math.Sqrt(x), m.W, 1e-6)
+	assert.InDelta(t, math.Sqrt((1-x+x*x)/(x+x*x)), m.Margin, 1e-9)
+}
+
+func TestLinear_DelayMarginOfDeadTime(t *testing.T) {
+	// G=e^{-s}/s has the phase margin π/2-1 at ω=1
+	l := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{0, 1}, Delay: 1}
+	dm, err := l.DelayMargin()
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, dm.W, 1e-6)
+	assert.InDelta(t, math.Pi/2-1, dm.Margin, 1e-6)
+}
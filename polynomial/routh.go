@@ -0,0 +1,290 @@
+package polynomial
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// routhEpsilon is the relative value used to replace a zero in the first column
+const routhEpsilon = 1e-9
+
+// RouthTable is the Routh array of a polynomial. The first row belongs to
+// the highest power of s.
+type RouthTable struct {
+	Rows [][]float64
+	// Aux marks the rows which are replaced by the derivative of the auxiliary polynomial
+	Aux []bool
+	// Eps marks the rows whose zero first element is replaced by a small positive ε
+	Eps []bool
+}
+
+// Routh creates the Routh array of the polynomial. If a row contains only zeros,
+// it is replaced by the derivative of the auxiliary polynomial formed by the row
+// above. A single zero in the first column is replaced by a small positive ε.
+// A zero in the last row is kept, since it is the constant coefficient of the
+// polynomial, which means that there is a root at the origin.
+func (p Polynomial) Routh() (*RouthTable, error) {
+	p = p.Canonical()
+	n := p.Degree()
+	if n < 1 {
+		return nil, errors.New("the polynomial needs to have at least degree one")
+	}
+	width := n/2 + 1
+	rows := make([][]float64, n+1)
+	for k := range rows {
+		rows[k] = make([]float64, width)
+	}
+	scale := 0.0
+	for i, c := range p {
+		rows[(n-i)%2][(n-i)/2] = c
+		scale = math.Max(scale, math.Abs(c))
+	}
+
+	rt := &RouthTable{Rows: rows, Aux: make([]bool, n+1), Eps: make([]bool, n+1)}
+	for k := 1; k <= n; k++ {
+		row := rows[k]
+		if k > 1 {
+			a := rows[k-2]
+			b := rows[k-1]
+			for i := 0; i < width-1; i++ {
+				row[i] = (b[0]*a[i+1] - a[0]*b[i+1]) / b[0]
+			}
+		}
+		rowScale := 0.0
+		for _, v := range rows[k-1] {
+			rowScale = math.Max(rowScale, math.Abs(v))
+		}
+		isZero := true
+		for i, v := range row {
+			if math.Abs(v) <= eps*rowScale {
+				row[i] = 0
+			} else {
+				isZero = false
+			}
+		}
+		if k == n {
+			// the last row contains the constant coefficient
+			break
+		}
+		if isZero {
+			// derivative of the auxiliary polynomial of the row above
+			m := n - k + 1
+			for i := range row {
+				if m-2*i > 0 {
+					row[i] = rows[k-1][i] * float64(m-2*i)
+				}
+			}
+			rt.Aux[k] = true
+		}
+		if row[0] == 0 {
+			row[0] = routhEpsilon * scale
+			rt.Eps[k] = true
+		}
+		for _, v := range row {
+			scale = math.Max(scale, math.Abs(v))
+		}
+	}
+	return rt, nil
+}
+
+// SignChanges returns the number of sign changes in the first column, which
+// is the number of roots in the right half plane.
+func (rt *RouthTable) SignChanges() int {
+	n := 0
+	for k := 1; k < len(rt.Rows); k++ {
+		if (rt.Rows[k-1][0] < 0) != (rt.Rows[k][0] < 0) {
+			n++
+		}
+	}
+	return n
+}
+
+// RootAtOrigin returns true if the last row is zero, which means that
+// the polynomial has a root at s=0.
+func (rt *RouthTable) RootAtOrigin() bool {
+	return rt.Rows[len(rt.Rows)-1][0] == 0
+}
+
+// Stable returns true if all roots are in the left half plane.
+func (rt *RouthTable) Stable() bool {
+	if rt.SignChanges() > 0 || rt.RootAtOrigin() {
+		return false
+	}
+	for k := range rt.Rows {
+		if rt.Aux[k] || rt.Eps[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// ToLaTeX writes the Routh array as a table. The elements which are
+// dominated by 1/ε are written as a fraction. These are the elements
+// aᵢ₊₁-a₀bᵢ₊₁/ε of the row following the pivot b₀=ε with bᵢ₊₁≠0.
+func (rt *RouthTable) ToLaTeX(w *bytes.Buffer) {
+	n := len(rt.Rows) - 1
+	w.WriteString("\\table[r|")
+	w.WriteString(strings.Repeat("r", len(rt.Rows[0])))
+	w.WriteString("]{")
+	for k, row := range rt.Rows {
+		if k > 0 {
+			w.WriteString("\\\\")
+		}
+		w.WriteString("s^{")
+		w.WriteString(strconv.Itoa(n - k))
+		w.WriteString("}")
+		for i, v := range row {
+			w.WriteString("&")
+			switch {
+			case i == 0 && rt.Eps[k]:
+				w.WriteString("\\varepsilon")
+			case k > 1 && rt.Eps[k-1] && i+1 < len(row) && rt.Rows[k-1][i+1] != 0 && rt.Rows[k-2][0] != 0:
+				// the term dominated by 1/ε
+				w.WriteString("\\frac{")
+				w.WriteString(laTeXFloat(-rt.Rows[k-2][0] * rt.Rows[k-1][i+1]))
+				w.WriteString("}{\\varepsilon}")
+			default:
+				w.WriteString(laTeXFloat(v))
+			}
+		}
+	}
+	w.WriteString("}")
+}
+
+func (rt *RouthTable) LaTeX() string {
+	var b bytes.Buffer
+	rt.ToLaTeX(&b)
+	return b.String()
+}
+
+// imagAxis returns the real and the imaginary part of p(jω) as polynomials in ω
+func (p Polynomial) imagAxis() (Polynomial, Polynomial) {
+	re := make(Polynomial, len(p))
+	im := make(Polynomial, len(p))
+	for i, c := range p {
+		switch i % 4 {
+		case 0:
+			re[i] = c
+		case 1:
+			im[i] = c
+		case 2:
+			re[i] = -c
+		case 3:
+			im[i] = -c
+		}
+	}
+	return re, im
+}
+
+// RouthRange calculates the ranges of the parameter k for which all roots of the
+// characteristic polynomial cpp(k) are in the left half plane. The coefficients of
+// the polynomial need to depend linearly on k. The boundaries of the ranges are the
+// values of k at which a root crosses the imaginary axis or the degree drops.
+func RouthRange(cpp func(k float64) (Polynomial, error)) ([][2]float64, error) {
+	p0, err := cpp(0)
+	if err != nil {
+		return nil, err
+	}
+	p1, err := cpp(1)
+	if err != nil {
+		return nil, err
+	}
+	p2, err := cpp(2)
+	if err != nil {
+		return nil, err
+	}
+	p1 = p1.Add(p0.MulFloat(-1))
+	check := p2.Add(p0.Add(p1.MulFloat(2)).MulFloat(-1))
+	scale := 0.0
+	for _, c := range p2 {
+		scale = math.Max(scale, math.Abs(c))
+	}
+	for _, c := range check {
+		if math.Abs(c) > 1e-9*scale {
+			return nil, errors.New("the coefficients of the polynomial need to depend linearly on the parameter")
+		}
+	}
+
+	var candidates []float64
+	if math.Abs(p1.Eval(0)) > eps {
+		candidates = append(candidates, -p0.Eval(0)/p1.Eval(0))
+	}
+	// the degree drops if the leading coefficient becomes zero
+	n := max(len(p0), len(p1)) - 1
+	if n < len(p1) && math.Abs(p1[n]) > eps {
+		lead := 0.0
+		if n < len(p0) {
+			lead = p0[n]
+		}
+		candidates = append(candidates, -lead/p1[n])
+	}
+
+	// p0(jω)+k·p1(jω)=0 requires Im(p0(jω)·conj(p1(jω)))=0, which is ω·E(ω²)
+	re0, im0 := p0.imagAxis()
+	re1, im1 := p1.imagAxis()
+	f := im0.Mul(re1).Add(re0.Mul(im1).MulFloat(-1)).Canonical()
+	if len(f) > 1 {
+		e := make(Polynomial, len(f)/2)
+		for i := range e {
+			e[i] = f[2*i+1]
+		}
+		e = e.Canonical()
+		if e.Degree() > 0 {
+			roots, err := e.Roots()
+			if err != nil {
+				return nil, fmt.Errorf("could not calculate the crossings of the imaginary axis: %w", err)
+			}
+			for _, x := range roots.roots {
+				if imag(x) != 0 || real(x) <= 0 {
+					continue
+				}
+				s := complex(0, math.Sqrt(real(x)))
+				d := p1.EvalCplx(s)
+				if cmplx.Abs(d) > eps {
+					candidates = append(candidates, real(-p0.EvalCplx(s)/d))
+				}
+			}
+		}
+	}
+	for i, c := range candidates {
+		if c == 0 {
+			// avoid a negative zero
+			candidates[i] = 0
+		}
+	}
+	sort.Float64s(candidates)
+
+	stable := func(k float64) bool {
+		rt, err := p0.Add(p1.MulFloat(k)).Routh()
+		return err == nil && rt.Stable()
+	}
+
+	var ranges [][2]float64
+	if len(candidates) == 0 {
+		if stable(0) {
+			ranges = append(ranges, [2]float64{math.Inf(-1), math.Inf(1)})
+		}
+		return ranges, nil
+	}
+	first := candidates[0]
+	if stable(first - math.Max(1, math.Abs(first))) {
+		ranges = append(ranges, [2]float64{math.Inf(-1), first})
+	}
+	for i := 1; i < len(candidates); i++ {
+		a, b := candidates[i-1], candidates[i]
+		if b-a > eps*math.Max(1, math.Abs(a)) && stable((a+b)/2) {
+			ranges = append(ranges, [2]float64{a, b})
+		}
+	}
+	last := candidates[len(candidates)-1]
+	if stable(last + math.Max(1, math.Abs(last))) {
+		ranges = append(ranges, [2]float64{last, math.Inf(1)})
+	}
+	return ranges, nil
+}
@@ -0,0 +1,74 @@
+package polynomial
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestPolynomial_Routh(t *testing.T) {
+	tests := []struct {
+		name   string
+		p      Polynomial
+		first  []float64
+		rhp    int
+		stable bool
+	}{
+		{"stable", Polynomial{6, 11, 6, 1}, []float64{1, 6, 10, 6}, 0, true},
+		{"unstable", Polynomial{10, 3, 2, 1}, []float64{1, 2, -2, 10}, 2, false},
+		{"epsilon", Polynomial{3, 2, 2, 1, 1}, []float64{1, 1, 0, -1e9, 3}, 2, false},
+		{"auxiliary", Polynomial{4, 0, 5, 0, 1}, []float64{1, 4, 2.5, 3.6, 4}, 0, false},
+		{"origin", Polynomial{0, 1, 1}, []float64{1, 1, 0}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt, err := tt.p.Routh()
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.first), len(rt.Rows))
+			for i, f := range tt.first {
+				assert.InDelta(t, f, rt.Rows[i][0], math.Abs(f)*1e-3+1e-6, "row %d", i)
+			}
+			assert.Equal(t, tt.rhp, rt.SignChanges())
+			assert.Equal(t, tt.stable, rt.Stable())
+		})
+	}
+}
+
+func TestRouthRange(t *testing.T) {
+	tests := []struct {
+		name string
+		cpp  func(k float64) (Polynomial, error)
+		want [][2]float64
+	}{
+		{"3rd order", func(k float64) (Polynomial, error) {
+			return Polynomial{k, 2, 3, 1}, nil
+		}, [][2]float64{{0, 6}}},
+		{"2nd order", func(k float64) (Polynomial, error) {
+			return Polynomial{2 + k, 3, 1}, nil
+		}, [][2]float64{{-2, math.Inf(1)}}},
+		{"unstable", func(k float64) (Polynomial, error) {
+			return Polynomial{k, -1, 1}, nil
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RouthRange(tt.cpp)
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.want), len(got))
+			for i := range tt.want {
+				for j := range 2 {
+					if math.IsInf(tt.want[i][j], 0) {
+						assert.Equal(t, tt.want[i][j], got[i][j])
+					} else {
+						assert.InDelta(t, tt.want[i][j], got[i][j], 1e-6)
+					}
+				}
+			}
+		})
+	}
+
+	_, err := RouthRange(func(k float64) (Polynomial, error) {
+		return Polynomial{1, k * k, 1}, nil
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,444 @@
+package polynomial
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hneemann/control/graph"
+	"github.com/hneemann/parser2/funcGen"
+	"github.com/hneemann/parser2/value"
+	"github.com/hneemann/parser2/value/export"
+	"github.com/hneemann/parser2/value/export/xmlWriter"
+)
+
+// StateSpace is a linear system given in the state space representation
+//
+//	x' = A x + B u
+//	y  = C x + D u
+type StateSpace struct {
+	A, B, C, D Matrix
+}
+
+var _ export.ToHtmlInterface = &StateSpace{}
+
+// NewStateSpace creates a new state space system and checks the dimensions of the matrices.
+func NewStateSpace(a, b, c, d Matrix) (*StateSpace, error) {
+	n := a.Rows()
+	p := d.Rows()
+	m := d.Cols()
+	if p == 0 || m == 0 {
+		return nil, errors.New("the matrix D must not be empty")
+	}
+	if n > 0 && a.Cols() != n {
+		return nil, errors.New("the matrix A needs to be square")
+	}
+	if b.Rows() != n || (n > 0 && b.Cols() != m) {
+		return nil, fmt.Errorf("the matrix B needs to be a %dx%d matrix", n, m)
+	}
+	if c.Rows() != p || c.Cols() != n {
+		return nil, fmt.Errorf("the matrix C needs to be a %dx%d matrix", p, n)
+	}
+	return &StateSpace{A: a, B: b, C: c, D: d}, nil
+}
+
+// StateSpace returns the state space representation of the linear system.
+// The controllable canonical form is used. A system with dead time
+// has no state space representation.
+func (l *Linear) StateSpace() (*StateSpace, error) {
+	if l.Delay != 0 {
+		return nil, errDeadTime
+	}
+	a, c, d, err := l.GetStateSpaceRepresentation()
+	if err != nil {
+		return nil, err
+	}
+	n := len(a)
+	b := NewMatrix(n, 1)
+	if n > 0 {
+		b[n-1][0] = 1
+	}
+	cm := NewMatrix(1, n)
+	copy(cm[0], c)
+	return &StateSpace{A: a, B: b, C: cm, D: Matrix{Vector{d}}}, nil
+}
+
+func (s *StateSpace) Order() int {
+	return s.A.Rows()
+}
+
+func (s *StateSpace) Inputs() int {
+	return s.D.Cols()
+}
+
+func (s *StateSpace) Outputs() int {
+	return s.D.Rows()
+}
+
+func (s *StateSpace) isSISO() bool {
+	return s.Inputs() == 1 && s.Outputs() == 1
+}
+
+// Linear returns the transfer function of a single input single output system.
+// It is calculated by the identity C(sI-A)⁻¹B = det(sI-A+BC)/det(sI-A)-1.
+func (s *StateSpace) Linear() (*Linear, error) {
+	if !s.isSISO() {
+		return nil, errors.New("only a single input single output system can be converted to a transfer function")
+	}
+	n := s.Order()
+	den, err := s.A.CharPoly()
+	if err != nil {
+		return nil, err
+	}
+	bc := mul(s.B, s.C, n)
+	ab, err := s.A.SubMatrix(bc)
+	if err != nil {
+		return nil, err
+	}
+	p, err := ab.CharPoly()
+	if err != nil {
+		return nil, err
+	}
+	num := p.Add(den.MulFloat(s.D[0][0] - 1)).Canonical()
+	return &Linear{Numerator: num, Denominator: den}, nil
+}
+
+// Poles returns the eigenvalues of the system matrix A.
+func (s *StateSpace) Poles() (Roots, error) {
+	return s.A.Eigenvalues()
+}
+
+// Series creates the series connection of the two systems.
+// The output of s is connected to the input of o.
+func (s *StateSpace) Series(o *StateSpace) (*StateSpace, error) {
+	if s.Outputs() != o.Inputs() {
+		return nil, errors.New("the number of outputs does not match the number of inputs")
+	}
+	n1 := s.Order()
+	n2 := o.Order()
+	a, err := Stack([][]Matrix{
+		{s.A, NewMatrix(n1, n2)},
+		{mul(o.B, s.C, n1), o.A},
+	})
+	if err != nil {
+		return nil, err
+	}
+	b, err := Stack([][]Matrix{{s.B}, {mul(o.B, s.D, s.Inputs())}})
+	if err != nil {
+		return nil, err
+	}
+	c, err := Stack([][]Matrix{{mul(o.D, s.C, n1), o.C}})
+	if err != nil {
+		return nil, err
+	}
+	return NewStateSpace(a, b, c, mul(o.D, s.D, s.Inputs()))
+}
+
+// Parallel creates the parallel connection of the two systems.
+// Both systems get the same input and the outputs are added.
+func (s *StateSpace) Parallel(o *StateSpace) (*StateSpace, error) {
+	if s.Outputs() != o.Outputs() || s.Inputs() != o.Inputs() {
+		return nil, errors.New("the number of inputs and outputs of both systems need to be equal")
+	}
+	n1 := s.Order()
+	n2 := o.Order()
+	a, err := Stack([][]Matrix{
+		{s.A, NewMatrix(n1, n2)},
+		{NewMatrix(n2, n1), o.A},
+	})
+	if err != nil {
+		return nil, err
+	}
+	b, err := Stack([][]Matrix{{s.B}, {o.B}})
+	if err != nil {
+		return nil, err
+	}
+	c, err := Stack([][]Matrix{{s.C, o.C}})
+	if err != nil {
+		return nil, err
+	}
+	return NewStateSpace(a, b, c, add(s.D, o.D))
+}
+
+// Feedback closes the loop. The system s is placed in the forward path,
+// the system o in the feedback path. If sign is negative, a negative feedback is used,
+// if it is positive, a positive feedback is used. A sign of zero is rejected.
+func (s *StateSpace) Feedback(o *StateSpace, sign float64) (*StateSpace, error) {
+	if s.Outputs() != o.Inputs() || s.Inputs() != o.Outputs() {
+		return nil, errors.New("the dimensions of the systems do not match")
+	}
+	if sign == 0 {
+		return nil, errFeedbackSign
+	}
+	if sign < 0 {
+		sign = -1
+	} else {
+		sign = 1
+	}
+	n1 := s.Order()
+	n2 := o.Order()
+	m := s.Inputs()
+	p := s.Outputs()
+
+	// u1 = r + sign*y2, u2 = y1
+	e, err := Identity(p).SubMatrix(mul(s.D, o.D, p).MulFloat(sign))
+	if err != nil {
+		return nil, err
+	}
+	e, err = e.Inverse()
+	if err != nil {
+		return nil, errors.New("the feedback loop is not well-posed")
+	}
+
+	// y1 = y1x1*x1 + y1x2*x2 + y1r*r
+	y1x1 := mul(e, s.C, n1)
+	y1x2 := mul(mul(e, s.D, m), o.C, n2).MulFloat(sign)
+	y1r := mul(e, s.D, m)
+	// u1 = u1x1*x1 + u1x2*x2 + u1r*r
+	u1x1 := mul(o.D, y1x1, n1).MulFloat(sign)
+	u1x2 := add(o.C, mul(o.D, y1x2, n2)).MulFloat(sign)
+	u1r := add(Identity(m), mul(o.D, y1r, m).MulFloat(sign))
+
+	a, err := Stack([][]Matrix{
+		{add(s.A, mul(s.B, u1x1, n1)), mul(s.B, u1x2, n2)},
+		{mul(o.B, y1x1, n1), add(o.A, mul(o.B, y1x2, n2))},
+	})
+	if err != nil {
+		return nil, err
+	}
+	b, err := Stack([][]Matrix{{mul(s.B, u1r, m)}, {mul(o.B, y1r, m)}})
+	if err != nil {
+		return nil, err
+	}
+	c, err := Stack([][]Matrix{{y1x1, y1x2}})
+	if err != nil {
+		return nil, err
+	}
+	return NewStateSpace(a, b, c, y1r)
+}
+
+// Simulate simulates the system using the euler method with the step width dt.
+func (s *StateSpace) Simulate(tMax, dt float64, u func(float64) (float64, error)) (*value.List, error) {
+	list, _, err := s.SimulateSolver(Euler, tMax, dt, u)
+	return list, err
+}
+
+// SimulateAdaptive simulates the system using the Dormand-Prince method with an adaptive step width.
+// The step width does not exceed hMax. If hMax is zero, tMax/100 is used.
+// One point per accepted step is returned, together with the number of steps.
+func (s *StateSpace) SimulateAdaptive(tMax, hMax float64, u func(float64) (float64, error)) (*value.List, int, error) {
+	return s.SimulateSolver(RK45, tMax, hMax, u)
+}
+
+// SimulateSolver simulates the system using the given solver.
+// For the fixed step solvers dt is the step width, for the adaptive solver
+// it is the maximum step width. Returns the simulated points and the number of steps.
+func (s *StateSpace) SimulateSolver(solver Solver, tMax, dt float64, u func(float64) (float64, error)) (*value.List, int, error) {
+	if !s.isSISO() {
+		return nil, 0, errors.New("only a single input single output system can be simulated")
+	}
+	lists, steps, err := s.SimulateVector(solver, tMax, dt, func(t float64, uv Vector) error {
+		ut, err := u(t)
+		uv[0] = ut
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return lists[0], steps, nil
+}
+
+// SimulateVector simulates a system with multiple inputs and outputs. The function u
+// writes the input vector at the time t to uv. One list of points is returned for each
+// output, together with the number of steps.
+func (s *StateSpace) SimulateVector(solver Solver, tMax, dt float64, u func(t float64, uv Vector) error) ([]*value.List, int, error) {
+	if tMax <= 0 {
+		return nil, 0, fmt.Errorf("tMax must be greater than 0")
+	}
+
+	p := s.Outputs()
+	uv := make(Vector, s.Inputs())
+	f := func(t float64, x, dx Vector) error {
+		err := u(t, uv)
+		if err != nil {
+			return err
+		}
+		s.A.Mul(dx, x)
+		for i := range dx {
+			dx[i] += s.B[i].Mul(uv)
+		}
+		return nil
+	}
+	output := func(t float64, x Vector, y func(i int, yi float64)) error {
+		err := u(t, uv)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < p; i++ {
+			y(i, s.C[i].Mul(x)+s.D[i].Mul(uv))
+		}
+		return nil
+	}
+	x := make(Vector, s.Order())
+
+	if solver == RK45 {
+		if dt <= 0 {
+			dt = tMax / 100
+		}
+		points := make([][]value.Value, p)
+		steps, err := rk45(f, x, tMax, dt, func(t float64, x Vector) error {
+			return output(t, x, func(i int, yi float64) {
+				points[i] = append(points[i], graph.Vector3d{X: t, Y: yi})
+			})
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		lists := make([]*value.List, p)
+		for i := range lists {
+			lists[i] = value.NewList(points[i]...)
+		}
+		return lists, steps, nil
+	}
+
+	if dt <= 0 {
+		if solver == Euler {
+			dt = 1e-5
+		} else {
+			dt = tMax / 1000
+		}
+	} else if solver != Euler && dt > tMax/10 {
+		// at least a few points are required
+		dt = tMax / 10
+	}
+
+	const shouldPointsExported = 1000
+	skip := int(tMax/dt) / shouldPointsExported
+	if skip < 1 {
+		if solver == Euler {
+			return nil, 0, fmt.Errorf("step width (dt=%v) is too large for a meaningful simulation", dt)
+		}
+		skip = 1
+	}
+	pointsExported := int(tMax / (dt * float64(skip)))
+	steps := pointsExported * skip
+
+	data := newDataSet(pointsExported+1, p+1)
+	row := 0
+	counter := 0
+	err := fixedStep(solver, f, x, steps, dt, func(t float64, x Vector) error {
+		if counter == 0 {
+			data.set(row, 0, t)
+			err := output(t, x, func(i int, yi float64) {
+				data.set(row, i+1, yi)
+			})
+			if err != nil {
+				return err
+			}
+			row++
+			counter = skip
+		}
+		counter--
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	lists := make([]*value.List, p)
+	for i := range lists {
+		lists[i] = data.toPointList(0, i+1)
+	}
+	return lists, steps, nil
+}
+
+// String returns the four matrices, one row per line. An empty matrix is
+// written as [].
+func (s *StateSpace) String() string {
+	var str string
+	for i, m := range []Matrix{s.A, s.B, s.C, s.D} {
+		str += string(rune('A'+i)) + "="
+		if len(m) == 0 {
+			str += "[]\n"
+			continue
+		}
+		for j, row := range m {
+			if j > 0 {
+				str += "  "
+			}
+			str += fmt.Sprintf("%v\n", row)
+		}
+	}
+	return str
+}
+
+func (s *StateSpace) ToHtml(_ funcGen.Stack[value.Value], w *xmlWriter.XMLWriter) error {
+	w.Open("math").
+		Attr("xmlns", "http://www.w3.org/1998/Math/MathML")
+	w.Open("mrow")
+	for i, m := range []Matrix{s.A, s.B, s.C, s.D} {
+		if i > 0 {
+			w.Open("mspace").Attr("width", "1em").Close()
+		}
+		w.Open("mi").Write(string(rune('A' + i))).Close().
+			Open("mo").Write("=").Close()
+		m.ToMathML(w)
+	}
+	w.Close()
+	w.Close()
+	return nil
+}
+
+func (s *StateSpace) Get(key string) (value.Value, bool) {
+	switch key {
+	case "A":
+		return s.A, true
+	case "B":
+		return s.B, true
+	case "C":
+		return s.C, true
+	case "D":
+		return s.D, true
+	}
+	return nil, false
+}
+
+func (s *StateSpace) Iter(yield func(key string, v value.Value) bool) {
+	if !yield("A", s.A) {
+		return
+	}
+	if !yield("B", s.B) {
+		return
+	}
+	if !yield("C", s.C) {
+		return
+	}
+	if !yield("D", s.D) {
+		return
+	}
+}
+
+func (s *StateSpace) Size() int {
+	return 4
+}
+
+func (s *StateSpace) ToList() (*value.List, bool) {
+	return nil, false
+}
+
+func (s *StateSpace) ToMap() (value.Map, bool) {
+	return value.NewMap(s), true
+}
+
+func (s *StateSpace) ToInt() (int, bool) {
+	return 0, false
+}
+
+func (s *StateSpace) ToFloat() (float64, bool) {
+	return 0, false
+}
+
+func (s *StateSpace) ToString(_ funcGen.Stack[value.Value]) (string, error) {
+	return s.String(), nil
+}
+
+func (s *StateSpace) GetType() value.Type {
+	return StateSpaceValueType
+}
@@ -0,0 +1,129 @@
+package polynomial
+
+import (
+	"github.com/hneemann/control/graph"
+	"github.com/hneemann/parser2/funcGen"
+	"github.com/hneemann/parser2/value"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func normalized(t *testing.T, l *Linear) *Linear {
+	n, err := l.Normalize()
+	assert.NoError(t, err)
+	return n
+}
+
+func TestStateSpace_Linear(t *testing.T) {
+	tests := []struct {
+		name string
+		lin  *Linear
+	}{
+		{"PT1", &Linear{Numerator: Polynomial{2}, Denominator: Polynomial{1, 3}}},
+		{"PT2", &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 2, 3}}},
+		{"phase", &Linear{Numerator: Polynomial{1, 2}, Denominator: Polynomial{1, 3}}},
+		{"zeros", &Linear{Numerator: Polynomial{1, 2, 3}, Denominator: Polynomial{4, 3, 2, 1}}},
+		{"const", NewConst(3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss, err := tt.lin.StateSpace()
+			assert.NoError(t, err)
+			lin, err := ss.Linear()
+			assert.NoError(t, err)
+			expected := normalized(t, tt.lin)
+			assert.True(t, expected.Equals(lin), lin.String())
+		})
+	}
+
+	_, err := (&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}, Delay: 1}).StateSpace()
+	assert.Equal(t, errDeadTime, err)
+}
+
+func TestStateSpace_String(t *testing.T) {
+	ss, err := NewConst(5).StateSpace()
+	assert.NoError(t, err)
+	assert.Equal(t, "A=[]\nB=[]\nC=[]\nD=[5]\n", ss.String())
+
+	ss, err = NewStateSpace(Matrix{{0, 1}, {-2, -3}}, Matrix{{0}, {1}}, Matrix{{1, 0}}, Matrix{{0}})
+	assert.NoError(t, err)
+	assert.Equal(t, "A=[0 1]\n  [-2 -3]\nB=[0]\n  [1]\nC=[1 0]\nD=[0]\n", ss.String())
+}
+
+func TestStateSpace_Interconnection(t *testing.T) {
+	g1 := &Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}
+	g2 := &Linear{Numerator: Polynomial{2, 1}, Denominator: Polynomial{3, 1}}
+	s1, err := g1.StateSpace()
+	assert.NoError(t, err)
+	s2, err := g2.StateSpace()
+	assert.NoError(t, err)
+
+	sum, err := g1.Add(g2)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		op       func() (*StateSpace, error)
+		expected *Linear
+	}{
+		{"series", func() (*StateSpace, error) { return s1.Series(s2) }, g1.Mul(g2)},
+		{"parallel", func() (*StateSpace, error) { return s1.Parallel(s2) }, sum},
+		{"feedback", func() (*StateSpace, error) { return s1.Feedback(s2, -1) },
+			&Linear{Numerator: Polynomial{3, 1}, Denominator: Polynomial{5, 5, 1}}},
+		{"posFeedback", func() (*StateSpace, error) { return s1.Feedback(s2, 1) },
+			&Linear{Numerator: Polynomial{3, 1}, Denominator: Polynomial{1, 3, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss, err := tt.op()
+			assert.NoError(t, err)
+			lin, err := ss.Linear()
+			assert.NoError(t, err)
+			expected := normalized(t, tt.expected)
+			assert.True(t, expected.Equals(lin), lin.String())
+		})
+	}
+
+	_, err = s1.Feedback(s2, 0)
+	assert.Equal(t, errFeedbackSign, err)
+}
+
+func TestStateSpace_Poles(t *testing.T) {
+	ss, err := NewStateSpace(Matrix{{0, 1}, {-2, -3}}, Matrix{{0}, {1}}, Matrix{{1, 0}}, Matrix{{0}})
+	assert.NoError(t, err)
+	p, err := ss.Poles()
+	assert.NoError(t, err)
+	assert.True(t, p.Equals(NewRoots(-1, -2)), p.String())
+}
+
+func TestStateSpace_Dimensions(t *testing.T) {
+	_, err := NewStateSpace(Matrix{{0, 1}, {-2, -3}}, Matrix{{0}, {1}}, Matrix{{1, 0, 0}}, Matrix{{0}})
+	assert.Error(t, err)
+	_, err = NewStateSpace(Matrix{{0, 1}, {-2, -3}}, Matrix{{0}}, Matrix{{1, 0}}, Matrix{{0}})
+	assert.Error(t, err)
+}
+
+func TestStateSpace_Simulate(t *testing.T) {
+	lin := &Linear{Numerator: Polynomial{1, 2}, Denominator: Polynomial{1, 2, 3}}
+	ss, err := NewStateSpace(Matrix{{-1}}, Matrix{{1}}, Matrix{{1}}, Matrix{{0}})
+	assert.NoError(t, err)
+	ss, err = ss.Series(Must(lin.StateSpace()))
+	assert.NoError(t, err)
+
+	step := func(t float64) (float64, error) { return 1, nil }
+	l1, err := lin.Mul(&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}).Simulate(10, 0, step)
+	assert.NoError(t, err)
+	l2, err := ss.Simulate(10, 0, step)
+	assert.NoError(t, err)
+
+	st := funcGen.NewEmptyStack[value.Value]()
+	p1, err := l1.ToSlice(st)
+	assert.NoError(t, err)
+	p2, err := l2.ToSlice(st)
+	assert.NoError(t, err)
+	assert.Equal(t, len(p1), len(p2))
+	f1 := p1[len(p1)-1].(graph.Vector3d).Y
+	f2 := p2[len(p2)-1].(graph.Vector3d).Y
+	assert.InDelta(t, 1, f1, 1e-2)
+	assert.InDelta(t, f1, f2, 1e-6)
+}
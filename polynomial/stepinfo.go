@@ -0,0 +1,142 @@
+package polynomial
+
+import (
+	"errors"
+	"math"
+)
+
+// StepInfo contains the characteristic values of a step response
+type StepInfo struct {
+	// RiseTime is the time the response needs to rise from 10% to 90% of the steady state value
+	RiseTime float64
+	// RiseStart and RiseEnd are the times at which 10% and 90% of the steady state value are reached
+	RiseStart, RiseEnd float64
+	// PeakTime is the time of the maximum, NaN if the response rises up to the last sample
+	PeakTime float64
+	Peak     float64
+	// Overshoot is the overshoot in percent of the steady state value
+	Overshoot float64
+	// SettlingTime is the time at which the response enters and stays in the error band
+	SettlingTime     float64
+	SteadyState      float64
+	SteadyStateError float64
+}
+
+// CalcStepInfo calculates the characteristic values of the step response given by the
+// times t and the values y. The band is the relative width of the error band used to
+// determine the settling time.
+func CalcStepInfo(t, y []float64, steadyState, band float64) (*StepInfo, error) {
+	if len(t) != len(y) || len(t) < 2 {
+		return nil, errors.New("at least two points are required")
+	}
+	if steadyState == 0 {
+		return nil, errors.New("the steady state value is zero")
+	}
+	yn := func(i int) float64 {
+		return y[i] / steadyState
+	}
+	// crossing returns the time at which the normalized response crosses the value v
+	crossing := func(v float64) float64 {
+		if yn(0) >= v {
+			return t[0]
+		}
+		for i := 1; i < len(t); i++ {
+			if yn(i) >= v {
+				y0 := yn(i - 1)
+				return t[i-1] + (v-y0)/(yn(i)-y0)*(t[i]-t[i-1])
+			}
+		}
+		return math.NaN()
+	}
+	si := &StepInfo{
+		SteadyState:      steadyState,
+		SteadyStateError: 1 - steadyState,
+	}
+	si.RiseStart = crossing(0.1)
+	si.RiseEnd = crossing(0.9)
+	if math.IsNaN(si.RiseEnd) {
+		return nil, errors.New("the response does not reach 90% of the steady state value")
+	}
+	si.RiseTime = si.RiseEnd - si.RiseStart
+
+	peak := 0
+	for i := range y {
+		if yn(i) > yn(peak) {
+			peak = i
+		}
+	}
+	if peak == len(t)-1 {
+		// no maximum within the samples, the response still rises
+		si.PeakTime = math.NaN()
+	} else {
+		si.PeakTime = t[peak]
+	}
+	si.Peak = y[peak]
+	si.Overshoot = math.Max(0, (yn(peak)-1)*100)
+
+	last := -1
+	for i := len(t) - 1; i >= 0; i-- {
+		if math.Abs(yn(i)-1) > band {
+			last = i
+			break
+		}
+	}
+	switch {
+	case last < 0:
+		si.SettlingTime = t[0]
+	case last == len(t)-1:
+		return nil, errors.New("the response does not settle in the given time")
+	default:
+		// interpolate the entrance into the band
+		y0, y1 := yn(last)-1, yn(last+1)-1
+		b := band
+		if y0 < 0 {
+			b = -band
+		}
+		si.SettlingTime = t[last] + (b-y0)/(y1-y0)*(t[last+1]-t[last])
+	}
+	return si, nil
+}
+
+// StepInfo calculates the characteristic values of the step response of a stable system.
+// The exact step response obtained by the partial fraction expansion is used.
+func (l *Linear) StepInfo(band float64) (*StepInfo, error) {
+	t, y, err := l.sampleStep()
+	if err != nil {
+		return nil, err
+	}
+	return CalcStepInfo(t, y, l.Eval(0), band)
+}
+
+// sampleStep samples the exact step response of a stable system until it has settled
+func (l *Linear) sampleStep() ([]float64, []float64, error) {
+	poles, err := l.Poles()
+	if err != nil {
+		return nil, nil, err
+	}
+	sigma := math.Inf(1)
+	for _, p := range poles.roots {
+		if real(p) >= 0 {
+			return nil, nil, errors.New("the system is not stable")
+		}
+		sigma = math.Min(sigma, -real(p))
+	}
+	pf, err := l.StepAnalytic()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tMax := 20 / sigma
+	if math.IsInf(sigma, 1) {
+		tMax = 1
+	}
+	tMax += l.Delay
+	const points = 20000
+	t := make([]float64, points+1)
+	y := make([]float64, points+1)
+	for i := range t {
+		t[i] = tMax * float64(i) / points
+		y[i] = pf.Eval(t[i])
+	}
+	return t, y, nil
+}
@@ -0,0 +1,56 @@
+package polynomial
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestLinear_StepInfo(t *testing.T) {
+	// second order system with damping 0.5
+	si, err := (&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1, 1}}).StepInfo(0.02)
+	assert.NoError(t, err)
+	wd := math.Sqrt(0.75)
+	assert.InDelta(t, 100*math.Exp(-math.Pi*0.5/wd), si.Overshoot, 1e-3)
+	assert.InDelta(t, math.Pi/wd, si.PeakTime, 1e-3)
+	assert.InDelta(t, 1, si.SteadyState, 1e-9)
+	assert.InDelta(t, 0, si.SteadyStateError, 1e-9)
+
+	// first order system with dead time
+	si, err = (&Linear{Numerator: Polynomial{0.5}, Denominator: Polynomial{1, 1}, Delay: 1}).StepInfo(0.02)
+	assert.NoError(t, err)
+	assert.InDelta(t, math.Log(9), si.RiseTime, 1e-3)
+	assert.InDelta(t, 1-math.Log(0.9), si.RiseStart, 1e-3)
+	assert.InDelta(t, 1+math.Log(50), si.SettlingTime, 1e-3)
+	assert.InDelta(t, 0, si.Overshoot, 1e-9)
+	assert.InDelta(t, 0.5, si.SteadyState, 1e-9)
+	assert.InDelta(t, 0.5, si.SteadyStateError, 1e-9)
+	assert.True(t, math.IsNaN(si.PeakTime))
+
+	// first order system without overshoot has no peak
+	si, err = (&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}}).StepInfo(0.02)
+	assert.NoError(t, err)
+	assert.True(t, math.IsNaN(si.PeakTime))
+	assert.InDelta(t, 0, si.Overshoot, 1e-9)
+
+	_, err = (&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{0, 1}}).StepInfo(0.02)
+	assert.Error(t, err)
+}
+
+func TestCalcStepInfo(t *testing.T) {
+	tl := []float64{0, 1, 2, 3, 4, 5}
+	yl := []float64{0, 0.5, 1.2, 0.9, 1.01, 1}
+	si, err := CalcStepInfo(tl, yl, 1, 0.05)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1+0.4/0.7-0.2, si.RiseTime, 1e-9)
+	assert.InDelta(t, 2, si.PeakTime, 1e-9)
+	assert.InDelta(t, 20, si.Overshoot, 1e-9)
+	assert.InDelta(t, 3+0.05/0.11, si.SettlingTime, 1e-9)
+
+	si, err = CalcStepInfo(tl, []float64{0, 0.5, 0.9, 0.95, 0.99, 1}, 1, 0.05)
+	assert.NoError(t, err)
+	assert.True(t, math.IsNaN(si.PeakTime))
+
+	_, err = CalcStepInfo(tl, []float64{0, 0.5, 1.2, 0.9, 1.01, 1.5}, 1, 0.05)
+	assert.Error(t, err)
+}
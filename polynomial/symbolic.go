@@ -0,0 +1,907 @@
+package polynomial
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/hneemann/control/graph/grParser/mathml"
+	"github.com/hneemann/parser2/funcGen"
+	"github.com/hneemann/parser2/value"
+	"github.com/hneemann/parser2/value/export"
+	"github.com/hneemann/parser2/value/export/xmlWriter"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// symPower is a symbol raised to a positive integer power
+type symPower struct {
+	name string
+	exp  int
+}
+
+// monomial is a product of symbol powers sorted by the symbol names
+type monomial []symPower
+
+func (m monomial) degree() int {
+	d := 0
+	for _, p := range m {
+		d += p.exp
+	}
+	return d
+}
+
+func (m monomial) mul(b monomial) monomial {
+	r := make(monomial, 0, len(m)+len(b))
+	i, j := 0, 0
+	for i < len(m) || j < len(b) {
+		switch {
+		case j == len(b) || (i < len(m) && m[i].name < b[j].name):
+			r = append(r, m[i])
+			i++
+		case i == len(m) || b[j].name < m[i].name:
+			r = append(r, b[j])
+			j++
+		default:
+			r = append(r, symPower{name: m[i].name, exp: m[i].exp + b[j].exp})
+			i++
+			j++
+		}
+	}
+	return r
+}
+
+// div returns m/b if b divides m
+func (m monomial) div(b monomial) (monomial, bool) {
+	var r monomial
+	j := 0
+	for _, p := range m {
+		if j < len(b) && b[j].name < p.name {
+			return nil, false
+		}
+		if j < len(b) && b[j].name == p.name {
+			if b[j].exp > p.exp {
+				return nil, false
+			}
+			if b[j].exp < p.exp {
+				r = append(r, symPower{name: p.name, exp: p.exp - b[j].exp})
+			}
+			j++
+		} else {
+			r = append(r, p)
+		}
+	}
+	return r, j == len(b)
+}
+
+// compare implements the graded lexicographic order of monomials
+func (m monomial) compare(b monomial) int {
+	if d := m.degree() - b.degree(); d != 0 {
+		return d
+	}
+	for i := 0; i < len(m) && i < len(b); i++ {
+		if m[i].name != b[i].name {
+			if m[i].name < b[i].name {
+				return 1
+			}
+			return -1
+		}
+		if d := m[i].exp - b[i].exp; d != 0 {
+			return d
+		}
+	}
+	return len(m) - len(b)
+}
+
+type symTerm struct {
+	coef float64
+	mono monomial
+}
+
+// SymExpr is a polynomial in named symbols. It is used as the coefficient
+// of a SymPolynomial. The terms are sorted in descending graded lexicographic
+// order and contain no zero coefficients.
+type SymExpr []symTerm
+
+// NewSymConst creates a constant expression
+func NewSymConst(c float64) SymExpr {
+	if c == 0 {
+		return nil
+	}
+	return SymExpr{{coef: c}}
+}
+
+// NewSymbol creates an expression containing only the given symbol
+func NewSymbol(name string) SymExpr {
+	return SymExpr{{coef: 1, mono: monomial{{name: name, exp: 1}}}}
+}
+
+func (e SymExpr) IsZero() bool {
+	return len(e) == 0
+}
+
+// Const returns the value of the expression if it does not contain symbols
+func (e SymExpr) Const() (float64, bool) {
+	switch len(e) {
+	case 0:
+		return 0, true
+	case 1:
+		if len(e[0].mono) == 0 {
+			return e[0].coef, true
+		}
+	}
+	return 0, false
+}
+
+func (e SymExpr) Add(b SymExpr) SymExpr {
+	r := make(SymExpr, 0, len(e)+len(b))
+	appendTerm := func(t symTerm) {
+		if t.coef != 0 {
+			r = append(r, t)
+		}
+	}
+	i, j := 0, 0
+	for i < len(e) || j < len(b) {
+		var c int
+		switch {
+		case j == len(b):
+			c = 1
+		case i == len(e):
+			c = -1
+		default:
+			c = e[i].mono.compare(b[j].mono)
+		}
+		switch {
+		case c > 0:
+			appendTerm(e[i])
+			i++
+		case c < 0:
+			appendTerm(b[j])
+			j++
+		default:
+			sum := e[i].coef + b[j].coef
+			if math.Abs(sum) > eps*math.Max(math.Abs(e[i].coef), math.Abs(b[j].coef)) {
+				appendTerm(symTerm{coef: sum, mono: e[i].mono})
+			}
+			i++
+			j++
+		}
+	}
+	return r
+}
+
+func (e SymExpr) MulFloat(f float64) SymExpr {
+	if f == 0 {
+		return nil
+	}
+	r := make(SymExpr, len(e))
+	for i, t := range e {
+		r[i] = symTerm{coef: t.coef * f, mono: t.mono}
+	}
+	return r
+}
+
+func (e SymExpr) Mul(b SymExpr) SymExpr {
+	var r SymExpr
+	for _, ta := range e {
+		for _, tb := range b {
+			r = r.Add(SymExpr{{coef: ta.coef * tb.coef, mono: ta.mono.mul(tb.mono)}})
+		}
+	}
+	return r
+}
+
+// Div divides the expression by b. It returns false if the
+// division leaves a remainder.
+func (e SymExpr) Div(b SymExpr) (SymExpr, bool) {
+	if b.IsZero() {
+		return nil, false
+	}
+	scale := 0.0
+	for _, t := range e {
+		scale = math.Max(scale, math.Abs(t.coef))
+	}
+	var q SymExpr
+	r := e
+	for !r.IsZero() {
+		m, ok := r[0].mono.div(b[0].mono)
+		if !ok {
+			return nil, false
+		}
+		t := SymExpr{{coef: r[0].coef / b[0].coef, mono: m}}
+		q = q.Add(t)
+		// the leading terms cancel by construction
+		r = r[1:].Add(t.Mul(b[1:]).MulFloat(-1))
+		var cleaned SymExpr
+		for _, rt := range r {
+			if math.Abs(rt.coef) > eps*scale {
+				cleaned = append(cleaned, rt)
+			}
+		}
+		r = cleaned
+	}
+	return q, true
+}
+
+func (e SymExpr) Equals(b SymExpr) bool {
+	return e.Add(b.MulFloat(-1)).IsZero()
+}
+
+// Subst replaces the given symbols by their values
+func (e SymExpr) Subst(values map[string]float64) SymExpr {
+	var r SymExpr
+	for _, t := range e {
+		c := t.coef
+		var m monomial
+		for _, p := range t.mono {
+			if v, ok := values[p.name]; ok {
+				c *= math.Pow(v, float64(p.exp))
+			} else {
+				m = append(m, p)
+			}
+		}
+		r = r.Add(SymExpr{{coef: c, mono: m}})
+	}
+	return r
+}
+
+func (e SymExpr) addSymbols(names map[string]bool) {
+	for _, t := range e {
+		for _, p := range t.mono {
+			names[p.name] = true
+		}
+	}
+}
+
+// symFormat describes how an expression is written
+type symFormat struct {
+	float func(f float64) string
+	power func(name string, exp int) string
+	// coefSep separates the coefficient from the symbols
+	coefSep string
+	// mulSep separates the symbols
+	mulSep string
+}
+
+var (
+	symStringFormat = symFormat{
+		float: func(f float64) string {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		},
+		power: func(name string, exp int) string {
+			return fmt.Sprintf("%s^%d", name, exp)
+		},
+		coefSep: "*",
+		mulSep:  "*",
+	}
+	symLaTeXFormat = symFormat{
+		float: laTeXFloat,
+		power: func(name string, exp int) string {
+			return fmt.Sprintf("%s^{%d}", name, exp)
+		},
+		mulSep: " ",
+	}
+	symUnicodeFormat = symFormat{
+		float: func(f float64) string {
+			return export.NewFormattedFloat(f, 6).Unicode()
+		},
+		power: func(name string, exp int) string {
+			return name + export.ExpStr(exp)
+		},
+		mulSep: "·",
+	}
+)
+
+// writeTerm writes the absolute value of the term followed by the symbols in tail
+func (f symFormat) writeTerm(w *bytes.Buffer, coef float64, mono, tail monomial) {
+	c := f.float(math.Abs(coef))
+	hasSymbols := len(mono)+len(tail) > 0
+	if c != "1" || !hasSymbols {
+		w.WriteString(c)
+		if hasSymbols {
+			w.WriteString(f.coefSep)
+		}
+	}
+	first := true
+	for _, m := range []monomial{mono, tail} {
+		for _, p := range m {
+			if !first {
+				w.WriteString(f.mulSep)
+			}
+			first = false
+			if p.exp == 1 {
+				w.WriteString(p.name)
+			} else {
+				w.WriteString(f.power(p.name, p.exp))
+			}
+		}
+	}
+}
+
+func (f symFormat) writeExpr(w *bytes.Buffer, e SymExpr) {
+	if e.IsZero() {
+		w.WriteString("0")
+		return
+	}
+	for i, t := range e {
+		if t.coef < 0 {
+			w.WriteString("-")
+		} else if i > 0 {
+			w.WriteString("+")
+		}
+		f.writeTerm(w, t.coef, t.mono, nil)
+	}
+}
+
+func (f symFormat) writePolynomial(w *bytes.Buffer, p SymPolynomial) {
+	first := true
+	for n := len(p) - 1; n >= 0; n-- {
+		c := p[n]
+		if c.IsZero() {
+			continue
+		}
+		var tail monomial
+		if n > 0 {
+			tail = monomial{{name: "s", exp: n}}
+		}
+		if len(c) == 1 {
+			if c[0].coef < 0 {
+				w.WriteString("-")
+			} else if !first {
+				w.WriteString("+")
+			}
+			f.writeTerm(w, c[0].coef, c[0].mono, tail)
+		} else {
+			if !first {
+				w.WriteString("+")
+			}
+			if n == 0 {
+				f.writeExpr(w, c)
+			} else {
+				w.WriteString("(")
+				f.writeExpr(w, c)
+				w.WriteString(")")
+				w.WriteString(f.coefSep)
+				f.writeTerm(w, 1, nil, tail)
+			}
+		}
+		first = false
+	}
+	if first {
+		w.WriteString("0")
+	}
+}
+
+func (e SymExpr) String() string {
+	var b bytes.Buffer
+	symStringFormat.writeExpr(&b, e)
+	return b.String()
+}
+
+func (e SymExpr) ToLaTeX(w *bytes.Buffer) {
+	symLaTeXFormat.writeExpr(w, e)
+}
+
+// SymPolynomial is a polynomial in s whose coefficients are
+// polynomials in named symbols.
+type SymPolynomial []SymExpr
+
+var _ export.ToHtmlInterface = SymPolynomial{}
+
+// NewSymPolynomial converts a polynomial to a symbolic polynomial
+func NewSymPolynomial(p Polynomial) SymPolynomial {
+	r := make(SymPolynomial, len(p))
+	for i, c := range p {
+		r[i] = NewSymConst(c)
+	}
+	return r.Canonical()
+}
+
+// Canonical removes the vanishing leading coefficients
+func (p SymPolynomial) Canonical() SymPolynomial {
+	n := len(p)
+	for n > 1 && p[n-1].IsZero() {
+		n--
+	}
+	if n == 0 {
+		return SymPolynomial{nil}
+	}
+	return p[:n]
+}
+
+func (p SymPolynomial) Degree() int {
+	return len(p.Canonical()) - 1
+}
+
+func (p SymPolynomial) IsZero() bool {
+	for _, c := range p {
+		if !c.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+func (p SymPolynomial) Add(q SymPolynomial) SymPolynomial {
+	r := make(SymPolynomial, max(len(p), len(q)))
+	for i := range r {
+		if i < len(p) {
+			r[i] = p[i]
+		}
+		if i < len(q) {
+			r[i] = r[i].Add(q[i])
+		}
+	}
+	return r.Canonical()
+}
+
+func (p SymPolynomial) Mul(q SymPolynomial) SymPolynomial {
+	r := make(SymPolynomial, len(p)+len(q)-1)
+	for i, a := range p {
+		for j, b := range q {
+			r[i+j] = r[i+j].Add(a.Mul(b))
+		}
+	}
+	return r.Canonical()
+}
+
+func (p SymPolynomial) MulFloat(f float64) SymPolynomial {
+	r := make(SymPolynomial, len(p))
+	for i, c := range p {
+		r[i] = c.MulFloat(f)
+	}
+	return r.Canonical()
+}
+
+func (p SymPolynomial) Pow(n int) SymPolynomial {
+	r := SymPolynomial{NewSymConst(1)}
+	for i := 0; i < n; i++ {
+		r = r.Mul(p)
+	}
+	return r
+}
+
+func (p SymPolynomial) Equals(q SymPolynomial) bool {
+	return p.Add(q.MulFloat(-1)).IsZero()
+}
+
+// Const returns the value of the polynomial if it neither contains s nor any symbol
+func (p SymPolynomial) Const() (float64, bool) {
+	if p.Degree() > 0 {
+		return 0, false
+	}
+	return p[0].Const()
+}
+
+// Subst replaces the given symbols by their values
+func (p SymPolynomial) Subst(values map[string]float64) SymPolynomial {
+	r := make(SymPolynomial, len(p))
+	for i, c := range p {
+		r[i] = c.Subst(values)
+	}
+	return r.Canonical()
+}
+
+// Polynomial returns the polynomial if it does not contain any symbols
+func (p SymPolynomial) Polynomial() (Polynomial, bool) {
+	r := make(Polynomial, len(p))
+	for i, c := range p {
+		f, ok := c.Const()
+		if !ok {
+			return nil, false
+		}
+		r[i] = f
+	}
+	return r, true
+}
+
+// Symbols returns the sorted names of the symbols used
+func (p SymPolynomial) Symbols() []string {
+	names := map[string]bool{}
+	for _, c := range p {
+		c.addSymbols(names)
+	}
+	return sortedNames(names)
+}
+
+func sortedNames(names map[string]bool) []string {
+	r := make([]string, 0, len(names))
+	for n := range names {
+		r = append(r, n)
+	}
+	sort.Strings(r)
+	return r
+}
+
+// toExpr converts the polynomial to an expression treating s as a symbol
+func (p SymPolynomial) toExpr() SymExpr {
+	var r SymExpr
+	for n, c := range p {
+		if n == 0 {
+			r = r.Add(c)
+		} else {
+			r = r.Add(c.Mul(SymExpr{{coef: 1, mono: monomial{{name: "s", exp: n}}}}))
+		}
+	}
+	return r
+}
+
+func symPolynomialFromExpr(e SymExpr) SymPolynomial {
+	var r SymPolynomial
+	for _, t := range e {
+		n := 0
+		var m monomial
+		for _, p := range t.mono {
+			if p.name == "s" {
+				n = p.exp
+			} else {
+				m = append(m, p)
+			}
+		}
+		for len(r) <= n {
+			r = append(r, nil)
+		}
+		r[n] = r[n].Add(SymExpr{{coef: t.coef, mono: m}})
+	}
+	return r.Canonical()
+}
+
+// IsSum checks if the polynomial is a sum of at least two terms.
+func (p SymPolynomial) IsSum() bool {
+	n := 0
+	for _, c := range p {
+		n += len(c)
+	}
+	return n > 1
+}
+
+func (p SymPolynomial) String() string {
+	var b bytes.Buffer
+	symStringFormat.writePolynomial(&b, p)
+	return b.String()
+}
+
+func (p SymPolynomial) ToLaTeX(w *bytes.Buffer) {
+	symLaTeXFormat.writePolynomial(w, p)
+}
+
+func (p SymPolynomial) ToUnicode() string {
+	var b bytes.Buffer
+	symUnicodeFormat.writePolynomial(&b, p)
+	return b.String()
+}
+
+func (p SymPolynomial) ToHtml(_ funcGen.Stack[value.Value], w *xmlWriter.XMLWriter) error {
+	var b bytes.Buffer
+	p.ToLaTeX(&b)
+	return laTeXToHtml(b.String(), w)
+}
+
+func laTeXToHtml(latex string, w *xmlWriter.XMLWriter) error {
+	ast, err := mathml.ParseLaTeX(latex)
+	if err != nil {
+		return err
+	}
+	ast.ToMathMl(w, nil)
+	return nil
+}
+
+func (p SymPolynomial) ToList() (*value.List, bool) {
+	return nil, false
+}
+
+func (p SymPolynomial) ToMap() (value.Map, bool) {
+	return value.Map{}, false
+}
+
+func (p SymPolynomial) ToInt() (int, bool) {
+	return 0, false
+}
+
+func (p SymPolynomial) ToFloat() (float64, bool) {
+	return 0, false
+}
+
+func (p SymPolynomial) ToString(_ funcGen.Stack[value.Value]) (string, error) {
+	return p.String(), nil
+}
+
+func (p SymPolynomial) GetType() value.Type {
+	return SymbolicValueType
+}
+
+// SymLinear is a rational function in s whose coefficients are
+// polynomials in named symbols.
+type SymLinear struct {
+	Numerator   SymPolynomial
+	Denominator SymPolynomial
+}
+
+var _ export.ToHtmlInterface = &SymLinear{}
+
+// NewSymLinear converts a linear system to a symbolic one
+func NewSymLinear(l *Linear) (*SymLinear, error) {
+	if l.Delay != 0 {
+		return nil, errors.New("systems with dead time can not be combined with symbols, use pade to approximate the dead time")
+	}
+	return &SymLinear{Numerator: NewSymPolynomial(l.Numerator), Denominator: NewSymPolynomial(l.Denominator)}, nil
+}
+
+// reduce cancels the denominator if possible
+func (l *SymLinear) reduce() *SymLinear {
+	if c, ok := l.Denominator.Const(); ok {
+		if c == 1 {
+			return l
+		}
+		return &SymLinear{Numerator: l.Numerator.MulFloat(1 / c), Denominator: SymPolynomial{NewSymConst(1)}}
+	}
+	if q, ok := l.Numerator.toExpr().Div(l.Denominator.toExpr()); ok {
+		return &SymLinear{Numerator: symPolynomialFromExpr(q), Denominator: SymPolynomial{NewSymConst(1)}}
+	}
+	return l
+}
+
+// IsPolynomial returns true if the denominator is one
+func (l *SymLinear) IsPolynomial() bool {
+	c, ok := l.Denominator.Const()
+	return ok && c == 1
+}
+
+func (l *SymLinear) Add(b *SymLinear) *SymLinear {
+	if l.Denominator.Equals(b.Denominator) {
+		return (&SymLinear{Numerator: l.Numerator.Add(b.Numerator), Denominator: l.Denominator}).reduce()
+	}
+	return (&SymLinear{
+		Numerator:   l.Numerator.Mul(b.Denominator).Add(b.Numerator.Mul(l.Denominator)),
+		Denominator: l.Denominator.Mul(b.Denominator),
+	}).reduce()
+}
+
+func (l *SymLinear) Mul(b *SymLinear) *SymLinear {
+	return (&SymLinear{
+		Numerator:   l.Numerator.Mul(b.Numerator),
+		Denominator: l.Denominator.Mul(b.Denominator),
+	}).reduce()
+}
+
+func (l *SymLinear) MulFloat(f float64) *SymLinear {
+	return &SymLinear{Numerator: l.Numerator.MulFloat(f), Denominator: l.Denominator}
+}
+
+func (l *SymLinear) Inv() (*SymLinear, error) {
+	if l.Numerator.IsZero() {
+		return nil, errors.New("division by zero")
+	}
+	return (&SymLinear{Numerator: l.Denominator, Denominator: l.Numerator}).reduce(), nil
+}
+
+func (l *SymLinear) Div(b *SymLinear) (*SymLinear, error) {
+	inv, err := b.Inv()
+	if err != nil {
+		return nil, err
+	}
+	return l.Mul(inv), nil
+}
+
+func (l *SymLinear) Pow(n int) *SymLinear {
+	return &SymLinear{Numerator: l.Numerator.Pow(n), Denominator: l.Denominator.Pow(n)}
+}
+
+// Loop closes the loop with a negative unity feedback
+func (l *SymLinear) Loop() *SymLinear {
+	return (&SymLinear{Numerator: l.Numerator, Denominator: l.Numerator.Add(l.Denominator)}).reduce()
+}
+
+// Subst replaces the given symbols by their values. An error is returned
+// if the denominator becomes zero.
+func (l *SymLinear) Subst(values map[string]float64) (*SymLinear, error) {
+	d := l.Denominator.Subst(values)
+	if d.IsZero() {
+		return nil, errors.New("division by zero")
+	}
+	return (&SymLinear{Numerator: l.Numerator.Subst(values), Denominator: d}).reduce(), nil
+}
+
+// Linear returns the linear system if it does not contain any symbols
+func (l *SymLinear) Linear() (*Linear, bool) {
+	n, ok := l.Numerator.Polynomial()
+	if !ok {
+		return nil, false
+	}
+	d, ok := l.Denominator.Polynomial()
+	if !ok {
+		return nil, false
+	}
+	return &Linear{Numerator: n, Denominator: d}, true
+}
+
+// Symbols returns the sorted names of the symbols used
+func (l *SymLinear) Symbols() []string {
+	names := map[string]bool{}
+	for _, p := range []SymPolynomial{l.Numerator, l.Denominator} {
+		for _, c := range p {
+			c.addSymbols(names)
+		}
+	}
+	return sortedNames(names)
+}
+
+func (l *SymLinear) String() string {
+	if l.IsPolynomial() {
+		return l.Numerator.String()
+	}
+	n := l.Numerator.String()
+	if l.Numerator.IsSum() {
+		n = "(" + n + ")"
+	}
+	return n + "/(" + l.Denominator.String() + ")"
+}
+
+func (l *SymLinear) ToLaTeX(w *bytes.Buffer) {
+	if l.IsPolynomial() {
+		l.Numerator.ToLaTeX(w)
+		return
+	}
+	w.WriteString("\\frac{")
+	l.Numerator.ToLaTeX(w)
+	w.WriteString("}{")
+	l.Denominator.ToLaTeX(w)
+	w.WriteString("}")
+}
+
+func (l *SymLinear) ToUnicode() string {
+	if l.IsPolynomial() {
+		return l.Numerator.ToUnicode()
+	}
+	n := l.Numerator.ToUnicode()
+	if l.Numerator.IsSum() {
+		n = "(" + n + ")"
+	}
+	return n + "/(" + l.Denominator.ToUnicode() + ")"
+}
+
+func (l *SymLinear) ToHtml(_ funcGen.Stack[value.Value], w *xmlWriter.XMLWriter) error {
+	var b bytes.Buffer
+	l.ToLaTeX(&b)
+	return laTeXToHtml(b.String(), w)
+}
+
+func (l *SymLinear) ToList() (*value.List, bool) {
+	return nil, false
+}
+
+func (l *SymLinear) ToMap() (value.Map, bool) {
+	return value.Map{}, false
+}
+
+func (l *SymLinear) ToInt() (int, bool) {
+	return 0, false
+}
+
+func (l *SymLinear) ToFloat() (float64, bool) {
+	return 0, false
+}
+
+func (l *SymLinear) ToString(_ funcGen.Stack[value.Value]) (string, error) {
+	return l.String(), nil
+}
+
+func (l *SymLinear) GetType() value.Type {
+	return SymbolicValueType
+}
+
+// SymRouthTable is the Routh array of a symbolic polynomial. The elements
+// are rational functions of the symbols.
+type SymRouthTable struct {
+	Rows [][]*SymLinear
+}
+
+// Routh creates the Routh array of the symbolic polynomial. A row which
+// vanishes identically is replaced by the derivative of the auxiliary
+// polynomial. A zero in the first column can not be handled symbolically.
+// A zero in the last row is kept, since it means a root at the origin.
+func (p SymPolynomial) Routh() (*SymRouthTable, error) {
+	p = p.Canonical()
+	n := p.Degree()
+	if n < 1 {
+		return nil, errors.New("the polynomial needs to have at least degree one")
+	}
+	width := n/2 + 1
+	one := SymPolynomial{NewSymConst(1)}
+	rows := make([][]*SymLinear, n+1)
+	for k := range rows {
+		rows[k] = make([]*SymLinear, width)
+		for i := range rows[k] {
+			rows[k][i] = &SymLinear{Numerator: SymPolynomial{nil}, Denominator: one}
+		}
+	}
+	for i, c := range p {
+		rows[(n-i)%2][(n-i)/2] = &SymLinear{Numerator: SymPolynomial{c}, Denominator: one}
+	}
+
+	for k := 1; k <= n; k++ {
+		row := rows[k]
+		if k > 1 {
+			a := rows[k-2]
+			b := rows[k-1]
+			for i := 0; i < width-1; i++ {
+				e := b[0].Mul(a[i+1]).Add(a[0].Mul(b[i+1]).MulFloat(-1))
+				d, err := e.Div(b[0])
+				if err != nil {
+					return nil, err
+				}
+				row[i] = d.simplifyRouth()
+			}
+		}
+		if k == n {
+			// the last row contains the constant coefficient
+			break
+		}
+		isZero := true
+		for _, v := range row {
+			if !v.Numerator.IsZero() {
+				isZero = false
+			}
+		}
+		if isZero {
+			m := n - k + 1
+			for i := range row {
+				if m-2*i > 0 {
+					row[i] = rows[k-1][i].MulFloat(float64(m - 2*i))
+				}
+			}
+		}
+		if row[0].Numerator.IsZero() {
+			return nil, fmt.Errorf("the first element of the row s^%d vanishes, use numbers for the symbols to handle this case", n-k)
+		}
+	}
+	return &SymRouthTable{Rows: rows}, nil
+}
+
+// simplifyRouth scales the element so that the leading coefficient of the denominator is one
+func (l *SymLinear) simplifyRouth() *SymLinear {
+	d := l.Denominator.Canonical()
+	lead := d[len(d)-1]
+	if len(lead) == 0 || lead[0].coef == 1 {
+		return l
+	}
+	f := 1 / lead[0].coef
+	return &SymLinear{Numerator: l.Numerator.MulFloat(f), Denominator: l.Denominator.MulFloat(f)}
+}
+
+// FirstColumn returns the elements of the first column
+func (rt *SymRouthTable) FirstColumn() []*SymLinear {
+	r := make([]*SymLinear, len(rt.Rows))
+	for k, row := range rt.Rows {
+		r[k] = row[0]
+	}
+	return r
+}
+
+// ToLaTeX writes the Routh array as a table
+func (rt *SymRouthTable) ToLaTeX(w *bytes.Buffer) {
+	n := len(rt.Rows) - 1
+	w.WriteString("\\table[r|")
+	w.WriteString(strings.Repeat("r", len(rt.Rows[0])))
+	w.WriteString("]{")
+	for k, row := range rt.Rows {
+		if k > 0 {
+			w.WriteString("\\\\")
+		}
+		w.WriteString("s^{")
+		w.WriteString(strconv.Itoa(n - k))
+		w.WriteString("}")
+		for _, v := range row {
+			w.WriteString("&")
+			v.ToLaTeX(w)
+		}
+	}
+	w.WriteString("}")
+}
+
+func (rt *SymRouthTable) LaTeX() string {
+	var b bytes.Buffer
+	rt.ToLaTeX(&b)
+	return b.String()
+}
@@ -0,0 +1,126 @@
+package polynomial
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSymExpr(t *testing.T) {
+	k := NewSymbol("k")
+	T := NewSymbol("T")
+	one := NewSymConst(1)
+
+	p := k.Add(one).Mul(k.Add(one))
+	assert.Equal(t, "k^2+2*k+1", p.String())
+	assert.Equal(t, "T*k+2*T", T.Mul(k.Add(NewSymConst(2))).String())
+	assert.True(t, k.Add(k.MulFloat(-1)).IsZero())
+
+	q, ok := p.Div(k.Add(one))
+	assert.True(t, ok)
+	assert.True(t, q.Equals(k.Add(one)))
+	_, ok = p.Div(k.Add(NewSymConst(2)))
+	assert.False(t, ok)
+	_, ok = T.Div(k)
+	assert.False(t, ok)
+
+	c, ok := p.Subst(map[string]float64{"k": 2}).Const()
+	assert.True(t, ok)
+	assert.Equal(t, 9.0, c)
+	_, ok = T.Mul(k).Subst(map[string]float64{"k": 2}).Const()
+	assert.False(t, ok)
+}
+
+func TestSymPolynomial(t *testing.T) {
+	k := SymPolynomial{NewSymbol("k")}
+	s := NewSymPolynomial(Polynomial{0, 1})
+
+	p := s.Add(k).Mul(s.Add(k.MulFloat(-1)))
+	assert.Equal(t, "s^2-k^2", p.String())
+	assert.Equal(t, 2, p.Degree())
+	assert.Equal(t, []string{"k"}, p.Symbols())
+
+	p = s.Mul(s).Add(k.Add(SymPolynomial{NewSymConst(1)}).Mul(s)).Add(k)
+	assert.Equal(t, "s^2+(k+1)*s+k", p.String())
+	assert.Equal(t, "s²+(k+1)s+k", p.ToUnicode())
+	var b bytes.Buffer
+	p.ToLaTeX(&b)
+	assert.Equal(t, "s^{2}+(k+1)s+k", b.String())
+
+	poly, ok := p.Subst(map[string]float64{"k": 2}).Polynomial()
+	assert.True(t, ok)
+	assert.Equal(t, Polynomial{2, 3, 1}, poly)
+}
+
+func TestSymLinear(t *testing.T) {
+	k := &SymLinear{Numerator: SymPolynomial{NewSymbol("k")}, Denominator: SymPolynomial{NewSymConst(1)}}
+	s, err := NewSymLinear(&Linear{Numerator: Polynomial{0, 1}, Denominator: Polynomial{1}})
+	assert.NoError(t, err)
+
+	g, err := k.Div(s.Mul(s.Add(k)))
+	assert.NoError(t, err)
+	assert.Equal(t, "k/(s^2+k*s)", g.String())
+	assert.Equal(t, "k/(s^2+k*s+k)", g.Loop().String())
+
+	var b bytes.Buffer
+	g.Loop().ToLaTeX(&b)
+	assert.Equal(t, "\\frac{k}{s^{2}+k s+k}", b.String())
+
+	sl, err := g.Loop().Subst(map[string]float64{"k": 2})
+	assert.NoError(t, err)
+	l, ok := sl.Linear()
+	assert.True(t, ok)
+	assert.Equal(t, Polynomial{2}, l.Numerator)
+	assert.Equal(t, Polynomial{2, 2, 1}, l.Denominator)
+
+	// (s+k)(s-k)/(s+k) is reduced to s-k
+	r, err := s.Add(k).Mul(s.Add(k.MulFloat(-1))).Div(s.Add(k))
+	assert.NoError(t, err)
+	assert.True(t, r.IsPolynomial())
+	assert.Equal(t, "s-k", r.String())
+
+	_, err = NewSymLinear(&Linear{Numerator: Polynomial{1}, Denominator: Polynomial{1, 1}, Delay: 1})
+	assert.Error(t, err)
+}
+
+func TestSymPolynomial_Routh(t *testing.T) {
+	k := SymPolynomial{NewSymbol("k")}
+	// s^4+2s^3+3s^2+(k+1)s+k
+	p := NewSymPolynomial(Polynomial{0, 1, 3, 2, 1}).Add(k.Mul(NewSymPolynomial(Polynomial{1, 1})))
+	rt, err := p.Routh()
+	assert.NoError(t, err)
+	fc := rt.FirstColumn()
+	assert.Len(t, fc, 5)
+	assert.Equal(t, "-0.5*k+2.5", fc[2].String())
+	assert.Equal(t, "(k^2-5)/(k-5)", fc[3].String())
+	assert.Equal(t, "k", fc[4].String())
+
+	// the symbolic array coincides with the numeric one
+	for _, kv := range []float64{0.5, 2, 3} {
+		num, _ := p.Subst(map[string]float64{"k": kv}).Polynomial()
+		nrt, err := num.Routh()
+		assert.NoError(t, err)
+		for i, e := range fc {
+			sl, err := e.Subst(map[string]float64{"k": kv})
+			assert.NoError(t, err)
+			l, ok := sl.Linear()
+			assert.True(t, ok)
+			assert.InDelta(t, nrt.Rows[i][0], l.Eval(0), 1e-9)
+		}
+	}
+
+	// a row of zeros is replaced by the derivative of the auxiliary polynomial
+	rt, err = NewSymPolynomial(Polynomial{0, 0, 1, 1}).Add(k.Mul(NewSymPolynomial(Polynomial{1, 1}))).Routh()
+	assert.NoError(t, err)
+	assert.Equal(t, "2", rt.FirstColumn()[2].String())
+	assert.Equal(t, "k", rt.FirstColumn()[3].String())
+
+	// a zero constant coefficient is a root at the origin
+	rt, err = NewSymPolynomial(Polynomial{0, 0, 1}).Add(k.Mul(NewSymPolynomial(Polynomial{0, 1}))).Routh()
+	assert.NoError(t, err)
+	var col []string
+	for _, e := range rt.FirstColumn() {
+		col = append(col, e.String())
+	}
+	assert.Equal(t, []string{"1", "k", "0"}, col)
+}